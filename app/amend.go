@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (this *App) amendCampaign(params []string) (string, error) {
+	args, flags, err := parseFlags(params, "duration", "limit", "target")
+	if err != nil || len(args) != 1 || len(flags) == 0 {
+		return "", ErrInvalidParameters
+	}
+
+	var durationExtension, limit, target int
+	if value, ok := flags["duration"]; ok {
+		durationExtension, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrDurationMustBeInt
+		}
+	}
+
+	if value, ok := flags["limit"]; ok {
+		limit, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrLimitMustBeInt
+		}
+	}
+
+	if value, ok := flags["target"]; ok {
+		target, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrTargetSalesMustBeInt
+		}
+	}
+
+	this.timeMu.RLock()
+	defer this.timeMu.RUnlock()
+
+	result, err := this.campaignSerivce.Amend(args[0], durationExtension, limit, target)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Campaign %s amended; duration %d, limit %d, target sales count %d", result.Name.Value(), result.Duration.Value(), result.PriceManipulationLimit.Value(), result.TargetSalesCount.Value()), nil
+}
+
+func (this *App) getCampaignAmendments(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	result, err := this.campaignSerivce.Get(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	lines := []string{fmt.Sprintf("Campaign %s amendments; count %d", result.Name.Value(), len(result.Amendments))}
+	for _, amendment := range result.Amendments {
+		var changes []string
+		if amendment.DurationExtension != 0 {
+			changes = append(changes, fmt.Sprintf("duration +%d", amendment.DurationExtension))
+		}
+		if amendment.PriceManipulationLimit != 0 {
+			changes = append(changes, fmt.Sprintf("limit %d", amendment.PriceManipulationLimit))
+		}
+		if amendment.TargetSalesCount != 0 {
+			changes = append(changes, fmt.Sprintf("target sales count %d", amendment.TargetSalesCount))
+		}
+
+		lines = append(lines, fmt.Sprintf("Amended at %s; %s", amendment.Time.Format("15:00"), strings.Join(changes, ", ")))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
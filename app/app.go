@@ -4,50 +4,131 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
 	"github.com/aaydin-tr/e-commerce/service/campaign"
+	"github.com/aaydin-tr/e-commerce/service/category"
+	"github.com/aaydin-tr/e-commerce/service/coupon"
+	"github.com/aaydin-tr/e-commerce/service/ledger"
 	"github.com/aaydin-tr/e-commerce/service/order"
 	"github.com/aaydin-tr/e-commerce/service/product"
+	"github.com/aaydin-tr/e-commerce/service/state"
+	"github.com/aaydin-tr/e-commerce/service/tax"
+	"github.com/aaydin-tr/e-commerce/service/warehouse"
+	"github.com/aaydin-tr/e-commerce/valueobject"
 )
 
 var (
-	ErrCommandNotFound            = errors.New("Command not found")
-	ErrInvalidParameters          = errors.New("Invalid parameters")
-	ErrPriceMustBeFloat           = errors.New("Price must be float")
-	ErrStockMustBeInt             = errors.New("Stock must be integer")
-	ErrQuantityMustBeInt          = errors.New("Quantity must be integer")
-	ErrDurationMustBeInt          = errors.New("Duration must be integer")
-	ErrLimitMustBeInt             = errors.New("Limit must be integer")
-	ErrTargetSalesMustBeInt       = errors.New("Target sales must be integer")
-	ErrHourMustBeInt              = errors.New("Hour must be integer")
-	ErrCampaignDoesNotHaveProduct = errors.New("Campaign does not have product")
+	ErrCommandNotFound         = errors.New("Command not found")
+	ErrInvalidParameters       = errors.New("Invalid parameters")
+	ErrPriceMustBeFloat        = errors.New("Price must be float")
+	ErrStockMustBeInt          = errors.New("Stock must be integer")
+	ErrQuantityMustBeInt       = errors.New("Quantity must be integer")
+	ErrDurationMustBeInt       = errors.New("Duration must be integer")
+	ErrLimitMustBeInt          = errors.New("Limit must be integer")
+	ErrTargetSalesMustBeInt    = errors.New("Target sales must be integer")
+	ErrHourMustBeInt           = errors.New("Hour must be integer")
+	ErrHourCannotBeNegative    = errors.New("Hour cannot be negative")
+	ErrSeedMustBeInt           = errors.New("Seed must be integer")
+	ErrViewsMustBeFloat        = errors.New("Views must be float")
+	ErrConversionMustBeFloat   = errors.New("Conversion must be float")
+	ErrElasticityMustBeFloat   = errors.New("Elasticity must be float")
+	ErrMaxQuantityMustBeInt    = errors.New("Max quantity must be integer")
+	ErrRunsMustBeInt           = errors.New("Runs must be integer")
+	ErrMaxDurationMustBeInt    = errors.New("Max duration must be integer")
+	ErrBackorderLimitMustBeInt = errors.New("Backorder limit must be integer")
+	ErrAmountMustBeInt         = errors.New("Amount must be integer")
+	ErrCoordinateMustBeFloat   = errors.New("Coordinate must be float")
+	ErrInvalidLocation         = errors.New("Location must be two floats as x,y")
+	ErrInvalidComponent        = errors.New("Component must be code:quantity")
+	ErrInvalidAttribute        = errors.New("Attribute must be name=value with a unique name")
+	ErrDiscountMustBeFloat     = errors.New("Discount must be float")
+	ErrUsageLimitMustBeInt     = errors.New("Usage limit must be integer")
+	ErrInvalidTier             = errors.New("Tier must be quantity:percent with a unique quantity")
+	ErrTaxRateMustBeFloat      = errors.New("Tax rate must be float")
 )
 
+// startTime is the clock value every simulation starts from.
+var startTime = clock.Start
+
 type App struct {
-	systemTime      time.Time
-	commands        map[string]func(params []string) (string, error)
-	productService  product.ProductServiceInterface
-	orderSerivce    order.OrderServiceInterface
-	campaignSerivce campaign.CampaignServiceInterface
+	timeMu           sync.RWMutex
+	clock            *clock.Clock
+	commands         map[string]func(params []string) (string, error)
+	productService   product.ProductServiceInterface
+	orderSerivce     order.OrderServiceInterface
+	campaignSerivce  campaign.CampaignServiceInterface
+	warehouseService warehouse.WarehouseServiceInterface
+	ledgerService    ledger.LedgerServiceInterface
+	categoryService  category.CategoryServiceInterface
+	couponService    coupon.CouponServiceInterface
+	taxService       tax.TaxServiceInterface
+	scheduler        campaign.SchedulerInterface
+	stateService     state.StateServiceInterface
+	branchService    state.BranchServiceInterface
 }
 
-func NewApp(productService product.ProductServiceInterface, orderService order.OrderServiceInterface, campaignService campaign.CampaignServiceInterface) *App {
+func NewApp(productService product.ProductServiceInterface, orderService order.OrderServiceInterface, campaignService campaign.CampaignServiceInterface, warehouseService warehouse.WarehouseServiceInterface, ledgerService ledger.LedgerServiceInterface, categoryService category.CategoryServiceInterface, couponService coupon.CouponServiceInterface, taxService tax.TaxServiceInterface, scheduler campaign.SchedulerInterface, stateService state.StateServiceInterface, clock *clock.Clock) *App {
 
 	app := &App{
-		systemTime:      time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC),
-		productService:  productService,
-		orderSerivce:    orderService,
-		campaignSerivce: campaignService,
+		clock:            clock,
+		productService:   productService,
+		orderSerivce:     orderService,
+		campaignSerivce:  campaignService,
+		warehouseService: warehouseService,
+		ledgerService:    ledgerService,
+		categoryService:  categoryService,
+		couponService:    couponService,
+		taxService:       taxService,
+		scheduler:        scheduler,
+		stateService:     stateService,
+		branchService:    state.NewBranchService(stateService),
 	}
 
 	commands := make(map[string]func(params []string) (string, error))
 	commands["create_product"] = app.createProduct
 	commands["get_product_info"] = app.getProductInfo
+	commands["create_bundle"] = app.createBundle
+	commands["create_variant"] = app.createVariant
+	commands["set_price_tiers"] = app.setPriceTiers
 	commands["create_order"] = app.createOrder
+	commands["restock"] = app.restock
+	commands["get_order"] = app.getOrder
+	commands["pay_order"] = app.changeOrderStatus(valueobject.Paid)
+	commands["ship_order"] = app.changeOrderStatus(valueobject.Shipped)
+	commands["deliver_order"] = app.changeOrderStatus(valueobject.Delivered)
+	commands["cancel_order"] = app.changeOrderStatus(valueobject.Cancelled)
 	commands["create_campaign"] = app.createCampaign
 	commands["get_campaign_info"] = app.getCampaignInfo
+	commands["amend_campaign"] = app.amendCampaign
+	commands["cancel_campaign"] = app.cancelCampaign
+	commands["get_campaign_amendments"] = app.getCampaignAmendments
+	commands["create_category"] = app.createCategory
+	commands["assign_category"] = app.assignCategory
+	commands["get_category_info"] = app.getCategoryInfo
+	commands["create_category_campaign"] = app.createCategoryCampaign
+	commands["create_coupon"] = app.createCoupon
+	commands["get_coupon_info"] = app.getCouponInfo
+	commands["set_tax_rate"] = app.setTaxRate
+	commands["get_region_info"] = app.getRegionInfo
+	commands["set_tax_class"] = app.setTaxClass
+	commands["create_warehouse"] = app.createWarehouse
+	commands["stock_warehouse"] = app.stockWarehouse
+	commands["adjust_stock"] = app.adjustStock
+	commands["stock_ledger"] = app.stockLedger
+	commands["reconcile_stock"] = app.reconcileStock
 	commands["increase_time"] = app.increaseTime
+	commands["simulate"] = app.simulate
+	commands["optimize_campaign"] = app.optimizeCampaign
+	commands["save_state"] = app.saveState
+	commands["load_state"] = app.loadState
+	commands["fork"] = app.fork
+	commands["switch"] = app.switchBranch
+	commands["discard"] = app.discard
+	commands["compare_campaign"] = app.compareCampaign
 
 	app.commands = commands
 	return app
@@ -62,26 +143,60 @@ func (this *App) Run(args []string) (string, error) {
 }
 
 func (this *App) createProduct(params []string) (string, error) {
-	if len(params) != 3 {
+	args, flags, err := parseFlags(params, "backorder-limit", "launch")
+	if err != nil || len(args) != 3 {
 		return "", ErrInvalidParameters
 	}
 
-	code := params[0]
-	price, err := strconv.ParseFloat(params[1], 64)
+	code := args[0]
+	price, err := strconv.ParseFloat(args[1], 64)
 	if err != nil {
 		return "", ErrPriceMustBeFloat
 	}
-	stock, err := strconv.Atoi(params[2])
+	stock, err := strconv.Atoi(args[2])
 	if err != nil {
 		return "", ErrStockMustBeInt
 	}
 
-	err = this.productService.Create(code, price, stock)
+	var backorderLimit, launch int
+	if value, ok := flags["backorder-limit"]; ok {
+		backorderLimit, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrBackorderLimitMustBeInt
+		}
+
+		if backorderLimit < 0 {
+			return "", product.ErrBackorderLimitCannotBeNegative
+		}
+	}
+
+	if value, ok := flags["launch"]; ok {
+		launch, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrHourMustBeInt
+		}
+
+		if launch < 0 {
+			return "", ErrHourCannotBeNegative
+		}
+	}
+
+	var launchAt time.Time
+	if launch > 0 {
+		launchAt = this.clock.Now().Add(time.Duration(launch) * time.Hour)
+	}
+
+	result, err := this.productService.CreateWithBackorders(code, price, stock, backorderLimit, launchAt)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("Product created; code %s, price %.1f, stock %d", code, price, stock), nil
+	msg := fmt.Sprintf("Product created; code %s, price %.1f, stock %d", code, price, stock)
+	if len(flags) == 0 {
+		return msg, nil
+	}
+
+	return msg + backorderInfo(result, this.clock.Now()), nil
 }
 
 func (this *App) getProductInfo(params []string) (string, error) {
@@ -94,61 +209,166 @@ func (this *App) getProductInfo(params []string) (string, error) {
 		return "", err
 	}
 
+	result.Acquire()
+	defer result.Release()
+
 	result.IncreaseDemand(1)
 
-	return fmt.Sprintf("Product %s info; price %.1f, stock %d", result.Code.Value(), result.Price.Value(), result.Stock.Value()), nil
+	info := fmt.Sprintf("Product %s info; price %.1f, stock %d", result.Code.Value(), result.Price.Value(), result.Stock.Value())
+	return info + componentInfo(result) + variantInfo(result) + categoryInfo(result) + tierInfo(result) + taxClassInfo(result) + inventoryInfo(result) + backorderInfo(result, this.clock.Now()), nil
 }
 
 func (this *App) createOrder(params []string) (string, error) {
-	if len(params) != 2 {
+	args, flags, err := parseFlags(params, "strategy", "location", "coupon", "region")
+	if err != nil || len(args) != 2 {
 		return "", ErrInvalidParameters
 	}
 
-	code := params[0]
-	quantity, err := strconv.Atoi(params[1])
+	code := args[0]
+	quantity, err := strconv.Atoi(args[1])
 	if err != nil {
 		return "", ErrQuantityMustBeInt
 	}
 
+	strategy := valueobject.SplitAcrossWarehouses
+	if value, ok := flags["strategy"]; ok {
+		strategy = value
+	}
+
+	var x, y float64
+	if value, ok := flags["location"]; ok {
+		x, y, err = parseLocation(value)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	product, err := this.productService.Get(code)
 	if err != nil {
 		return "", err
 	}
 
-	err = this.orderSerivce.Create(product, quantity)
+	var orderCoupon *entity.Coupon
+	if value, ok := flags["coupon"]; ok {
+		orderCoupon, err = this.couponService.Get(value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var orderRegion *entity.Region
+	if value, ok := flags["region"]; ok {
+		orderRegion, err = this.taxService.Get(value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	newOrder, err := this.orderSerivce.CreateWithStrategy(product, quantity, strategy, x, y, orderCoupon, orderRegion)
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("Order created; product %s, quantity %d", code, quantity), nil
+	msg := fmt.Sprintf("Order created; id %s, product %s, quantity %d", newOrder.ID, code, quantity)
+	if newOrder.Status.Value() != valueobject.Placed {
+		msg += fmt.Sprintf(", status %s, backordered %d", newOrder.Status.Value(), newOrder.Backordered.Value())
+	}
+	if newOrder.TierPercent != 0 {
+		msg += fmt.Sprintf(", price %.1f", newOrder.Price.Value()) + orderTierInfo(newOrder)
+	}
+	if orderCoupon != nil {
+		msg += discountInfo(newOrder)
+	}
+	if orderRegion != nil {
+		msg += taxInfo(newOrder)
+	} else if newOrder.TierPercent != 0 || orderCoupon != nil {
+		msg += fmt.Sprintf(", total %.1f", newOrder.Total())
+	}
+
+	return msg + allocationInfo(newOrder), nil
+}
+
+func (this *App) getOrder(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	result, err := this.orderSerivce.Get(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	product, err := this.productService.GetByID(result.ProductID)
+	if err != nil {
+		return "", err
+	}
+
+	info := fmt.Sprintf("Order %s info; product %s, quantity %d, price %.1f, total %.1f, status %s", result.ID, product.Code.Value(), result.Quantity.Value(), result.Price.Value(), result.Total(), result.Status.Value())
+	if result.Backordered.Value() > 0 {
+		info += fmt.Sprintf(", backordered %d", result.Backordered.Value())
+	}
+	info += allocationInfo(result)
+	info += fmt.Sprintf(", time %s", result.CreatedAt.Format("15:00"))
+	if result.Campaign.Value() != "" {
+		info += fmt.Sprintf(", campaign %s", result.Campaign.Value())
+	}
+
+	return info + orderTierInfo(result) + discountInfo(result) + taxInfo(result), nil
+}
+
+// changeOrderStatus returns the command that moves an order to status.
+func (this *App) changeOrderStatus(status string) func(params []string) (string, error) {
+	return func(params []string) (string, error) {
+		if len(params) != 1 {
+			return "", ErrInvalidParameters
+		}
+
+		result, err := this.orderSerivce.ChangeStatus(params[0], status)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Order %s status; %s", result.ID, result.Status.Value()), nil
+	}
 }
 
 func (this *App) createCampaign(params []string) (string, error) {
-	if len(params) != 5 {
+	args, flags, err := parseFlags(params, "stock-policy", "backorders")
+	if err != nil || len(args) != 5 {
 		return "", ErrInvalidParameters
 	}
 
-	name := params[0]
-	code := params[1]
-	duration, err := strconv.Atoi(params[2])
+	name := args[0]
+	code := args[1]
+	duration, err := strconv.Atoi(args[2])
 	if err != nil {
 		return "", ErrDurationMustBeInt
 	}
-	limit, err := strconv.Atoi(params[3])
+	limit, err := strconv.Atoi(args[3])
 	if err != nil {
 		return "", ErrLimitMustBeInt
 	}
-	targetSalesCount, err := strconv.Atoi(params[4])
+	targetSalesCount, err := strconv.Atoi(args[4])
 	if err != nil {
 		return "", ErrTargetSalesMustBeInt
 	}
 
+	stockPolicy := valueobject.EndOnStockOut
+	if value, ok := flags["stock-policy"]; ok {
+		stockPolicy = value
+	}
+
+	backorderPolicy := valueobject.CountOnOrder
+	if value, ok := flags["backorders"]; ok {
+		backorderPolicy = value
+	}
+
 	product, err := this.productService.Get(code)
 	if err != nil {
 		return "", err
 	}
 
-	err = this.campaignSerivce.Create(name, product, duration, limit, targetSalesCount)
+	err = this.campaignSerivce.Create(name, product, duration, limit, targetSalesCount, stockPolicy, backorderPolicy)
 	if err != nil {
 		return "", err
 	}
@@ -166,7 +386,66 @@ func (this *App) getCampaignInfo(params []string) (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("Campaign %s info; Status %s, Target Sales %d, Total Sales %d, Turnover %.1f, Average Item Price %.1f", result.Name.Value(), result.Status.Value(), result.TargetSalesCount.Value(), result.TotalSales.Value(), (float64(result.TotalSales.Value()) * result.AverageItemPrice.Value()), result.AverageItemPrice.Value()), nil
+	return campaignInfo(result), nil
+}
+
+func campaignInfo(result *entity.Campaign) string {
+	result.Acquire()
+	defer result.Release()
+
+	info := formatCampaignInfo(result.Name.Value(), result.Status.Value(), result.TargetSalesCount.Value(), result.TotalSales.Value(), result.AverageItemPrice.Value(), result.Tax)
+	if result.IsCategory() {
+		info += fmt.Sprintf(", Category %s", result.Category.Value())
+	}
+	if result.EndReason.Value() != "" {
+		info += formatCampaignEnd(result.EndReason.Value(), result.EndedAt, result.FinalPrice.Value())
+	}
+
+	return info
+}
+
+// formatCampaignInfo describes a campaign. The turnover is net of tax, and the
+// tax and the gross turnover are added once the campaign has counted taxed
+// units.
+func formatCampaignInfo(name string, status string, targetSalesCount int, totalSales int, averageItemPrice float64, tax float64) string {
+	turnover := float64(totalSales) * averageItemPrice
+	info := fmt.Sprintf("Campaign %s info; Status %s, Target Sales %d, Total Sales %d, Turnover %.1f, Average Item Price %.1f", name, status, targetSalesCount, totalSales, turnover, averageItemPrice)
+	if tax > 0 {
+		info += fmt.Sprintf(", Tax %.1f, Gross Turnover %.1f", tax, turnover+tax)
+	}
+
+	return info
+}
+
+// formatCampaignEnd describes how an ended campaign ended, to be appended to
+// its info. A category campaign has no single final price, which is left out.
+func formatCampaignEnd(reason string, endedAt time.Time, finalPrice float64) string {
+	info := fmt.Sprintf(", End Reason %s, Ended At %s", reason, endedAt.Format("15:00"))
+	if finalPrice == 0 {
+		return info
+	}
+
+	return info + fmt.Sprintf(", Final Price %.1f", finalPrice)
+}
+
+func (this *App) cancelCampaign(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.RLock()
+	defer this.timeMu.RUnlock()
+
+	result, err := this.campaignSerivce.Cancel(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	if result.IsCategory() {
+		return fmt.Sprintf("Campaign %s cancelled; category %s", result.Name.Value(), result.Category.Value()), nil
+	}
+
+	return fmt.Sprintf("Campaign %s cancelled; final price %.1f", result.Name.Value(), result.FinalPrice.Value()), nil
 }
 
 func (this *App) increaseTime(params []string) (string, error) {
@@ -179,27 +458,27 @@ func (this *App) increaseTime(params []string) (string, error) {
 		return "", ErrHourMustBeInt
 	}
 
-	this.systemTime = this.systemTime.Add(time.Duration(hour) * time.Hour)
-	campaigns, err := this.campaignSerivce.GetAll()
+	if hour < 0 {
+		return "", ErrHourCannotBeNegative
+	}
+
+	systemTime, err := this.advanceTime(hour)
 	if err != nil {
 		return "", err
 	}
 
-	for _, campaign := range campaigns {
+	return fmt.Sprintf("Time is %s", systemTime.Format("15:00")), nil
+}
 
-		if campaign.Product == nil {
-			return "", ErrCampaignDoesNotHaveProduct
-		}
+func (this *App) advanceTime(hour int) (time.Time, error) {
+	this.timeMu.Lock()
+	defer this.timeMu.Unlock()
 
-		campaign.DecreaseDuration(hour)
-		if campaign.Duration.Value() == 0 || campaign.TotalSales.Value() == campaign.TargetSalesCount.Value() {
-			campaign.Close()
-			campaign.Product.RemoveCampaign()
-			continue
-		}
-
-		campaign.Product.Discount(campaign.PriceManipulationLimit)
+	systemTime := this.clock.Advance(time.Duration(hour) * time.Hour)
+	err := this.scheduler.Tick(systemTime)
+	if err != nil {
+		return systemTime, err
 	}
 
-	return fmt.Sprintf("Time is %s", this.systemTime.Format("15:00")), nil
+	return systemTime, this.orderSerivce.FulfillBackorders()
 }
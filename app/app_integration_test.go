@@ -4,6 +4,7 @@
 package app
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 
@@ -12,6 +13,9 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// orderIDPattern matches the generated order IDs, which differ on every run.
+var orderIDPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
 type integationTestCase struct {
 	name                     string
 	productCode              string
@@ -42,7 +46,7 @@ func TestAppIntegration(t *testing.T) {
 			productCode: "P1",
 			commands: []commandTestCase{
 				{args: "create_product P1 100 100", msg: "Product created; code P1, price 100.0, stock 100"},
-				{args: "create_order P1 50", msg: "Order created; product P1, quantity 50"},
+				{args: "create_order P1 50", msg: "Order created; id <id>, product P1, quantity 50"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 100.0, stock 50"},
 			},
 			expectedLastPrice: 100,
@@ -54,7 +58,7 @@ func TestAppIntegration(t *testing.T) {
 			productCode: "P1",
 			commands: []commandTestCase{
 				{args: "create_product P1 100 100", msg: "Product created; code P1, price 100.0, stock 100"},
-				{args: "create_order P1 100", msg: "Order created; product P1, quantity 100"},
+				{args: "create_order P1 100", msg: "Order created; id <id>, product P1, quantity 100"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 100.0, stock 0"},
 			},
 			expectedLastPrice: 100,
@@ -80,7 +84,7 @@ func TestAppIntegration(t *testing.T) {
 			commands: []commandTestCase{
 				{args: "create_product P1 100 100", msg: "Product created; code P1, price 100.0, stock 100"},
 				{args: "create_campaign C1 P1 10 20 100", msg: "Campaign created; name C1, product P1, duration 10, limit 20, target sales count 100"},
-				{args: "create_order P1 50", msg: "Order created; product P1, quantity 50"},
+				{args: "create_order P1 50", msg: "Order created; id <id>, product P1, quantity 50"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 100.0, stock 50"},
 				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Active, Target Sales 100, Total Sales 50, Turnover 5000.0, Average Item Price 100.0"},
 			},
@@ -99,9 +103,9 @@ func TestAppIntegration(t *testing.T) {
 			commands: []commandTestCase{
 				{args: "create_product P1 100 100", msg: "Product created; code P1, price 100.0, stock 100"},
 				{args: "create_campaign C1 P1 10 20 100", msg: "Campaign created; name C1, product P1, duration 10, limit 20, target sales count 100"},
-				{args: "create_order P1 100", msg: "Order created; product P1, quantity 100"},
+				{args: "create_order P1 100", msg: "Order created; id <id>, product P1, quantity 100"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 100.0, stock 0"},
-				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 100, Turnover 10000.0, Average Item Price 100.0"},
+				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 100, Turnover 10000.0, Average Item Price 100.0, End Reason TargetReached, Ended At 00:00, Final Price 100.0"},
 			},
 			expectedLastPrice:        100,
 			expectedLastStock:        0,
@@ -118,9 +122,9 @@ func TestAppIntegration(t *testing.T) {
 			commands: []commandTestCase{
 				{args: "create_product P1 100 200", msg: "Product created; code P1, price 100.0, stock 200"},
 				{args: "create_campaign C1 P1 10 20 100", msg: "Campaign created; name C1, product P1, duration 10, limit 20, target sales count 100"},
-				{args: "create_order P1 150", msg: "Order created; product P1, quantity 150"},
+				{args: "create_order P1 150", msg: "Order created; id <id>, product P1, quantity 150"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 100.0, stock 50"},
-				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 100, Turnover 10000.0, Average Item Price 100.0"},
+				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 100, Turnover 10000.0, Average Item Price 100.0, End Reason TargetReached, Ended At 00:00, Final Price 100.0"},
 			},
 			expectedLastPrice:        100,
 			expectedLastStock:        50,
@@ -137,7 +141,7 @@ func TestAppIntegration(t *testing.T) {
 			commands: []commandTestCase{
 				{args: "create_product P1 100 100", msg: "Product created; code P1, price 100.0, stock 100"},
 				{args: "create_campaign C1 P1 10 20 100", msg: "Campaign created; name C1, product P1, duration 10, limit 20, target sales count 100"},
-				{args: "create_order P1 50", msg: "Order created; product P1, quantity 50"},
+				{args: "create_order P1 50", msg: "Order created; id <id>, product P1, quantity 50"},
 				{args: "increase_time 1", msg: "Time is 01:00"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 120.0, stock 50"},
 				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Active, Target Sales 100, Total Sales 50, Turnover 5000.0, Average Item Price 100.0"},
@@ -179,7 +183,7 @@ func TestAppIntegration(t *testing.T) {
 				{args: "create_product P1 100 100", msg: "Product created; code P1, price 100.0, stock 100"},
 				{args: "create_campaign C1 P1 1 20 100", msg: "Campaign created; name C1, product P1, duration 1, limit 20, target sales count 100"},
 				{args: "increase_time 1", msg: "Time is 01:00"},
-				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0"},
+				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0, End Reason Expired, Ended At 01:00, Final Price 100.0"},
 			},
 			expectedLastPrice:        100,
 			expectedLastStock:        100,
@@ -196,9 +200,9 @@ func TestAppIntegration(t *testing.T) {
 			commands: []commandTestCase{
 				{args: "create_product P1 100 200", msg: "Product created; code P1, price 100.0, stock 200"},
 				{args: "create_campaign C1 P1 10 20 100", msg: "Campaign created; name C1, product P1, duration 10, limit 20, target sales count 100"},
-				{args: "create_order P1 100", msg: "Order created; product P1, quantity 100"},
+				{args: "create_order P1 100", msg: "Order created; id <id>, product P1, quantity 100"},
 				{args: "increase_time 1", msg: "Time is 01:00"},
-				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 100, Turnover 10000.0, Average Item Price 100.0"},
+				{args: "get_campaign_info C1", msg: "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 100, Turnover 10000.0, Average Item Price 100.0, End Reason TargetReached, Ended At 00:00, Final Price 100.0"},
 			},
 			expectedLastPrice:        100,
 			expectedLastStock:        100,
@@ -215,7 +219,7 @@ func TestAppIntegration(t *testing.T) {
 			commands: []commandTestCase{
 				{args: "create_product P1 100 200", msg: "Product created; code P1, price 100.0, stock 200"},
 				{args: "create_campaign C1 P1 10 20 100", msg: "Campaign created; name C1, product P1, duration 10, limit 20, target sales count 100"},
-				{args: "create_order P1 100", msg: "Order created; product P1, quantity 100"},
+				{args: "create_order P1 100", msg: "Order created; id <id>, product P1, quantity 100"},
 				{args: "increase_time 1", msg: "Time is 01:00"},
 				{args: "get_product_info P1", msg: "Product P1 info; price 100.0, stock 100"},
 			},
@@ -235,7 +239,7 @@ func TestAppIntegration(t *testing.T) {
 			for _, command := range testCase.commands {
 				msg, err := app.Run(strings.Split(command.args, " "))
 				assert.Equal(t, command.err, err)
-				assert.Equal(t, command.msg, msg)
+				assert.Equal(t, command.msg, orderIDPattern.ReplaceAllString(msg, "<id>"))
 			}
 
 			product, err := app.productService.Get(testCase.productCode)
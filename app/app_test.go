@@ -1,30 +1,69 @@
 package app
 
 import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	categoryRepo "github.com/aaydin-tr/e-commerce/domain/category/memory"
+	couponRepo "github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	ledgerRepo "github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	orderDomain "github.com/aaydin-tr/e-commerce/domain/order"
 	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
 	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	regionRepo "github.com/aaydin-tr/e-commerce/domain/region/memory"
+	warehouseDomain "github.com/aaydin-tr/e-commerce/domain/warehouse"
+	warehouseRepo "github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
 	"github.com/aaydin-tr/e-commerce/service/campaign"
+	"github.com/aaydin-tr/e-commerce/service/category"
+	"github.com/aaydin-tr/e-commerce/service/coupon"
+	"github.com/aaydin-tr/e-commerce/service/ledger"
 	"github.com/aaydin-tr/e-commerce/service/order"
 	"github.com/aaydin-tr/e-commerce/service/product"
+	"github.com/aaydin-tr/e-commerce/service/simulation"
+	"github.com/aaydin-tr/e-commerce/service/state"
+	"github.com/aaydin-tr/e-commerce/service/tax"
+	"github.com/aaydin-tr/e-commerce/service/warehouse"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
 	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
 )
 
 func setup(t *testing.T) *App {
-	mockProductRepository := productRepo.NewProductRepository(storage.New[*entity.Product]())
-	mockOrderRepository := orderRepo.NewOrderRepository(storage.New[*entity.Order]())
-	mockCampaignRepository := campaignRepo.NewCampaignRepository(storage.New[*entity.Campaign]())
+	unitOfWork := uow.New()
+	mockProductRepository := productRepo.NewProductRepository(uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]()))
+	mockOrderRepository := orderRepo.NewOrderRepository(uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]()))
+	mockCampaignRepository := campaignRepo.NewCampaignRepository(uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]()))
+	mockWarehouseRepository := warehouseRepo.NewWarehouseRepository(uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]()))
+	mockLedgerRepository := ledgerRepo.NewLedgerRepository(uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]()))
+	mockCategoryRepository := categoryRepo.NewCategoryRepository(uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]()))
+	mockCouponRepository := couponRepo.NewCouponRepository(uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]()))
+	mockRegionRepository := regionRepo.NewRegionRepository(uow.NewStorage[*entity.Region](unitOfWork, storage.New[*entity.Region]()))
 
-	mockProductService := product.NewProductService(mockProductRepository)
-	mockOrderService := order.NewOrderService(mockOrderRepository)
-	mockCampaignService := campaign.NewCampaignService(mockCampaignRepository)
+	systemClock := clock.New(startTime)
+	mockProductService := product.NewProductService(mockProductRepository, mockLedgerRepository, unitOfWork, systemClock)
+	mockOrderService := order.NewOrderService(mockOrderRepository, mockProductRepository, mockCampaignRepository, mockWarehouseRepository, mockLedgerRepository, mockCouponRepository, mockCategoryRepository, unitOfWork, systemClock)
+	mockCampaignService := campaign.NewCampaignService(mockCampaignRepository, mockProductRepository, unitOfWork, systemClock)
+	mockWarehouseService := warehouse.NewWarehouseService(mockWarehouseRepository, mockProductRepository, unitOfWork)
+	mockLedgerService := ledger.NewLedgerService(mockLedgerRepository, mockProductRepository)
+	mockCategoryService := category.NewCategoryService(mockCategoryRepository, mockProductRepository, unitOfWork)
+	mockCouponService := coupon.NewCouponService(mockCouponRepository, mockProductRepository, mockCategoryRepository, systemClock)
+	mockTaxService := tax.NewTaxService(mockRegionRepository, unitOfWork)
 
-	return NewApp(mockProductService, mockOrderService, mockCampaignService)
+	mockStateService := state.NewStateService(mockProductRepository, mockOrderRepository, mockCampaignRepository, mockWarehouseRepository, mockLedgerRepository, mockCategoryRepository, mockCouponRepository, mockRegionRepository, unitOfWork)
+
+	scheduler := campaign.NewScheduler(mockCampaignRepository, mockProductRepository, systemClock.Now())
+
+	return NewApp(mockProductService, mockOrderService, mockCampaignService, mockWarehouseService, mockLedgerService, mockCategoryService, mockCouponService, mockTaxService, scheduler, mockStateService, systemClock)
 }
 
 func TestNewApp(t *testing.T) {
@@ -95,6 +134,21 @@ func TestAppCreateProduct(t *testing.T) {
 		assert.Equal(t, 1000, p.Stock.Value())
 	})
 
+	t.Run("invalid backorder limit", func(t *testing.T) {
+		msg, err := app.createProduct([]string{"P7", "100", "10", "--backorder-limit", "-1"})
+		assert.ErrorIs(t, err, product.ErrBackorderLimitCannotBeNegative)
+		assert.Equal(t, "", msg)
+
+		_, err = app.productService.Get("P7")
+		assert.Error(t, err)
+	})
+
+	t.Run("backorders and launch", func(t *testing.T) {
+		msg, err := app.createProduct([]string{"P8", "100", "10", "--backorder-limit", "5", "--launch", "3"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Product created; code P8, price 100.0, stock 10, backordered 0, backorder limit 5, launch 03:00", msg)
+	})
+
 }
 
 func TestAppGetProductInfo(t *testing.T) {
@@ -154,7 +208,10 @@ func TestAppCreateOrder(t *testing.T) {
 	t.Run("valid parameters", func(t *testing.T) {
 		msg, err := app.createOrder([]string{"P1", "10"})
 		assert.NoError(t, err)
-		assert.Equal(t, "Order created; product P1, quantity 10", msg)
+
+		newOrder, err := app.orderSerivce.Get(orderID(msg))
+		assert.NoError(t, err)
+		assert.Equal(t, "Order created; id "+newOrder.ID.String()+", product P1, quantity 10", msg)
 
 		p, err := app.productService.Get("P1")
 		assert.NoError(t, err)
@@ -163,9 +220,468 @@ func TestAppCreateOrder(t *testing.T) {
 
 }
 
+// orderID returns the ID printed by create_order.
+func orderID(msg string) string {
+	return strings.TrimSuffix(strings.Fields(msg)[3], ",")
+}
+
+func TestAppBackorders(t *testing.T) {
+	app := setup(t)
+	app.createProduct([]string{"P1", "100", "10", "--backorder-limit", "5", "--launch", "2"})
+	app.createCampaign([]string{"C1", "P1", "10", "20", "10", "--backorders", "Fulfillment"})
+	campaign, _ := app.campaignSerivce.Get("C1")
+
+	msg, err := app.createOrder([]string{"P1", "8"})
+	assert.NoError(t, err)
+	assert.Contains(t, msg, ", status PreOrdered, backordered 8")
+	preOrder := orderID(msg)
+
+	msg, err = app.createOrder([]string{"P1", "8"})
+	assert.ErrorIs(t, err, order.ErrInsufficientStock)
+	assert.Equal(t, "", msg)
+
+	msg, _ = app.getProductInfo([]string{"P1"})
+	assert.Equal(t, "Product P1 info; price 100.0, stock 10, backordered 8, backorder limit 5, launch 02:00", msg)
+	assert.Equal(t, 0, campaign.TotalSales.Value())
+
+	app.increaseTime([]string{"2"})
+	msg, _ = app.getOrder([]string{preOrder})
+	assert.Contains(t, msg, "status Placed")
+	assert.Equal(t, 8, campaign.TotalSales.Value())
+
+	msg, err = app.createOrder([]string{"P1", "5"})
+	assert.NoError(t, err)
+	assert.Contains(t, msg, ", status Backordered, backordered 3")
+	backorder := orderID(msg)
+
+	msg, _ = app.getOrder([]string{backorder})
+	assert.Contains(t, msg, "status Backordered, backordered 3")
+	assert.Equal(t, 10, campaign.TotalSales.Value())
+	assert.Equal(t, valueobject.Ended, campaign.Status.Value())
+
+	t.Run("invalid restock", func(t *testing.T) {
+		msg, err := app.Run([]string{"restock", "P1"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"restock", "P1", "many"})
+		assert.ErrorIs(t, err, ErrAmountMustBeInt)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"restock", "P1", "-1"})
+		assert.ErrorIs(t, err, order.ErrRestockAmountMustBePositive)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("restock fulfills the backorder", func(t *testing.T) {
+		msg, err := app.Run([]string{"restock", "P1", "5"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Product P1 restocked; stock 2, fulfilled orders 1, backordered 0, backorder limit 5", msg)
+
+		msg, _ = app.getOrder([]string{backorder})
+		assert.Contains(t, msg, "status Placed")
+		assert.Equal(t, 10, campaign.TotalSales.Value())
+	})
+}
+
+func TestAppWarehouses(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 100)
+
+	t.Run("invalid warehouse", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_warehouse", "W1", "3"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_warehouse", "W1", "3", "north"})
+		assert.ErrorIs(t, err, ErrCoordinateMustBeFloat)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"create_warehouse", "W1", "0", "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Warehouse created; code W1, location 0.0,0.0", msg)
+	app.Run([]string{"create_warehouse", "W2", "10", "0"})
+
+	t.Run("invalid stock", func(t *testing.T) {
+		msg, err := app.Run([]string{"stock_warehouse", "P1", "W1", "many"})
+		assert.ErrorIs(t, err, ErrAmountMustBeInt)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"stock_warehouse", "P1", "W3", "10"})
+		assert.ErrorIs(t, err, warehouseDomain.ErrNotFound)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"stock_warehouse", "P1", "W1", "101"})
+		assert.ErrorIs(t, err, warehouse.ErrNotEnoughUnassignedStock)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err = app.Run([]string{"stock_warehouse", "P1", "W1", "40"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Product P1 stocked in W1; stock 100, warehouses W1=40, unassigned 60", msg)
+	app.Run([]string{"stock_warehouse", "P1", "W2", "20"})
+
+	msg, _ = app.getProductInfo([]string{"P1"})
+	assert.Equal(t, "Product P1 info; price 100.0, stock 100, warehouses W1=40 W2=20, unassigned 40", msg)
+
+	t.Run("invalid order flags", func(t *testing.T) {
+		msg, err := app.createOrder([]string{"P1", "5", "--location", "1"})
+		assert.ErrorIs(t, err, ErrInvalidLocation)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.createOrder([]string{"P1", "5", "--strategy", "closest"})
+		assert.ErrorIs(t, err, valueobject.ErrAllocationStrategyMustBeOneOf)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err = app.createOrder([]string{"P1", "15", "--strategy", "nearest", "--location", "9,0"})
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "quantity 15, allocated W2=15")
+
+	msg, err = app.createOrder([]string{"P1", "10", "--location", "9,0"})
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "quantity 10, allocated W2=5 W1=5")
+
+	msg, _ = app.getOrder([]string{orderID(msg)})
+	assert.Contains(t, msg, "status Placed, allocated W2=5 W1=5, time 00:00")
+
+	msg, err = app.createOrder([]string{"P1", "20", "--strategy", "most-stock"})
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "quantity 20, allocated W1=20")
+
+	msg, _ = app.getProductInfo([]string{"P1"})
+	assert.Equal(t, "Product P1 info; price 100.0, stock 55, warehouses W1=15 W2=0, unassigned 40", msg)
+}
+
+func TestAppStockLedger(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 10)
+	p1, _ := app.productService.Get("P1")
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.Run([]string{"stock_ledger"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"adjust_stock", "P1", "few"})
+		assert.ErrorIs(t, err, ErrAmountMustBeInt)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"adjust_stock", "P1", "-11"})
+		assert.ErrorIs(t, err, product.ErrAdjustmentExceedsUnassignedStock)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.createOrder([]string{"P1", "4"})
+	assert.NoError(t, err)
+	id := orderID(msg)
+	app.increaseTime([]string{"2"})
+
+	msg, err = app.Run([]string{"adjust_stock", "P1", "-3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Product P1 stock adjusted by -3; stock 3", msg)
+
+	app.Run([]string{"cancel_order", id})
+
+	msg, err = app.Run([]string{"stock_ledger", "P1"})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"Product P1 stock ledger; movements 4, balance 7, stock 7, reconciled",
+		"00:00 Initial +10, reference " + p1.ID.String(),
+		"00:00 Sale -4, reference " + id,
+		"02:00 Adjustment -3, reference " + p1.ID.String(),
+		"02:00 Cancellation +4, reference " + id,
+	}, "\n"), msg)
+
+	app.productService.Create("P2", 10, 5)
+	other, _ := app.productService.Get("P2")
+	other.DecreaseStock(1)
+
+	msg, err = app.Run([]string{"reconcile_stock"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Stock reconciled; products 2, mismatches 1\nProduct P1, balance 7, stock 7, reconciled\nProduct P2, balance 5, stock 4, mismatch", msg)
+}
+
+func TestAppBundles(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 10)
+	app.productService.Create("P2", 50, 9)
+	app.Run([]string{"create_warehouse", "W1", "0", "0"})
+	app.Run([]string{"stock_warehouse", "P1", "W1", "4"})
+
+	t.Run("invalid bundle", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_bundle", "B1", "150"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_bundle", "B1", "150", "P1"})
+		assert.ErrorIs(t, err, ErrInvalidComponent)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_bundle", "B1", "150", "P3:1"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"create_bundle", "B1", "150", "P2:2", "P1:1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bundle created; code B1, price 150.0, stock 4, components P1:1 P2:2", msg)
+
+	msg, err = app.createOrder([]string{"B1", "3"})
+	assert.NoError(t, err)
+	assert.Contains(t, msg, "product B1, quantity 3, allocated P1@W1=3")
+
+	msg, _ = app.getProductInfo([]string{"P2"})
+	assert.Equal(t, "Product P2 info; price 50.0, stock 3", msg)
+
+	app.createOrder([]string{"P2", "2"})
+	msg, _ = app.getProductInfo([]string{"B1"})
+	assert.Equal(t, "Product B1 info; price 150.0, stock 0, components P1:1 P2:2", msg)
+
+	t.Run("bundle stock is derived", func(t *testing.T) {
+		msg, err := app.Run([]string{"restock", "B1", "5"})
+		assert.ErrorIs(t, err, order.ErrCannotBeRestocked)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"adjust_stock", "B1", "5"})
+		assert.ErrorIs(t, err, product.ErrStockIsDerived)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"stock_warehouse", "B1", "W1", "1"})
+		assert.ErrorIs(t, err, warehouse.ErrCannotBeStocked)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"stock_ledger", "B1"})
+		assert.ErrorIs(t, err, ledger.ErrNoLedger)
+		assert.Equal(t, "", msg)
+	})
+
+	app.Run([]string{"restock", "P2", "4"})
+	_, err = app.createCampaign([]string{"C1", "B1", "5", "20", "2"})
+	assert.NoError(t, err)
+
+	_, err = app.createOrder([]string{"B1", "2"})
+	assert.NoError(t, err)
+	campaign, _ := app.campaignSerivce.Get("C1")
+	assert.Equal(t, 2, campaign.TotalSales.Value())
+
+	msg, _ = app.Run([]string{"reconcile_stock"})
+	assert.Equal(t, "Stock reconciled; products 2, mismatches 0\nProduct P1, balance 5, stock 5, reconciled\nProduct P2, balance 1, stock 1, reconciled", msg)
+
+	t.Run("bundle campaign sees the components sell out", func(t *testing.T) {
+		app.Run([]string{"restock", "P2", "10"})
+		_, err := app.createCampaign([]string{"C2", "B1", "5", "20", "4"})
+		assert.NoError(t, err)
+
+		_, err = app.createOrder([]string{"P1", "5"})
+		assert.NoError(t, err)
+
+		app.increaseTime([]string{"1"})
+		campaign, _ := app.campaignSerivce.Get("C2")
+		assert.True(t, campaign.IsEnded())
+		assert.Equal(t, valueobject.StockExhausted, campaign.EndReason.Value())
+	})
+}
+
+func TestAppVariants(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("T1", 20, 0)
+
+	t.Run("invalid variant", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_variant", "T1", "V1", "6"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_variant", "T1", "V1", "6", "size"})
+		assert.ErrorIs(t, err, ErrInvalidAttribute)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_variant", "T1", "V1", "6", "size=S", "size=M"})
+		assert.ErrorIs(t, err, ErrInvalidAttribute)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_variant", "T1", "V1", "6", "size=S", "--price", "abc"})
+		assert.ErrorIs(t, err, ErrPriceMustBeFloat)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_variant", "T2", "V1", "6", "size=S"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"create_variant", "T1", "V1", "6", "size=S", "colour=red"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Variant created; code V1, price 20.0, stock 6, parent T1, attributes colour=red size=S", msg)
+
+	msg, err = app.Run([]string{"create_variant", "T1", "V2", "4", "size=M", "--price", "25"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Variant created; code V2, price 25.0, stock 4, parent T1, attributes size=M", msg)
+
+	msg, _ = app.getProductInfo([]string{"T1"})
+	assert.Equal(t, "Product T1 info; price 20.0, stock 10, variants V1 V2", msg)
+
+	t.Run("parent stock is derived", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_order", "T1", "1"})
+		assert.ErrorIs(t, err, order.ErrParentCannotBeOrdered)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"restock", "T1", "5"})
+		assert.ErrorIs(t, err, order.ErrCannotBeRestocked)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"stock_ledger", "T1"})
+		assert.ErrorIs(t, err, ledger.ErrNoLedger)
+		assert.Equal(t, "", msg)
+	})
+
+	_, err = app.createCampaign([]string{"C1", "T1", "5", "20", "5"})
+	assert.NoError(t, err)
+
+	app.createOrder([]string{"V1", "2"})
+	app.createOrder([]string{"V2", "1"})
+	campaign, _ := app.campaignSerivce.Get("C1")
+	assert.Equal(t, 3, campaign.TotalSales.Value())
+
+	msg, _ = app.getProductInfo([]string{"T1"})
+	assert.Equal(t, "Product T1 info; price 20.0, stock 7, variants V1 V2", msg)
+
+	app.increaseTime([]string{"1"})
+	parent, _ := app.productService.Get("T1")
+	assert.NotEqual(t, 20.0, parent.Price.Value())
+
+	msg, _ = app.getProductInfo([]string{"V1"})
+	assert.Equal(t, fmt.Sprintf("Product V1 info; price %.1f, stock 4, parent T1, attributes colour=red size=S", parent.Price.Value()), msg)
+
+	msg, _ = app.getProductInfo([]string{"V2"})
+	assert.Equal(t, "Product V2 info; price 25.0, stock 3, parent T1, attributes size=M", msg)
+
+	t.Run("variants follow the parent when its campaign is cancelled", func(t *testing.T) {
+		_, err := app.Run([]string{"cancel_campaign", "C1"})
+		assert.NoError(t, err)
+
+		variant, _ := app.productService.Get("V1")
+		assert.Equal(t, 20.0, variant.Price.Value())
+	})
+}
+
+func TestAppGetOrder(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	app.increaseTime([]string{"2"})
+	created, _ := app.createOrder([]string{"P1", "10"})
+	id := orderID(created)
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.getOrder([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid order id", func(t *testing.T) {
+		msg, err := app.getOrder([]string{"invalid"})
+		assert.ErrorIs(t, err, order.ErrInvalidOrderID)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("order not found", func(t *testing.T) {
+		msg, err := app.getOrder([]string{uuid.New().String()})
+		assert.ErrorIs(t, err, orderDomain.ErrOrderNotFound)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid parameters", func(t *testing.T) {
+		msg, err := app.getOrder([]string{id})
+		assert.NoError(t, err)
+		assert.Equal(t, "Order "+id+" info; product P1, quantity 10, price 100.0, total 1000.0, status Placed, time 02:00", msg)
+	})
+}
+
+func TestAppChangeOrderStatus(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	created, _ := app.createOrder([]string{"P1", "10"})
+	id := orderID(created)
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.Run([]string{"pay_order"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid order id", func(t *testing.T) {
+		msg, err := app.Run([]string{"pay_order", "invalid"})
+		assert.ErrorIs(t, err, order.ErrInvalidOrderID)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("illegal transition", func(t *testing.T) {
+		msg, err := app.Run([]string{"deliver_order", id})
+		assert.ErrorIs(t, err, valueobject.ErrIllegalOrderStatusTransition)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid transitions", func(t *testing.T) {
+		msg, err := app.Run([]string{"pay_order", id})
+		assert.NoError(t, err)
+		assert.Equal(t, "Order "+id+" status; Paid", msg)
+
+		msg, err = app.Run([]string{"ship_order", id})
+		assert.NoError(t, err)
+		assert.Equal(t, "Order "+id+" status; Shipped", msg)
+
+		msg, err = app.Run([]string{"cancel_order", id})
+		assert.ErrorIs(t, err, valueobject.ErrIllegalOrderStatusTransition)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"deliver_order", id})
+		assert.NoError(t, err)
+		assert.Equal(t, "Order "+id+" status; Delivered", msg)
+	})
+
+	t.Run("cancel placed order", func(t *testing.T) {
+		created, _ := app.createOrder([]string{"P1", "1"})
+		other := orderID(created)
+
+		msg, err := app.Run([]string{"cancel_order", other})
+		assert.NoError(t, err)
+		assert.Equal(t, "Order "+other+" status; Cancelled", msg)
+
+		msg, err = app.Run([]string{"pay_order", other})
+		assert.ErrorIs(t, err, valueobject.ErrIllegalOrderStatusTransition)
+		assert.Equal(t, "", msg)
+	})
+}
+
+func TestAppCancelCampaignOrder(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	app.Run([]string{"create_campaign", "C1", "P1", "10", "20", "100"})
+
+	created, err := app.createOrder([]string{"P1", "10"})
+	assert.NoError(t, err)
+	id := orderID(created)
+
+	msg, err := app.Run([]string{"get_campaign_info", "C1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 100, Total Sales 10, Turnover 1000.0, Average Item Price 100.0", msg)
+
+	_, err = app.Run([]string{"cancel_order", id})
+	assert.NoError(t, err)
+
+	msg, err = app.Run([]string{"get_campaign_info", "C1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0", msg)
+
+	p, err := app.productService.Get("P1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, p.TotalDemandCount.Value())
+}
+
 func TestAppCreateCampaign(t *testing.T) {
 	app := setup(t)
 	app.productService.Create("P1", 100, 1000)
+	app.productService.Create("P3", 100, 1000)
 
 	t.Parallel()
 
@@ -211,15 +727,37 @@ func TestAppCreateCampaign(t *testing.T) {
 		assert.Equal(t, 10, c.Duration.Value())
 		assert.Equal(t, 20, c.PriceManipulationLimit.Value())
 		assert.Equal(t, 3, c.TargetSalesCount.Value())
+		assert.Equal(t, valueobject.EndOnStockOut, c.StockPolicy.Value())
+	})
+
+	t.Run("product already has a campaign", func(t *testing.T) {
+		msg, err := app.createCampaign([]string{"C4", "P1", "10", "20", "3"})
+		assert.ErrorIs(t, err, campaign.ErrProductHasCampaign)
+		assert.Equal(t, "", msg)
 	})
 
+	t.Run("invalid stock policy", func(t *testing.T) {
+		msg, err := app.createCampaign([]string{"C2", "P1", "10", "20", "3", "--stock-policy", "Stop"})
+		assert.ErrorIs(t, err, valueobject.ErrStockPolicyMustBeOneOf)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid stock policy", func(t *testing.T) {
+		msg, err := app.createCampaign([]string{"C3", "P3", "10", "20", "3", "--stock-policy", "Pause"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign created; name C3, product P3, duration 10, limit 20, target sales count 3", msg)
+
+		c, err := app.campaignSerivce.Get("C3")
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.PauseOnStockOut, c.StockPolicy.Value())
+	})
 }
 
 func TestAppGetCampaignInfo(t *testing.T) {
 	app := setup(t)
 	app.productService.Create("P1", 100, 1000)
 	product, _ := app.productService.Get("P1")
-	app.campaignSerivce.Create("C1", product, 10, 20, 100)
+	app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 
 	t.Parallel()
 
@@ -243,31 +781,755 @@ func TestAppGetCampaignInfo(t *testing.T) {
 
 }
 
-func TestAppIncreaseTime(t *testing.T) {
+func TestAppAmendCampaign(t *testing.T) {
 	app := setup(t)
-	app.productService.Create("P1", 100, 1000)
+	app.productService.Create("P1", 100, 100)
 	product, _ := app.productService.Get("P1")
-	app.campaignSerivce.Create("C1", product, 10, 20, 100)
-
-	t.Parallel()
+	app.campaignSerivce.Create("C1", product, 5, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+	app.increaseTime([]string{"3"})
 
 	t.Run("invalid parameters", func(t *testing.T) {
-		msg, err := app.increaseTime([]string{})
-		assert.NotNil(t, err)
+		msg, err := app.amendCampaign([]string{"C1"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.amendCampaign([]string{"C1", "--stock", "3"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
 		assert.Equal(t, "", msg)
 	})
 
-	t.Run("invalid time", func(t *testing.T) {
-		msg, err := app.increaseTime([]string{"invalid_time"})
-		assert.ErrorIs(t, err, ErrHourMustBeInt)
+	t.Run("invalid duration", func(t *testing.T) {
+		msg, err := app.amendCampaign([]string{"C1", "--duration", "long"})
+		assert.ErrorIs(t, err, ErrDurationMustBeInt)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("target above stock", func(t *testing.T) {
+		msg, err := app.amendCampaign([]string{"C1", "--target", "120"})
+		assert.ErrorIs(t, err, campaign.ErrTargetSalesCountMustBeLessThanStock)
 		assert.Equal(t, "", msg)
 	})
 
 	t.Run("valid parameters", func(t *testing.T) {
-		msg, err := app.increaseTime([]string{"10"})
+		msg, err := app.amendCampaign([]string{"C1", "--duration", "+3", "--limit", "30", "--target", "80"})
 		assert.NoError(t, err)
-		assert.Equal(t, "Time is 10:00", msg)
-		assert.Equal(t, 10, app.systemTime.Hour())
+		assert.Equal(t, "Campaign C1 amended; duration 5, limit 30, target sales count 80", msg)
+
+		msg, err = app.getCampaignAmendments([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign C1 amendments; count 1\nAmended at 03:00; duration +3, limit 30, target sales count 80", msg)
+	})
+
+	t.Run("ended campaign", func(t *testing.T) {
+		app.increaseTime([]string{"5"})
+
+		msg, err := app.amendCampaign([]string{"C1", "--duration", "2"})
+		assert.ErrorIs(t, err, campaign.ErrCampaignEnded)
+		assert.Equal(t, "", msg)
+	})
+}
+
+func TestAppCancelCampaign(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 100)
+	product, _ := app.productService.Get("P1")
+	app.campaignSerivce.Create("C1", product, 5, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+	app.getProductInfo([]string{"P1"})
+	app.increaseTime([]string{"2"})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.cancelCampaign([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
 	})
 
+	t.Run("valid parameters", func(t *testing.T) {
+		msg, err := app.cancelCampaign([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign C1 cancelled; final price 80.0", msg)
+
+		msg, err = app.getCampaignInfo([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign C1 info; Status Ended, Target Sales 50, Total Sales 0, Turnover 0.0, Average Item Price 0.0, End Reason Cancelled, Ended At 02:00, Final Price 80.0", msg)
+
+		msg, _ = app.getProductInfo([]string{"P1"})
+		assert.Equal(t, "Product P1 info; price 100.0, stock 100", msg)
+	})
+
+	t.Run("campaign already ended", func(t *testing.T) {
+		msg, err := app.cancelCampaign([]string{"C1"})
+		assert.ErrorIs(t, err, campaign.ErrCampaignAlreadyEnded)
+		assert.Equal(t, "", msg)
+	})
+}
+
+func TestAppStockExhaustion(t *testing.T) {
+	t.Run("end campaign", func(t *testing.T) {
+		app := setup(t)
+		app.productService.Create("P1", 100, 100)
+		product, _ := app.productService.Get("P1")
+		app.campaignSerivce.Create("C1", product, 5, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		product.DecreaseStock(60)
+
+		app.increaseTime([]string{"1"})
+		msg, err := app.getCampaignInfo([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign C1 info; Status Ended, Target Sales 50, Total Sales 0, Turnover 0.0, Average Item Price 0.0, End Reason StockExhausted, Ended At 01:00, Final Price 100.0", msg)
+		assert.Nil(t, product.Campaign)
+	})
+
+	t.Run("pause campaign until stock covers the target", func(t *testing.T) {
+		app := setup(t)
+		app.productService.Create("P1", 100, 100)
+		product, _ := app.productService.Get("P1")
+		app.campaignSerivce.Create("C1", product, 5, 20, 50, valueobject.PauseOnStockOut, valueobject.CountOnOrder)
+		campaign, _ := app.campaignSerivce.Get("C1")
+		product.DecreaseStock(60)
+
+		app.increaseTime([]string{"1"})
+		app.increaseTime([]string{"1"})
+		msg, _ := app.getCampaignInfo([]string{"C1"})
+		assert.Equal(t, "Campaign C1 info; Status Paused, Target Sales 50, Total Sales 0, Turnover 0.0, Average Item Price 0.0", msg)
+		assert.Equal(t, 4, campaign.Duration.Value())
+		assert.Same(t, campaign, product.Campaign)
+
+		_, err := app.amendCampaign([]string{"C1", "--target", "40"})
+		assert.NoError(t, err)
+		app.increaseTime([]string{"1"})
+		assert.Equal(t, valueobject.Active, campaign.Status.Value())
+		assert.Equal(t, 3, campaign.Duration.Value())
+	})
+
+	t.Run("keep campaign running", func(t *testing.T) {
+		app := setup(t)
+		app.productService.Create("P1", 100, 100)
+		product, _ := app.productService.Get("P1")
+		app.campaignSerivce.Create("C1", product, 5, 20, 50, valueobject.KeepOnStockOut, valueobject.CountOnOrder)
+		campaign, _ := app.campaignSerivce.Get("C1")
+		product.DecreaseStock(60)
+
+		app.increaseTime([]string{"1"})
+		assert.Equal(t, valueobject.Active, campaign.Status.Value())
+		assert.Equal(t, 4, campaign.Duration.Value())
+	})
+}
+
+func TestAppIncreaseTime(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	product, _ := app.productService.Get("P1")
+	app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+
+	t.Parallel()
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.increaseTime([]string{})
+		assert.NotNil(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		msg, err := app.increaseTime([]string{"invalid_time"})
+		assert.ErrorIs(t, err, ErrHourMustBeInt)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("negative time", func(t *testing.T) {
+		msg, err := app.increaseTime([]string{"-1"})
+		assert.ErrorIs(t, err, ErrHourCannotBeNegative)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid parameters", func(t *testing.T) {
+		msg, err := app.increaseTime([]string{"10"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Time is 10:00", msg)
+		assert.Equal(t, 10, app.clock.Now().Hour())
+	})
+
+}
+
+func TestAppIncreaseTimeWithoutCampaigns(t *testing.T) {
+	app := setup(t)
+
+	msg, err := app.increaseTime([]string{"3"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Time is 03:00", msg)
+}
+
+func TestAppIncreaseTimeStepsHourly(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	product, _ := app.productService.Get("P1")
+	app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+	campaign, _ := app.campaignSerivce.Get("C1")
+
+	_, err := app.increaseTime([]string{"5"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, campaign.Duration.Value())
+
+	_, err = app.increaseTime([]string{"5"})
+	assert.NoError(t, err)
+	msg, _ := app.getCampaignInfo([]string{"C1"})
+	assert.Equal(t, "Campaign C1 info; Status Ended, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0, End Reason Expired, Ended At 10:00, Final Price 100.0", msg)
+}
+
+func TestAppConcurrentCommands(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 100)
+	product, _ := app.productService.Get("P1")
+	app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+
+	commands := [][]string{
+		{"create_order", "P1", "1"},
+		{"get_product_info", "P1"},
+		{"get_campaign_info", "C1"},
+		{"increase_time", "1"},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(args []string) {
+			defer wg.Done()
+			app.Run(args)
+		}(commands[i%len(commands)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, product.Stock.Value())
+	assert.Equal(t, 50*time.Hour, app.clock.Now().Sub(time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestAppSimulate(t *testing.T) {
+	t.Run("invalid parameters", func(t *testing.T) {
+		app := setup(t)
+		msg, err := app.simulate([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid hours", func(t *testing.T) {
+		app := setup(t)
+		msg, err := app.simulate([]string{"invalid_hours"})
+		assert.ErrorIs(t, err, ErrHourMustBeInt)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid seed", func(t *testing.T) {
+		app := setup(t)
+		msg, err := app.simulate([]string{"5", "--seed", "invalid_seed"})
+		assert.ErrorIs(t, err, ErrSeedMustBeInt)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("several invalid flags", func(t *testing.T) {
+		app := setup(t)
+		for i := 0; i < 10; i++ {
+			msg, err := app.simulate([]string{"5", "--max-quantity", "invalid", "--views", "invalid", "--seed", "invalid_seed"})
+			assert.ErrorIs(t, err, ErrSeedMustBeInt)
+			assert.Equal(t, "", msg)
+		}
+	})
+
+	t.Run("NaN views", func(t *testing.T) {
+		app := setup(t)
+		msg, err := app.simulate([]string{"5", "--views", "NaN"})
+		assert.ErrorIs(t, err, simulation.ErrDemandModelMustBeFinite)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		app := setup(t)
+		msg, err := app.simulate([]string{"5", "--unknown", "1"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("without campaign", func(t *testing.T) {
+		app := setup(t)
+		app.productService.Create("P1", 100, 1000)
+
+		msg, err := app.simulate([]string{"5", "--conversion", "0"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Simulation finished; hours 5, views 54, orders 0, units 0", msg)
+		assert.Equal(t, 5, app.clock.Now().Hour())
+	})
+
+	t.Run("with campaign", func(t *testing.T) {
+		app := setup(t)
+		app.productService.Create("P1", 100, 1000)
+		product, _ := app.productService.Get("P1")
+		app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+
+		msg, err := app.simulate([]string{"3", "--seed", "7", "--views", "20", "--conversion", "1", "--max-quantity", "1"})
+		assert.NoError(t, err)
+		assert.Contains(t, msg, "Campaign C1 info; Status Active")
+		assert.Equal(t, 7, product.Campaign.Duration.Value())
+	})
+}
+
+func TestParseFlags(t *testing.T) {
+	args, flags, err := parseFlags([]string{"ABC", "--target", "50", "10"}, "target")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ABC", "10"}, args)
+	assert.Equal(t, map[string]string{"target": "50"}, flags)
+
+	_, _, err = parseFlags([]string{"ABC", "--target"}, "target")
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+
+	_, _, err = parseFlags([]string{"--target", "1", "--target", "2"}, "target")
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+
+	_, _, err = parseFlags([]string{"ABC", "--limit", "10"}, "target")
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+}
+
+func TestAppOptimizeCampaign(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 100)
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.optimizeCampaign([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		msg, err := app.optimizeCampaign([]string{"P1", "--target", "invalid_target"})
+		assert.ErrorIs(t, err, ErrTargetSalesMustBeInt)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("invalid runs", func(t *testing.T) {
+		msg, err := app.optimizeCampaign([]string{"P1", "--runs", "invalid_runs"})
+		assert.ErrorIs(t, err, ErrRunsMustBeInt)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("product not found", func(t *testing.T) {
+		msg, err := app.optimizeCampaign([]string{"P2"})
+		assert.NotNil(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid parameters", func(t *testing.T) {
+		msg, err := app.optimizeCampaign([]string{"P1", "--target", "50", "--runs", "2", "--max-duration", "3"})
+		assert.NoError(t, err)
+		assert.Contains(t, msg, "Optimization finished; product P1, candidates 30, runs 2, objective turnover")
+		assert.Contains(t, msg, "target sales count 50")
+
+		p, _ := app.productService.Get("P1")
+		assert.Equal(t, 100, p.Stock.Value())
+		assert.Nil(t, p.Campaign)
+		_, err = app.campaignSerivce.GetAll()
+		assert.Error(t, err)
+	})
+}
+
+func TestAppSaveLoadState(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	product, _ := app.productService.Get("P1")
+	app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+	app.increaseTime([]string{"3"})
+	file := filepath.Join(t.TempDir(), "state.json")
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.saveState([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.loadState([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		msg, err := app.loadState([]string{filepath.Join(t.TempDir(), "missing.json")})
+		assert.NotNil(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid parameters", func(t *testing.T) {
+		msg, err := app.saveState([]string{file})
+		assert.NoError(t, err)
+		assert.Equal(t, "State saved; file "+file+", time is 03:00", msg)
+
+		app.createOrder([]string{"P1", "10"})
+		app.increaseTime([]string{"2"})
+
+		msg, err = app.loadState([]string{file})
+		assert.NoError(t, err)
+		assert.Equal(t, "State loaded; file "+file+", time is 03:00", msg)
+
+		msg, err = app.getProductInfo([]string{"P1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Product P1 info; price 100.0, stock 1000", msg)
+
+		msg, err = app.getCampaignInfo([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0", msg)
+	})
+}
+
+func TestAppBranches(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 1000)
+	product, _ := app.productService.Get("P1")
+	app.campaignSerivce.Create("C1", product, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+	app.increaseTime([]string{"1"})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		msg, err := app.fork([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.switchBranch([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.discard([]string{"main"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.compareCampaign([]string{})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("main branch cannot be discarded", func(t *testing.T) {
+		msg, err := app.discard([]string{})
+		assert.ErrorIs(t, err, state.ErrCannotDiscardMainBranch)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("branch not found", func(t *testing.T) {
+		msg, err := app.switchBranch([]string{"missing"})
+		assert.ErrorIs(t, err, state.ErrBranchNotFound)
+		assert.Equal(t, "", msg)
+	})
+
+	t.Run("valid parameters", func(t *testing.T) {
+		msg, err := app.fork([]string{"what-if"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Branch forked; name what-if, from main", msg)
+
+		app.createOrder([]string{"P1", "10"})
+		app.increaseTime([]string{"2"})
+
+		msg, err = app.compareCampaign([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Branch main; Campaign C1 info; Status Active, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0\n"+
+			"Branch what-if; Campaign C1 info; Status Active, Target Sales 100, Total Sales 10, Turnover 1000.0, Average Item Price 100.0", msg)
+
+		msg, err = app.compareCampaign([]string{"C2"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Branch main; Campaign C2 not found\nBranch what-if; Campaign C2 not found", msg)
+
+		msg, err = app.switchBranch([]string{"main"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Switched to branch main; time is 01:00", msg)
+
+		msg, err = app.getProductInfo([]string{"P1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Product P1 info; price 100.0, stock 1000", msg)
+
+		msg, err = app.switchBranch([]string{"what-if"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Switched to branch what-if; time is 03:00", msg)
+
+		msg, err = app.discard([]string{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Branch what-if discarded; switched to main, time is 01:00", msg)
+
+		msg, err = app.getCampaignInfo([]string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 100, Total Sales 0, Turnover 0.0, Average Item Price 0.0", msg)
+	})
+}
+
+func TestAppCategories(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 50)
+	app.productService.Create("P2", 80, 30)
+	app.productService.Create("P3", 60, 20)
+
+	t.Run("invalid category", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_category"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_category", "SHIRTS", "--parent", "APPAREL"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"assign_category", "P1", "APPAREL"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"create_category", "APPAREL"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Category created; code APPAREL", msg)
+
+	msg, err = app.Run([]string{"create_category", "SHIRTS", "--parent", "APPAREL"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Category created; code SHIRTS, parent APPAREL", msg)
+
+	msg, err = app.Run([]string{"get_category_info", "APPAREL"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Category APPAREL info; subcategories SHIRTS, products none", msg)
+
+	t.Run("category campaign without products", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_category_campaign", "SALE", "APPAREL", "5", "20", "10"})
+		assert.ErrorIs(t, err, campaign.ErrCategoryHasNoProducts)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err = app.Run([]string{"assign_category", "P1", "SHIRTS"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Product P1 assigned to category SHIRTS", msg)
+	app.Run([]string{"assign_category", "P2", "APPAREL"})
+
+	msg, _ = app.Run([]string{"get_category_info", "SHIRTS"})
+	assert.Equal(t, "Category SHIRTS info; parent APPAREL, products P1", msg)
+
+	msg, _ = app.getProductInfo([]string{"P1"})
+	assert.Equal(t, "Product P1 info; price 100.0, stock 50, category SHIRTS", msg)
+
+	t.Run("invalid category campaign", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_category_campaign", "SALE", "APPAREL", "5", "20"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_category_campaign", "SALE", "APPAREL", "5", "20", "90"})
+		assert.ErrorIs(t, err, campaign.ErrTargetSalesCountMustBeLessThanStock)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err = app.Run([]string{"create_category_campaign", "SALE", "APPAREL", "5", "20", "40"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Campaign created; name SALE, category APPAREL, products P1 P2, duration 5, limit 20, target sales count 40", msg)
+
+	app.createOrder([]string{"P1", "10"})
+	app.createOrder([]string{"P2", "5"})
+	app.createOrder([]string{"P3", "5"})
+
+	msg, _ = app.getCampaignInfo([]string{"SALE"})
+	assert.Equal(t, "Campaign SALE info; Status Active, Target Sales 40, Total Sales 15, Turnover 1400.0, Average Item Price 93.3, Category APPAREL", msg)
+
+	app.increaseTime([]string{"1"})
+	p1, _ := app.productService.Get("P1")
+	p3, _ := app.productService.Get("P3")
+	assert.NotEqual(t, 100.0, p1.Price.Value())
+	assert.Equal(t, 60.0, p3.Price.Value())
+
+	msg, err = app.Run([]string{"cancel_campaign", "SALE"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Campaign SALE cancelled; category APPAREL", msg)
+	assert.Equal(t, 100.0, p1.Price.Value())
+	assert.Nil(t, p1.Campaign)
+
+	msg, _ = app.getCampaignInfo([]string{"SALE"})
+	assert.Equal(t, "Campaign SALE info; Status Ended, Target Sales 40, Total Sales 15, Turnover 1400.0, Average Item Price 93.3, Category APPAREL, End Reason Cancelled, Ended At 01:00", msg)
+}
+
+func TestAppCoupons(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 50)
+	app.productService.Create("P2", 80, 30)
+	app.categoryService.Create("APPAREL", "")
+	app.categoryService.Assign("P2", "APPAREL")
+
+	t.Run("invalid coupon", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_coupon", "SAVE10", "Fixed"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_coupon", "SAVE10", "Fixed", "ten"})
+		assert.ErrorIs(t, err, ErrDiscountMustBeFloat)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_coupon", "SAVE10", "Fixed", "10", "--limit", "many"})
+		assert.ErrorIs(t, err, ErrUsageLimitMustBeInt)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_coupon", "SAVE10", "Half", "10"})
+		assert.ErrorIs(t, err, valueobject.ErrDiscountTypeMustBeOneOf)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_coupon", "SAVE10", "Fixed", "10", "--products", "P9"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"create_coupon", "SAVE10", "Percent", "10", "--limit", "2", "--expires", "3", "--categories", "APPAREL"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Coupon created; code SAVE10, discount 10.0%, usage limit 2, expires 03:00, categories APPAREL", msg)
+
+	msg, err = app.Run([]string{"create_coupon", "FIVE", "Fixed", "5", "--products", "P1,P2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Coupon created; code FIVE, discount 5.0, products P1 P2", msg)
+
+	t.Run("coupon which does not apply", func(t *testing.T) {
+		msg, err := app.Run([]string{"create_order", "P1", "1", "--coupon", "SAVE10"})
+		assert.ErrorIs(t, err, order.ErrCouponNotApplicable)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"create_order", "P1", "1", "--coupon", "NONE"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	app.Run([]string{"create_campaign", "C1", "P2", "5", "20", "10"})
+
+	msg, err = app.Run([]string{"create_order", "P2", "2", "--coupon", "SAVE10"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Order created; id \S+, product P2, quantity 2, coupon SAVE10, discount 16.0, total 144.0$`, msg)
+
+	orderID := strings.Fields(msg)[3]
+	msg, _ = app.getOrder([]string{strings.TrimSuffix(orderID, ",")})
+	assert.Contains(t, msg, "price 80.0, total 144.0")
+	assert.True(t, strings.HasSuffix(msg, ", campaign C1, coupon SAVE10, discount 16.0"))
+
+	msg, _ = app.getCampaignInfo([]string{"C1"})
+	assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 10, Total Sales 2, Turnover 144.0, Average Item Price 72.0", msg)
+
+	msg, _ = app.getCouponInfo([]string{"SAVE10"})
+	assert.Equal(t, "Coupon SAVE10 info; uses 1, discount 10.0%, usage limit 2, expires 03:00, categories APPAREL", msg)
+
+	app.increaseTime([]string{"3"})
+
+	msg, err = app.Run([]string{"create_order", "P2", "1", "--coupon", "SAVE10"})
+	assert.ErrorIs(t, err, order.ErrCouponExpired)
+	assert.Equal(t, "", msg)
+
+	msg, _ = app.getCouponInfo([]string{"SAVE10"})
+	assert.Equal(t, "Coupon SAVE10 info; uses 1, discount 10.0%, usage limit 2, expired, categories APPAREL", msg)
+}
+
+func TestAppPriceTiers(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 20, 200)
+
+	t.Run("invalid tiers", func(t *testing.T) {
+		msg, err := app.Run([]string{"set_price_tiers"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		for _, tier := range [][]string{{"10"}, {"ten:5"}, {"10:five"}, {"10:5", "10:8"}} {
+			msg, err = app.Run(append([]string{"set_price_tiers", "P1"}, tier...))
+			assert.ErrorIs(t, err, ErrInvalidTier)
+			assert.Equal(t, "", msg)
+		}
+
+		msg, err = app.Run([]string{"set_price_tiers", "P1", "10:12", "100:5"})
+		assert.ErrorIs(t, err, product.ErrTierPercentMustIncrease)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"set_price_tiers", "P9", "10:5"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"set_price_tiers", "P1", "100:12", "10:5"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Price tiers set; product P1, price tiers 10:5.0% 100:12.0%", msg)
+
+	msg, _ = app.getProductInfo([]string{"P1"})
+	assert.Equal(t, "Product P1 info; price 20.0, stock 200, price tiers 10:5.0% 100:12.0%", msg)
+
+	app.Run([]string{"create_campaign", "C1", "P1", "5", "20", "50"})
+
+	msg, err = app.Run([]string{"create_order", "P1", "5"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Order created; id \S+, product P1, quantity 5$`, msg)
+
+	msg, err = app.Run([]string{"create_order", "P1", "10"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Order created; id \S+, product P1, quantity 10, price 19.0, list price 20.0, tier 5.0%, total 190.0$`, msg)
+
+	orderID := strings.Fields(msg)[3]
+	msg, _ = app.getOrder([]string{strings.TrimSuffix(orderID, ",")})
+	assert.Contains(t, msg, "price 19.0, total 190.0")
+	assert.True(t, strings.HasSuffix(msg, ", campaign C1, list price 20.0, tier 5.0%"))
+
+	msg, _ = app.getCampaignInfo([]string{"C1"})
+	assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 50, Total Sales 15, Turnover 290.0, Average Item Price 19.3", msg)
+
+	msg, err = app.Run([]string{"set_price_tiers", "P1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Price tiers cleared; product P1", msg)
+}
+
+func TestAppTaxes(t *testing.T) {
+	app := setup(t)
+	app.productService.Create("P1", 100, 100)
+	app.productService.Create("P2", 50, 100)
+
+	t.Run("invalid rates", func(t *testing.T) {
+		msg, err := app.Run([]string{"set_tax_rate", "EU", "STANDARD"})
+		assert.ErrorIs(t, err, ErrInvalidParameters)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"set_tax_rate", "EU", "STANDARD", "twenty"})
+		assert.ErrorIs(t, err, ErrTaxRateMustBeFloat)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"set_tax_rate", "EU", "STANDARD", "120"})
+		assert.ErrorIs(t, err, tax.ErrTaxRateOutOfRange)
+		assert.Equal(t, "", msg)
+
+		msg, err = app.Run([]string{"get_region_info", "EU"})
+		assert.Error(t, err)
+		assert.Equal(t, "", msg)
+	})
+
+	msg, err := app.Run([]string{"set_tax_rate", "EU", "STANDARD", "20"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tax rate set; region EU; rates STANDARD 20.0%", msg)
+
+	app.Run([]string{"set_tax_rate", "EU", "REDUCED", "7"})
+	app.Run([]string{"set_tax_rate", "US", "REDUCED", "5"})
+
+	msg, err = app.Run([]string{"get_region_info", "EU"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Region EU info; rates REDUCED 7.0%, STANDARD 20.0%", msg)
+
+	msg, err = app.Run([]string{"set_tax_class", "P1", "REDUCED"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tax class set; product P1, tax class REDUCED", msg)
+
+	msg, _ = app.getProductInfo([]string{"P1"})
+	assert.Equal(t, "Product P1 info; price 100.0, stock 100, tax class REDUCED", msg)
+
+	app.Run([]string{"create_campaign", "C1", "P1", "5", "20", "50"})
+
+	msg, err = app.Run([]string{"create_order", "P1", "10", "--region", "EU"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^Order created; id \S+, product P1, quantity 10, region EU, tax rate 7.0%, net 1000.0, tax 70.0, gross 1070.0$`, msg)
+
+	orderID := strings.Fields(msg)[3]
+	msg, _ = app.getOrder([]string{strings.TrimSuffix(orderID, ",")})
+	assert.True(t, strings.HasSuffix(msg, ", campaign C1, region EU, tax rate 7.0%, net 1000.0, tax 70.0, gross 1070.0"))
+
+	msg, err = app.Run([]string{"create_order", "P2", "2", "--region", "EU"})
+	assert.NoError(t, err)
+	assert.Regexp(t, `, region EU, tax rate 20.0%, net 100.0, tax 20.0, gross 120.0$`, msg)
+
+	msg, err = app.Run([]string{"create_order", "P2", "2", "--region", "US"})
+	assert.ErrorIs(t, err, order.ErrNoTaxRate)
+	assert.Equal(t, "", msg)
+
+	msg, err = app.Run([]string{"create_order", "P2", "2", "--region", "UK"})
+	assert.Error(t, err)
+	assert.Equal(t, "", msg)
+
+	msg, _ = app.getCampaignInfo([]string{"C1"})
+	assert.Equal(t, "Campaign C1 info; Status Active, Target Sales 50, Total Sales 10, Turnover 1000.0, Average Item Price 100.0, Tax 70.0, Gross Turnover 1070.0", msg)
+
+	msg, err = app.Run([]string{"set_tax_class", "P1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Tax class cleared; product P1", msg)
 }
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+)
+
+func (this *App) restock(params []string) (string, error) {
+	if len(params) != 2 {
+		return "", ErrInvalidParameters
+	}
+
+	amount, err := strconv.Atoi(params[1])
+	if err != nil {
+		return "", ErrAmountMustBeInt
+	}
+
+	product, err := this.productService.Get(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	fulfilled, err := this.orderSerivce.Restock(product, amount)
+	if err != nil {
+		return "", err
+	}
+
+	product.Acquire()
+	defer product.Release()
+
+	return fmt.Sprintf("Product %s restocked; stock %d, fulfilled orders %d", product.Code.Value(), product.Stock.Value(), len(fulfilled)) + backorderInfo(product, this.clock.Now()), nil
+}
+
+// backorderInfo describes the backorder settings of product for the product
+// messages, or returns an empty string for a product on sale without them.
+func backorderInfo(product *entity.Product, now time.Time) string {
+	var info string
+	if product.BackorderLimit.Value() > 0 || product.Backordered.Value() > 0 {
+		info += fmt.Sprintf(", backordered %d, backorder limit %d", product.Backordered.Value(), product.BackorderLimit.Value())
+	}
+	if !product.IsLaunched(now) {
+		info += fmt.Sprintf(", launch %s", product.LaunchAt.Format("15:00"))
+	}
+
+	return info
+}
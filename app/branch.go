@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (this *App) fork(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.Lock()
+	defer this.timeMu.Unlock()
+
+	parent := this.branchService.Current()
+	err := this.branchService.Fork(params[0], this.elapsedHours())
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Branch forked; name %s, from %s", params[0], parent), nil
+}
+
+func (this *App) switchBranch(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.Lock()
+	defer this.timeMu.Unlock()
+
+	hours, err := this.branchService.Switch(params[0], this.elapsedHours())
+	if err != nil {
+		return "", err
+	}
+
+	this.clock.Set(startTime.Add(time.Duration(hours) * time.Hour))
+	this.scheduler.Reset(this.clock.Now())
+	return fmt.Sprintf("Switched to branch %s; time is %s", params[0], this.clock.Now().Format("15:00")), nil
+}
+
+func (this *App) discard(params []string) (string, error) {
+	if len(params) != 0 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.Lock()
+	defer this.timeMu.Unlock()
+
+	discarded := this.branchService.Current()
+	parent, hours, err := this.branchService.Discard()
+	if err != nil {
+		return "", err
+	}
+
+	this.clock.Set(startTime.Add(time.Duration(hours) * time.Hour))
+	this.scheduler.Reset(this.clock.Now())
+	return fmt.Sprintf("Branch %s discarded; switched to %s, time is %s", discarded, parent, this.clock.Now().Format("15:00")), nil
+}
+
+func (this *App) compareCampaign(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.RLock()
+	defer this.timeMu.RUnlock()
+
+	branches, err := this.branchService.Snapshots(this.elapsedHours())
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(branches))
+	for _, branch := range branches {
+		info := fmt.Sprintf("Campaign %s not found", params[0])
+		for _, record := range branch.Snapshot.Campaigns {
+			if record.Name == params[0] {
+				info = formatCampaignInfo(record.Name, record.Status, record.TargetSalesCount, record.TotalSales, record.AverageItemPrice, record.Tax)
+				if record.EndReason != "" {
+					info += formatCampaignEnd(record.EndReason, time.Unix(record.EndedAt, 0).UTC(), record.FinalPrice)
+				}
+				break
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("Branch %s; %s", branch.Name, info))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// elapsedHours returns the hours passed since startTime; timeMu must be held.
+func (this *App) elapsedHours() int {
+	return int(this.clock.Now().Sub(startTime) / time.Hour)
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+)
+
+func (this *App) createBundle(params []string) (string, error) {
+	if len(params) < 3 {
+		return "", ErrInvalidParameters
+	}
+
+	code := params[0]
+	price, err := strconv.ParseFloat(params[1], 64)
+	if err != nil {
+		return "", ErrPriceMustBeFloat
+	}
+
+	components := make(map[string]int, len(params)-2)
+	for _, param := range params[2:] {
+		component, value, ok := strings.Cut(param, ":")
+		if !ok || component == "" {
+			return "", ErrInvalidComponent
+		}
+
+		quantity, err := strconv.Atoi(value)
+		if err != nil {
+			return "", ErrInvalidComponent
+		}
+
+		components[component] += quantity
+	}
+
+	result, err := this.productService.CreateBundle(code, price, components)
+	if err != nil {
+		return "", err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	return fmt.Sprintf("Bundle created; code %s, price %.1f, stock %d", code, price, result.Stock.Value()) + componentInfo(result), nil
+}
+
+// componentInfo lists the components of a bundle, or returns an empty string
+// for other products.
+func componentInfo(product *entity.Product) string {
+	if !product.IsBundle() {
+		return ""
+	}
+
+	components := make([]string, 0, len(product.Components))
+	for _, item := range product.Components {
+		components = append(components, fmt.Sprintf("%s:%d", item.Product.Value(), item.Quantity))
+	}
+
+	return fmt.Sprintf(", components %s", strings.Join(components, " "))
+}
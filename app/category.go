@@ -0,0 +1,151 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+func (this *App) createCategory(params []string) (string, error) {
+	args, flags, err := parseFlags(params, "parent")
+	if err != nil || len(args) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	parent := flags["parent"]
+	err = this.categoryService.Create(args[0], parent)
+	if err != nil {
+		return "", err
+	}
+
+	msg := fmt.Sprintf("Category created; code %s", args[0])
+	if parent != "" {
+		msg += fmt.Sprintf(", parent %s", parent)
+	}
+
+	return msg, nil
+}
+
+func (this *App) assignCategory(params []string) (string, error) {
+	if len(params) != 2 {
+		return "", ErrInvalidParameters
+	}
+
+	product, err := this.categoryService.Assign(params[0], params[1])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Product %s assigned to category %s", product.Code.Value(), params[1]), nil
+}
+
+func (this *App) getCategoryInfo(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	result, err := this.categoryService.Get(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	members, err := this.categoryService.Members(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	var fields []string
+	if result.Parent.Value() != "" {
+		fields = append(fields, fmt.Sprintf("parent %s", result.Parent.Value()))
+	}
+
+	var subcategories []string
+	for _, item := range this.categoryService.GetAll() {
+		if item.Parent.Equals(result.Code) {
+			subcategories = append(subcategories, item.Code.Value())
+		}
+	}
+	if len(subcategories) > 0 {
+		fields = append(fields, fmt.Sprintf("subcategories %s", strings.Join(subcategories, " ")))
+	}
+
+	fields = append(fields, fmt.Sprintf("products %s", productCodes(members)))
+
+	return fmt.Sprintf("Category %s info; %s", result.Code.Value(), strings.Join(fields, ", ")), nil
+}
+
+func (this *App) createCategoryCampaign(params []string) (string, error) {
+	args, flags, err := parseFlags(params, "stock-policy", "backorders")
+	if err != nil || len(args) != 5 {
+		return "", ErrInvalidParameters
+	}
+
+	name := args[0]
+	code := args[1]
+	duration, err := strconv.Atoi(args[2])
+	if err != nil {
+		return "", ErrDurationMustBeInt
+	}
+	limit, err := strconv.Atoi(args[3])
+	if err != nil {
+		return "", ErrLimitMustBeInt
+	}
+	targetSalesCount, err := strconv.Atoi(args[4])
+	if err != nil {
+		return "", ErrTargetSalesMustBeInt
+	}
+
+	stockPolicy := valueobject.EndOnStockOut
+	if value, ok := flags["stock-policy"]; ok {
+		stockPolicy = value
+	}
+
+	backorderPolicy := valueobject.CountOnOrder
+	if value, ok := flags["backorders"]; ok {
+		backorderPolicy = value
+	}
+
+	category, err := this.categoryService.Get(code)
+	if err != nil {
+		return "", err
+	}
+
+	members, err := this.categoryService.Members(code)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = this.campaignSerivce.CreateForCategory(name, category, members, duration, limit, targetSalesCount, stockPolicy, backorderPolicy)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Campaign created; name %s, category %s, products %s, duration %d, limit %d, target sales count %d", name, code, productCodes(members), duration, limit, targetSalesCount), nil
+}
+
+// productCodes lists the codes of products, or "none" when there are none.
+func productCodes(products []*entity.Product) string {
+	if len(products) == 0 {
+		return "none"
+	}
+
+	codes := make([]string, 0, len(products))
+	for _, item := range products {
+		codes = append(codes, item.Code.Value())
+	}
+
+	return strings.Join(codes, " ")
+}
+
+// categoryInfo names the category of product, or returns an empty string when
+// it has none.
+func categoryInfo(product *entity.Product) string {
+	if product.Category.Value() == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", category %s", product.Category.Value())
+}
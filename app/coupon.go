@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+func (this *App) createCoupon(params []string) (string, error) {
+	args, flags, err := parseFlags(params, "limit", "expires", "products", "categories")
+	if err != nil || len(args) != 3 {
+		return "", ErrInvalidParameters
+	}
+
+	code := args[0]
+	discountType := args[1]
+	amount, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return "", ErrDiscountMustBeFloat
+	}
+
+	var usageLimit, expiresIn int
+	if value, ok := flags["limit"]; ok {
+		usageLimit, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrUsageLimitMustBeInt
+		}
+	}
+
+	if value, ok := flags["expires"]; ok {
+		expiresIn, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrHourMustBeInt
+		}
+	}
+
+	result, err := this.couponService.Create(code, discountType, amount, usageLimit, expiresIn, splitList(flags["products"]), splitList(flags["categories"]))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Coupon created; code %s", code) + couponInfo(result, this.clock.Now()), nil
+}
+
+func (this *App) getCouponInfo(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	result, err := this.couponService.Get(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Coupon %s info; uses %d", result.Code.Value(), couponUses(result)) + couponInfo(result, this.clock.Now()), nil
+}
+
+// couponUses reads the uses of coupon under its lock.
+func couponUses(coupon *entity.Coupon) int {
+	coupon.Acquire()
+	defer coupon.Release()
+
+	return coupon.Uses
+}
+
+// couponInfo describes the discount and the restrictions of coupon for the
+// coupon messages.
+func couponInfo(coupon *entity.Coupon, now time.Time) string {
+	info := fmt.Sprintf(", discount %s", formatDiscount(coupon.Discount))
+	if coupon.UsageLimit > 0 {
+		info += fmt.Sprintf(", usage limit %d", coupon.UsageLimit)
+	}
+	if coupon.IsExpired(now) {
+		info += ", expired"
+	} else if !coupon.ExpiresAt.IsZero() {
+		info += fmt.Sprintf(", expires %s", coupon.ExpiresAt.Format("15:00"))
+	}
+	if len(coupon.Products) > 0 {
+		info += fmt.Sprintf(", products %s", joinCodes(coupon.Products))
+	}
+	if len(coupon.Categories) > 0 {
+		info += fmt.Sprintf(", categories %s", joinCodes(coupon.Categories))
+	}
+
+	return info
+}
+
+// formatDiscount shows a percent discount with a percent sign and a fixed one
+// as an amount.
+func formatDiscount(discount valueobject.Discount) string {
+	if discount.Type() == valueobject.PercentDiscount {
+		return fmt.Sprintf("%.1f%%", discount.Amount())
+	}
+
+	return fmt.Sprintf("%.1f", discount.Amount())
+}
+
+// discountInfo describes the coupon redeemed on order, or returns an empty
+// string when none was.
+func discountInfo(order *entity.Order) string {
+	if order.Coupon.Value() == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", coupon %s, discount %.1f", order.Coupon.Value(), order.Discount.Value())
+}
+
+// splitList splits a comma separated flag value, which may be empty.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+func joinCodes(codes []valueobject.Code) string {
+	values := make([]string, 0, len(codes))
+	for _, code := range codes {
+		values = append(values, code.Value())
+	}
+
+	return strings.Join(values, " ")
+}
@@ -0,0 +1,42 @@
+package app
+
+import "strings"
+
+// parseFlags splits command parameters into positional arguments and
+// "--name value" flags. Flags may appear anywhere after the command name and
+// must be one of allowed.
+func parseFlags(params []string, allowed ...string) ([]string, map[string]string, error) {
+	args := make([]string, 0, len(params))
+	flags := make(map[string]string)
+
+	for i := 0; i < len(params); i++ {
+		if !strings.HasPrefix(params[i], "--") {
+			args = append(args, params[i])
+			continue
+		}
+
+		name := strings.TrimPrefix(params[i], "--")
+		if !contains(allowed, name) || i+1 >= len(params) {
+			return nil, nil, ErrInvalidParameters
+		}
+
+		if _, ok := flags[name]; ok {
+			return nil, nil, ErrInvalidParameters
+		}
+
+		flags[name] = params[i+1]
+		i++
+	}
+
+	return args, flags, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
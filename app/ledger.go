@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/service/ledger"
+)
+
+func (this *App) stockLedger(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	movements, err := this.ledgerService.List(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	reconciliation, err := this.ledgerService.Reconcile(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{fmt.Sprintf("Product %s stock ledger; movements %d%s", reconciliation.Code, len(movements), reconciliationInfo(reconciliation))}
+	for _, movement := range movements {
+		lines = append(lines, fmt.Sprintf("%s %s %+d, reference %s", movement.Time.Format("15:00"), movement.Type.Value(), movement.Quantity, movement.Reference))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (this *App) reconcileStock(params []string) (string, error) {
+	if len(params) != 0 {
+		return "", ErrInvalidParameters
+	}
+
+	reconciliations := this.ledgerService.ReconcileAll()
+
+	var mismatches int
+	var products []string
+	for _, reconciliation := range reconciliations {
+		if !reconciliation.Balanced() {
+			mismatches++
+		}
+		products = append(products, fmt.Sprintf("Product %s%s", reconciliation.Code, reconciliationInfo(reconciliation)))
+	}
+
+	lines := []string{fmt.Sprintf("Stock reconciled; products %d, mismatches %d", len(reconciliations), mismatches)}
+	lines = append(lines, products...)
+	return strings.Join(lines, "\n"), nil
+}
+
+func (this *App) adjustStock(params []string) (string, error) {
+	if len(params) != 2 {
+		return "", ErrInvalidParameters
+	}
+
+	amount, err := strconv.Atoi(params[1])
+	if err != nil {
+		return "", ErrAmountMustBeInt
+	}
+
+	product, err := this.productService.AdjustStock(params[0], amount)
+	if err != nil {
+		return "", err
+	}
+
+	product.Acquire()
+	defer product.Release()
+
+	return fmt.Sprintf("Product %s stock adjusted by %+d; stock %d", product.Code.Value(), amount, product.Stock.Value()) + inventoryInfo(product), nil
+}
+
+func reconciliationInfo(reconciliation ledger.Reconciliation) string {
+	status := "reconciled"
+	if !reconciliation.Balanced() {
+		status = "mismatch"
+	}
+
+	return fmt.Sprintf(", balance %d, stock %d, %s", reconciliation.Balance, reconciliation.Stock, status)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aaydin-tr/e-commerce/service/simulation"
+)
+
+const (
+	defaultOptimizationRuns = 10
+	defaultMaxDuration      = 12
+)
+
+func (this *App) optimizeCampaign(params []string) (string, error) {
+	args, flags, err := parseFlags(params, append([]string{"target", "objective", "runs", "max-duration"}, demandModelFlags...)...)
+	if err != nil || len(args) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	model, seed, err := parseDemandModel(flags)
+	if err != nil {
+		return "", err
+	}
+
+	objective := simulation.Turnover
+	if value, ok := flags["objective"]; ok {
+		objective = value
+	}
+
+	runs := defaultOptimizationRuns
+	if value, ok := flags["runs"]; ok {
+		runs, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrRunsMustBeInt
+		}
+	}
+
+	maxDuration := defaultMaxDuration
+	if value, ok := flags["max-duration"]; ok {
+		maxDuration, err = strconv.Atoi(value)
+		if err != nil {
+			return "", ErrMaxDurationMustBeInt
+		}
+	}
+
+	product, err := this.productService.Get(args[0])
+	if err != nil {
+		return "", err
+	}
+
+	product.Acquire()
+	space := simulation.DefaultSearchSpace(product.Stock.Value(), maxDuration)
+	product.Release()
+
+	if value, ok := flags["target"]; ok {
+		target, err := strconv.Atoi(value)
+		if err != nil {
+			return "", ErrTargetSalesMustBeInt
+		}
+		space.Targets = []int{target}
+	}
+
+	optimizer := simulation.NewOptimizer(model, seed, runs)
+	result, err := optimizer.Optimize(product, space, objective)
+	if err != nil {
+		return "", err
+	}
+
+	best := result.Best
+	return fmt.Sprintf("Optimization finished; product %s, candidates %d, runs %d, objective %s\nBest settings; duration %d, limit %d, target sales count %d, expected turnover %.1f, target hit probability %.2f", args[0], result.Evaluated, runs, objective, best.Duration, best.Limit, best.TargetSalesCount, best.Turnover, best.HitProbability), nil
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/service/campaign"
+	"github.com/aaydin-tr/e-commerce/service/simulation"
+)
+
+func (this *App) simulate(params []string) (string, error) {
+	args, flags, err := parseFlags(params, demandModelFlags...)
+	if err != nil || len(args) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	hours, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", ErrHourMustBeInt
+	}
+
+	model, seed, err := parseDemandModel(flags)
+	if err != nil {
+		return "", err
+	}
+
+	simulator := simulation.NewSimulator(this.productService, this.orderSerivce, model, seed)
+	report, err := simulator.Run(hours, func() error {
+		_, err := this.advanceTime(1)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{fmt.Sprintf("Simulation finished; hours %d, views %d, orders %d, units %d", report.Hours, report.Views, report.Orders, report.Units)}
+	campaigns, err := this.campaignSerivce.GetAll()
+	if errors.Is(err, campaign.ErrNoCampaign) {
+		return lines[0], nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].Name.Value() < campaigns[j].Name.Value()
+	})
+	for _, result := range campaigns {
+		lines = append(lines, campaignInfo(result))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// demandModelFlags are the flags understood by parseDemandModel.
+var demandModelFlags = []string{"seed", "views", "conversion", "elasticity", "max-quantity"}
+
+// parseDemandModel builds a demand model and RNG seed from the simulation
+// flags, falling back to simulation.DefaultDemandModel and seed 1. Other flags
+// are ignored.
+func parseDemandModel(flags map[string]string) (simulation.DemandModel, int64, error) {
+	model := simulation.DefaultDemandModel
+	seed := int64(1)
+
+	var err error
+	if value, ok := flags["seed"]; ok {
+		seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return model, seed, ErrSeedMustBeInt
+		}
+	}
+
+	if value, ok := flags["views"]; ok {
+		model.Views, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return model, seed, ErrViewsMustBeFloat
+		}
+	}
+
+	if value, ok := flags["conversion"]; ok {
+		model.ConversionRate, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return model, seed, ErrConversionMustBeFloat
+		}
+	}
+
+	if value, ok := flags["elasticity"]; ok {
+		model.Elasticity, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return model, seed, ErrElasticityMustBeFloat
+		}
+	}
+
+	if value, ok := flags["max-quantity"]; ok {
+		model.MaxQuantity, err = strconv.Atoi(value)
+		if err != nil {
+			return model, seed, ErrMaxQuantityMustBeInt
+		}
+	}
+
+	return model, seed, nil
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+)
+
+func (this *App) saveState(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.Lock()
+	defer this.timeMu.Unlock()
+
+	var buffer bytes.Buffer
+	err := this.stateService.Save(&buffer, this.elapsedHours())
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(params[0], buffer.Bytes(), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("State saved; file %s, time is %s", params[0], this.clock.Now().Format("15:00")), nil
+}
+
+func (this *App) loadState(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	this.timeMu.Lock()
+	defer this.timeMu.Unlock()
+
+	file, err := os.Open(params[0])
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hours, err := this.stateService.Load(file)
+	if err != nil {
+		return "", err
+	}
+
+	this.clock.Set(startTime.Add(time.Duration(hours) * time.Hour))
+	this.scheduler.Reset(this.clock.Now())
+	return fmt.Sprintf("State loaded; file %s, time is %s", params[0], this.clock.Now().Format("15:00")), nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+)
+
+func (this *App) setTaxRate(params []string) (string, error) {
+	if len(params) != 3 {
+		return "", ErrInvalidParameters
+	}
+
+	rate, err := strconv.ParseFloat(params[2], 64)
+	if err != nil {
+		return "", ErrTaxRateMustBeFloat
+	}
+
+	result, err := this.taxService.SetRate(params[0], params[1], rate)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Tax rate set; region %s", params[0]) + rateInfo(result), nil
+}
+
+func (this *App) getRegionInfo(params []string) (string, error) {
+	if len(params) != 1 {
+		return "", ErrInvalidParameters
+	}
+
+	result, err := this.taxService.Get(params[0])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Region %s info", result.Code.Value()) + rateInfo(result), nil
+}
+
+func (this *App) setTaxClass(params []string) (string, error) {
+	if len(params) != 1 && len(params) != 2 {
+		return "", ErrInvalidParameters
+	}
+
+	var taxClass string
+	if len(params) == 2 {
+		taxClass = params[1]
+	}
+
+	result, err := this.productService.SetTaxClass(params[0], taxClass)
+	if err != nil {
+		return "", err
+	}
+
+	if taxClass == "" {
+		return fmt.Sprintf("Tax class cleared; product %s", result.Code.Value()), nil
+	}
+
+	return fmt.Sprintf("Tax class set; product %s, tax class %s", result.Code.Value(), taxClass), nil
+}
+
+// rateInfo lists the tax rates of region under its lock.
+func rateInfo(region *entity.Region) string {
+	region.Acquire()
+	defer region.Release()
+
+	rates := make([]string, 0, len(region.Rates))
+	for _, item := range region.Rates {
+		rates = append(rates, fmt.Sprintf("%s %.1f%%", item.Class.Value(), item.Percent))
+	}
+
+	return fmt.Sprintf("; rates %s", strings.Join(rates, ", "))
+}
+
+// taxClassInfo describes the tax class of product, or returns an empty string
+// for a product without one of its own.
+func taxClassInfo(product *entity.Product) string {
+	if product.TaxClass.Value() == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", tax class %s", product.TaxClass.Value())
+}
+
+// taxInfo breaks the total of order into net, tax and gross, or returns an
+// empty string for an untaxed order.
+func taxInfo(order *entity.Order) string {
+	if order.Region.Value() == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(", region %s, tax rate %.1f%%, net %.1f, tax %.1f, gross %.1f", order.Region.Value(), order.TaxRate, order.Total(), order.Tax, order.Gross())
+}
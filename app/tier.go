@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+)
+
+func (this *App) setPriceTiers(params []string) (string, error) {
+	if len(params) < 1 {
+		return "", ErrInvalidParameters
+	}
+
+	tiers := make(map[int]float64, len(params)-1)
+	for _, param := range params[1:] {
+		value, percentValue, ok := strings.Cut(param, ":")
+		if !ok {
+			return "", ErrInvalidTier
+		}
+
+		minQuantity, err := strconv.Atoi(value)
+		if err != nil {
+			return "", ErrInvalidTier
+		}
+
+		percent, err := strconv.ParseFloat(percentValue, 64)
+		if err != nil {
+			return "", ErrInvalidTier
+		}
+
+		if _, ok := tiers[minQuantity]; ok {
+			return "", ErrInvalidTier
+		}
+		tiers[minQuantity] = percent
+	}
+
+	result, err := this.productService.SetPriceTiers(params[0], tiers)
+	if err != nil {
+		return "", err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	if len(result.PriceTiers) == 0 {
+		return fmt.Sprintf("Price tiers cleared; product %s", result.Code.Value()), nil
+	}
+
+	return fmt.Sprintf("Price tiers set; product %s", result.Code.Value()) + tierInfo(result), nil
+}
+
+// tierInfo lists the volume tiers of product, or returns an empty string for
+// a product without them.
+func tierInfo(product *entity.Product) string {
+	if len(product.PriceTiers) == 0 {
+		return ""
+	}
+
+	tiers := make([]string, 0, len(product.PriceTiers))
+	for _, item := range product.PriceTiers {
+		tiers = append(tiers, fmt.Sprintf("%d:%.1f%%", item.MinQuantity, item.Percent))
+	}
+
+	return fmt.Sprintf(", price tiers %s", strings.Join(tiers, " "))
+}
+
+// orderTierInfo describes the volume tier taken off the unit price of order,
+// or returns an empty string when none was.
+func orderTierInfo(order *entity.Order) string {
+	if order.TierPercent == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(", list price %.1f, tier %.1f%%", order.ListPrice.Value(), order.TierPercent)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+func (this *App) createVariant(params []string) (string, error) {
+	args, flags, err := parseFlags(params, "price")
+	if err != nil || len(args) < 4 {
+		return "", ErrInvalidParameters
+	}
+
+	parent := args[0]
+	code := args[1]
+	stock, err := strconv.Atoi(args[2])
+	if err != nil {
+		return "", ErrStockMustBeInt
+	}
+
+	var price float64
+	if value, ok := flags["price"]; ok {
+		price, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", ErrPriceMustBeFloat
+		}
+
+		if price <= 0 {
+			return "", valueobject.ErrPriceMustBePositive
+		}
+	}
+
+	attributes := make(map[string]string, len(args)-3)
+	for _, param := range args[3:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok || name == "" {
+			return "", ErrInvalidAttribute
+		}
+
+		if _, ok := attributes[name]; ok {
+			return "", ErrInvalidAttribute
+		}
+
+		attributes[name] = value
+	}
+
+	result, err := this.productService.CreateVariant(parent, code, price, stock, attributes)
+	if err != nil {
+		return "", err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	return fmt.Sprintf("Variant created; code %s, price %.1f, stock %d", code, result.Price.Value(), result.Stock.Value()) + variantInfo(result), nil
+}
+
+// variantInfo lists the variants of a parent, or names the parent and the
+// attributes of a variant. It returns an empty string for other products.
+func variantInfo(product *entity.Product) string {
+	if product.IsParent() {
+		variants := make([]string, 0, len(product.Variants))
+		for _, code := range product.Variants {
+			variants = append(variants, code.Value())
+		}
+
+		return fmt.Sprintf(", variants %s", strings.Join(variants, " "))
+	}
+
+	if !product.IsVariant() {
+		return ""
+	}
+
+	attributes := make([]string, 0, len(product.Attributes))
+	for _, item := range product.Attributes {
+		attributes = append(attributes, item.Name+"="+item.Value)
+	}
+
+	return fmt.Sprintf(", parent %s, attributes %s", product.Parent.Value(), strings.Join(attributes, " "))
+}
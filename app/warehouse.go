@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+)
+
+func (this *App) createWarehouse(params []string) (string, error) {
+	if len(params) != 3 {
+		return "", ErrInvalidParameters
+	}
+
+	code := params[0]
+	x, err := strconv.ParseFloat(params[1], 64)
+	if err != nil {
+		return "", ErrCoordinateMustBeFloat
+	}
+	y, err := strconv.ParseFloat(params[2], 64)
+	if err != nil {
+		return "", ErrCoordinateMustBeFloat
+	}
+
+	err = this.warehouseService.Create(code, x, y)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Warehouse created; code %s, location %.1f,%.1f", code, x, y), nil
+}
+
+func (this *App) stockWarehouse(params []string) (string, error) {
+	if len(params) != 3 {
+		return "", ErrInvalidParameters
+	}
+
+	amount, err := strconv.Atoi(params[2])
+	if err != nil {
+		return "", ErrAmountMustBeInt
+	}
+
+	product, err := this.warehouseService.AssignStock(params[0], params[1], amount)
+	if err != nil {
+		return "", err
+	}
+
+	product.Acquire()
+	defer product.Release()
+
+	return fmt.Sprintf("Product %s stocked in %s; stock %d", product.Code.Value(), params[1], product.Stock.Value()) + inventoryInfo(product), nil
+}
+
+// parseLocation reads a customer location given as "x,y".
+func parseLocation(value string) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, ErrInvalidLocation
+	}
+
+	x, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, ErrInvalidLocation
+	}
+	y, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, 0, ErrInvalidLocation
+	}
+
+	return x, y, nil
+}
+
+// inventoryInfo describes how the stock of product is split between
+// warehouses, or returns an empty string when no warehouse holds any of it.
+func inventoryInfo(product *entity.Product) string {
+	if len(product.Inventory) == 0 {
+		return ""
+	}
+
+	stocks := make([]string, 0, len(product.Inventory))
+	for _, item := range product.Inventory {
+		stocks = append(stocks, fmt.Sprintf("%s=%d", item.Warehouse.Value(), item.Stock.Value()))
+	}
+
+	return fmt.Sprintf(", warehouses %s, unassigned %d", strings.Join(stocks, " "), product.Unassigned())
+}
+
+// allocationInfo describes the warehouses the units of order were taken from,
+// or returns an empty string when none were. Units of a bundle component are
+// prefixed with the component code.
+func allocationInfo(order *entity.Order) string {
+	if len(order.Allocations) == 0 {
+		return ""
+	}
+
+	allocations := make([]string, 0, len(order.Allocations))
+	for _, item := range order.Allocations {
+		allocation := fmt.Sprintf("%s=%d", item.Warehouse.Value(), item.Quantity)
+		if item.Product.Value() != "" {
+			allocation = item.Product.Value() + "@" + allocation
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	return fmt.Sprintf(", allocated %s", strings.Join(allocations, " "))
+}
@@ -7,30 +7,54 @@ import (
 	"os"
 	"strings"
 
-	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
-	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
-	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
-
 	"github.com/aaydin-tr/e-commerce/app"
-	"github.com/aaydin-tr/e-commerce/entity"
-	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
 	"github.com/aaydin-tr/e-commerce/service/campaign"
+	"github.com/aaydin-tr/e-commerce/service/category"
+	"github.com/aaydin-tr/e-commerce/service/coupon"
+	"github.com/aaydin-tr/e-commerce/service/ledger"
 	"github.com/aaydin-tr/e-commerce/service/order"
 	"github.com/aaydin-tr/e-commerce/service/product"
+	"github.com/aaydin-tr/e-commerce/service/state"
+	"github.com/aaydin-tr/e-commerce/service/tax"
+	"github.com/aaydin-tr/e-commerce/service/warehouse"
 )
 
 func main() {
 	scenarioFile := flag.String("file", "", "scenario file path")
+	storeSpec := flag.String("store", "memory", "repository store, 'memory' or 'sqlite:<path>'")
 	flag.Parse()
 
-	productRepository := productRepo.NewProductRepository(storage.New[*entity.Product]())
-	orderRepository := orderRepo.NewOrderRepository(storage.New[*entity.Order]())
-	campaignRepository := campaignRepo.NewCampaignRepository(storage.New[*entity.Campaign]())
-
-	productService := product.NewProductService(productRepository)
-	orderService := order.NewOrderService(orderRepository)
-	campaignService := campaign.NewCampaignService(campaignRepository)
-	app := app.NewApp(productService, orderService, campaignService)
+	unitOfWork := uow.New()
+	systemClock := clock.New(clock.Start)
+	store, err := openStore(*storeSpec, unitOfWork, systemClock)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		return
+	}
+	defer store.close()
+
+	productRepository := store.productRepository
+	orderRepository := store.orderRepository
+	campaignRepository := store.campaignRepository
+	warehouseRepository := store.warehouseRepository
+	ledgerRepository := store.ledgerRepository
+	categoryRepository := store.categoryRepository
+	couponRepository := store.couponRepository
+	regionRepository := store.regionRepository
+
+	productService := product.NewProductService(productRepository, ledgerRepository, unitOfWork, systemClock)
+	orderService := order.NewOrderService(orderRepository, productRepository, campaignRepository, warehouseRepository, ledgerRepository, couponRepository, categoryRepository, unitOfWork, systemClock)
+	campaignService := campaign.NewCampaignService(campaignRepository, productRepository, unitOfWork, systemClock)
+	stateService := state.NewStateService(productRepository, orderRepository, campaignRepository, warehouseRepository, ledgerRepository, categoryRepository, couponRepository, regionRepository, unitOfWork)
+	warehouseService := warehouse.NewWarehouseService(warehouseRepository, productRepository, unitOfWork)
+	ledgerService := ledger.NewLedgerService(ledgerRepository, productRepository)
+	categoryService := category.NewCategoryService(categoryRepository, productRepository, unitOfWork)
+	couponService := coupon.NewCouponService(couponRepository, productRepository, categoryRepository, systemClock)
+	taxService := tax.NewTaxService(regionRepository, unitOfWork)
+	scheduler := campaign.NewScheduler(campaignRepository, productRepository, systemClock.Now())
+	app := app.NewApp(productService, orderService, campaignService, warehouseService, ledgerService, categoryService, couponService, taxService, scheduler, stateService, systemClock)
 
 	if *scenarioFile == "" {
 		fmt.Println("Please enter command")
@@ -51,13 +75,7 @@ func main() {
 				return
 			}
 
-			msg, err := app.Run(args)
-			if err != nil {
-				fmt.Printf("Error: %s\n", err.Error())
-				continue
-			}
-
-			fmt.Println(msg)
+			execute(app, store, args)
 		}
 	}
 
@@ -76,13 +94,22 @@ func main() {
 			continue
 		}
 
-		msg, err := app.Run(args)
-		if err != nil {
-			fmt.Printf("Error: %s\n", err.Error())
-			continue
-		}
+		execute(app, store, args)
+	}
 
+}
+
+// execute runs a single command and persists its changes.
+func execute(app *app.App, store *store, args []string) {
+	msg, err := app.Run(args)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+	} else {
 		fmt.Println(msg)
 	}
 
+	err = store.flush()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+	}
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	campaignDomain "github.com/aaydin-tr/e-commerce/domain/campaign"
+	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	campaignSqlite "github.com/aaydin-tr/e-commerce/domain/campaign/sqlite"
+	categoryDomain "github.com/aaydin-tr/e-commerce/domain/category"
+	categoryRepo "github.com/aaydin-tr/e-commerce/domain/category/memory"
+	categorySqlite "github.com/aaydin-tr/e-commerce/domain/category/sqlite"
+	couponDomain "github.com/aaydin-tr/e-commerce/domain/coupon"
+	couponRepo "github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	couponSqlite "github.com/aaydin-tr/e-commerce/domain/coupon/sqlite"
+	ledgerDomain "github.com/aaydin-tr/e-commerce/domain/ledger"
+	ledgerRepo "github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	ledgerSqlite "github.com/aaydin-tr/e-commerce/domain/ledger/sqlite"
+	orderDomain "github.com/aaydin-tr/e-commerce/domain/order"
+	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
+	orderSqlite "github.com/aaydin-tr/e-commerce/domain/order/sqlite"
+	productDomain "github.com/aaydin-tr/e-commerce/domain/product"
+	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	productSqlite "github.com/aaydin-tr/e-commerce/domain/product/sqlite"
+	regionDomain "github.com/aaydin-tr/e-commerce/domain/region"
+	regionRepo "github.com/aaydin-tr/e-commerce/domain/region/memory"
+	regionSqlite "github.com/aaydin-tr/e-commerce/domain/region/sqlite"
+	warehouseDomain "github.com/aaydin-tr/e-commerce/domain/warehouse"
+	warehouseRepo "github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
+	warehouseSqlite "github.com/aaydin-tr/e-commerce/domain/warehouse/sqlite"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	clockSqlite "github.com/aaydin-tr/e-commerce/pkg/clock/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
+)
+
+var ErrInvalidStore = errors.New("Store must be 'memory' or 'sqlite:<path>'")
+
+type store struct {
+	productRepository   productDomain.ProductRepository
+	orderRepository     orderDomain.OrderRepository
+	campaignRepository  campaignDomain.CampaignRepository
+	warehouseRepository warehouseDomain.WarehouseRepository
+	ledgerRepository    ledgerDomain.LedgerRepository
+	categoryRepository  categoryDomain.CategoryRepository
+	couponRepository    couponDomain.CouponRepository
+	regionRepository    regionDomain.RegionRepository
+	// flush persists the changes made by the last command.
+	flush func() error
+	close func() error
+}
+
+// openStore builds the repositories described by spec, either "memory" or
+// "sqlite:<path>". A sqlite store also keeps the time of systemClock.
+func openStore(spec string, unitOfWork types.UnitOfWork, systemClock *clock.Clock) (*store, error) {
+	if spec == "memory" {
+		return &store{
+			productRepository:   productRepo.NewProductRepository(uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]())),
+			orderRepository:     orderRepo.NewOrderRepository(uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]())),
+			campaignRepository:  campaignRepo.NewCampaignRepository(uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]())),
+			warehouseRepository: warehouseRepo.NewWarehouseRepository(uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]())),
+			ledgerRepository:    ledgerRepo.NewLedgerRepository(uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]())),
+			categoryRepository:  categoryRepo.NewCategoryRepository(uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]())),
+			couponRepository:    couponRepo.NewCouponRepository(uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]())),
+			regionRepository:    regionRepo.NewRegionRepository(uow.NewStorage[*entity.Region](unitOfWork, storage.New[*entity.Region]())),
+			flush:               func() error { return nil },
+			close:               func() error { return nil },
+		}, nil
+	}
+
+	path, ok := strings.CutPrefix(spec, "sqlite:")
+	if !ok || path == "" {
+		return nil, ErrInvalidStore
+	}
+
+	db, err := sqlite.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	productRepository, err := productSqlite.NewProductRepository(db, uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	orderRepository, err := orderSqlite.NewOrderRepository(db, uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	campaignRepository, err := campaignSqlite.NewCampaignRepository(db, uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]()), productRepository)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	warehouseRepository, err := warehouseSqlite.NewWarehouseRepository(db, uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	ledgerRepository, err := ledgerSqlite.NewLedgerRepository(db, uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	categoryRepository, err := categorySqlite.NewCategoryRepository(db, uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	couponRepository, err := couponSqlite.NewCouponRepository(db, uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	regionRepository, err := regionSqlite.NewRegionRepository(db, uow.NewStorage[*entity.Region](unitOfWork, storage.New[*entity.Region]()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	clockStore, err := clockSqlite.NewClockStore(db, systemClock)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &store{
+		productRepository:   productRepository,
+		orderRepository:     orderRepository,
+		campaignRepository:  campaignRepository,
+		warehouseRepository: warehouseRepository,
+		ledgerRepository:    ledgerRepository,
+		categoryRepository:  categoryRepository,
+		couponRepository:    couponRepository,
+		regionRepository:    regionRepository,
+		flush: func() error {
+			return unitOfWork.Do(func() error {
+				return sqlite.Flush(db, warehouseRepository, categoryRepository, productRepository, couponRepository, regionRepository, campaignRepository, orderRepository, ledgerRepository, clockStore)
+			})
+		},
+		close: db.Close,
+	}, nil
+}
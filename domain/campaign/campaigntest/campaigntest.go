@@ -0,0 +1,145 @@
+// Package campaigntest provides the contract every campaign.CampaignRepository
+// implementation must satisfy.
+package campaigntest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/campaign"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) campaign.CampaignRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("Exist", func(t *testing.T) { testExist(t, newRepository(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func newCampaign(name string) *entity.Campaign {
+	campaignName, _ := valueobject.NewName(name)
+	return &entity.Campaign{Name: campaignName}
+}
+
+func testCreate(t *testing.T, repository campaign.CampaignRepository) {
+	stored := newCampaign("C1")
+
+	t.Run("Create campaign", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create campaign which already exist", func(t *testing.T) {
+		err := repository.Create(newCampaign("C1"))
+		assert.ErrorIs(t, err, campaign.ErrCampaignAlreadyExist)
+
+		result, err := repository.Get(stored.Name)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func testGet(t *testing.T, repository campaign.CampaignRepository) {
+	stored := newCampaign("C1")
+	repository.Create(stored)
+
+	t.Run("Get campaign", func(t *testing.T) {
+		name, _ := valueobject.NewName("C1")
+
+		result, err := repository.Get(name)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get campaign which not exist", func(t *testing.T) {
+		name, _ := valueobject.NewName("C2")
+
+		result, err := repository.Get(name)
+		assert.ErrorIs(t, err, campaign.ErrCampaignNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetAll(t *testing.T, repository campaign.CampaignRepository) {
+	t.Run("Get all campaigns of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all campaigns ordered by name", func(t *testing.T) {
+		for _, name := range []string{"C3", "C1", "C2"} {
+			repository.Create(newCampaign(name))
+		}
+
+		var names []string
+		for _, item := range repository.GetAll() {
+			names = append(names, item.Name.Value())
+		}
+		assert.Equal(t, []string{"C1", "C2", "C3"}, names)
+	})
+}
+
+func testExist(t *testing.T, repository campaign.CampaignRepository) {
+	repository.Create(newCampaign("C1"))
+
+	t.Run("Campaign exist", func(t *testing.T) {
+		name, _ := valueobject.NewName("C1")
+		assert.True(t, repository.Exist(name))
+	})
+
+	t.Run("Campaign not exist", func(t *testing.T) {
+		name, _ := valueobject.NewName("C2")
+		assert.False(t, repository.Exist(name))
+	})
+}
+
+func testUpdate(t *testing.T, repository campaign.CampaignRepository) {
+	stored := newCampaign("C1")
+	repository.Create(stored)
+
+	t.Run("Update campaign", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update campaign with stale version", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+
+		var conflictErr *types.VersionConflictError
+		assert.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "campaign", conflictErr.Aggregate)
+		assert.Equal(t, "C1", conflictErr.Key)
+		assert.Equal(t, 0, conflictErr.Expected)
+		assert.Equal(t, 1, conflictErr.Actual)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update campaign which not exist", func(t *testing.T) {
+		err := repository.Update(newCampaign("C2"), 0)
+		assert.ErrorIs(t, err, campaign.ErrCampaignNotFound)
+	})
+}
+
+func testClear(t *testing.T, repository campaign.CampaignRepository) {
+	repository.Create(newCampaign("C1"))
+	repository.Create(newCampaign("C2"))
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	name, _ := valueobject.NewName("C1")
+	assert.False(t, repository.Exist(name))
+
+	t.Run("Create campaign after clear", func(t *testing.T) {
+		err := repository.Create(newCampaign("C1"))
+		assert.NoError(t, err)
+	})
+}
@@ -1,6 +1,9 @@
 package memory
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/aaydin-tr/e-commerce/domain/campaign"
 	"github.com/aaydin-tr/e-commerce/entity"
 	"github.com/aaydin-tr/e-commerce/types"
@@ -9,6 +12,7 @@ import (
 
 type CampaignRepository struct {
 	storage types.Storage[*entity.Campaign]
+	mu      sync.Mutex
 }
 
 func NewCampaignRepository(storage types.Storage[*entity.Campaign]) *CampaignRepository {
@@ -16,6 +20,8 @@ func NewCampaignRepository(storage types.Storage[*entity.Campaign]) *CampaignRep
 }
 
 func (r *CampaignRepository) Create(newCampaign *entity.Campaign) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	_, ok := r.storage.Get(newCampaign.Name.Value())
 	if ok {
 		return campaign.ErrCampaignAlreadyExist
@@ -45,5 +51,34 @@ func (r *CampaignRepository) GetAll() []*entity.Campaign {
 		result = append(result, item)
 	}
 
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name.Value() < result[j].Name.Value()
+	})
+
 	return result
 }
+
+func (r *CampaignRepository) Update(updatedCampaign *entity.Campaign, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.storage.Get(updatedCampaign.Name.Value())
+	if !ok {
+		return campaign.ErrCampaignNotFound
+	}
+
+	if current.Version != version {
+		return &types.VersionConflictError{Aggregate: "campaign", Key: updatedCampaign.Name.Value(), Expected: version, Actual: current.Version}
+	}
+
+	updatedCampaign.Version = version + 1
+	r.storage.Set(updatedCampaign.Name.Value(), updatedCampaign)
+	return nil
+}
+
+func (r *CampaignRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
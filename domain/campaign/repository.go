@@ -16,6 +16,11 @@ var (
 type CampaignRepository interface {
 	Create(campaign *entity.Campaign) error
 	Get(name valueobject.Name) (*entity.Campaign, error)
+	// GetAll returns every campaign ordered by name.
 	GetAll() []*entity.Campaign
+	Clear()
 	Exist(name valueobject.Name) bool
+	// Update stores campaign if the stored version still equals version and
+	// increments it, otherwise it returns a *types.VersionConflictError.
+	Update(campaign *entity.Campaign, version int) error
 }
@@ -0,0 +1,429 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownProduct  = errors.New("Campaign refers to an unknown product")
+	ErrUnknownCampaign = errors.New("Product refers to an unknown campaign")
+)
+
+var migrations = []string{
+	`CREATE TABLE campaigns (
+		id TEXT NOT NULL,
+		name TEXT PRIMARY KEY,
+		product TEXT,
+		duration INTEGER NOT NULL,
+		price_manipulation_limit INTEGER NOT NULL,
+		target_sales_count INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		total_sales INTEGER NOT NULL,
+		average_item_price REAL NOT NULL,
+		version INTEGER NOT NULL
+	)`,
+	`ALTER TABLE campaigns ADD COLUMN amendments TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE campaigns ADD COLUMN end_reason TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE campaigns ADD COLUMN ended_at INTEGER`,
+	`ALTER TABLE campaigns ADD COLUMN final_price REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE campaigns ADD COLUMN stock_policy TEXT NOT NULL DEFAULT 'End'`,
+	`ALTER TABLE campaigns ADD COLUMN backorder_policy TEXT NOT NULL DEFAULT 'Order'`,
+	`ALTER TABLE campaigns ADD COLUMN category TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE campaigns ADD COLUMN members TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE campaigns ADD COLUMN tax REAL NOT NULL DEFAULT 0`,
+}
+
+// CampaignRepository keeps campaigns in memory, so every Get returns the same
+// entity, and writes them to the database on Flush. Loading links campaigns
+// and products in both directions through productRepository, which must be
+// loaded from the same database.
+type CampaignRepository struct {
+	*memory.CampaignRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Campaign]
+	persisted map[string]row
+}
+
+type row struct {
+	ID                     string
+	Name                   string
+	Product                sql.NullString
+	Duration               int
+	PriceManipulationLimit int
+	TargetSalesCount       int
+	Status                 string
+	TotalSales             int
+	AverageItemPrice       float64
+	Tax                    float64
+	StockPolicy            string
+	BackorderPolicy        string
+	// EndReason is empty and EndedAt NULL while the campaign is active or
+	// when it ended before endings were recorded. EndedAt is in Unix seconds.
+	EndReason  string
+	EndedAt    sql.NullInt64
+	FinalPrice float64
+	// Amendments holds the amendment log and Members the member codes of a
+	// category campaign as JSON, so rows stay comparable.
+	Amendments string
+	Category   string
+	Members    string
+	Version    int
+}
+
+// amendment keeps the amendment time in Unix seconds, since the simulation
+// clock starts before the years JSON can encode.
+type amendment struct {
+	Time                   int64 `json:"time"`
+	DurationExtension      int   `json:"duration_extension,omitempty"`
+	PriceManipulationLimit int   `json:"price_manipulation_limit,omitempty"`
+	TargetSalesCount       int   `json:"target_sales_count,omitempty"`
+}
+
+func NewCampaignRepository(db *sql.DB, storage types.Storage[*entity.Campaign], productRepository product.ProductRepository) (*CampaignRepository, error) {
+	err := database.Migrate(db, "campaigns", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CampaignRepository{
+		CampaignRepository: memory.NewCampaignRepository(storage),
+		db:                 db,
+		storage:            storage,
+		persisted:          make(map[string]row),
+	}
+
+	err = r.load(productRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CampaignRepository) load(productRepository product.ProductRepository) error {
+	rows, err := r.db.Query(`SELECT id, name, product, duration, price_manipulation_limit, target_sales_count, status, total_sales, average_item_price, tax, stock_policy, backorder_policy, end_reason, ended_at, final_price, amendments, category, members, version FROM campaigns`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Name, &item.Product, &item.Duration, &item.PriceManipulationLimit, &item.TargetSalesCount, &item.Status, &item.TotalSales, &item.AverageItemPrice, &item.Tax, &item.StockPolicy, &item.BackorderPolicy, &item.EndReason, &item.EndedAt, &item.FinalPrice, &item.Amendments, &item.Category, &item.Members, &item.Version)
+		if err != nil {
+			return err
+		}
+
+		campaign, err := item.toCampaign()
+		if err != nil {
+			return err
+		}
+
+		if item.Product.Valid {
+			campaign.Product, err = findProduct(productRepository, item.Product.String)
+			if err != nil {
+				return err
+			}
+		}
+
+		var members []string
+		err = json.Unmarshal([]byte(item.Members), &members)
+		if err != nil {
+			return err
+		}
+
+		for _, code := range members {
+			member, err := findProduct(productRepository, code)
+			if err != nil {
+				return err
+			}
+			campaign.Members = append(campaign.Members, member)
+		}
+
+		r.storage.Set(item.Name, campaign)
+		r.persisted[item.Name] = item
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range productRepository.GetAll() {
+		err = r.linkProduct(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// linkProduct points item at the campaign stored for it in the products table.
+func (r *CampaignRepository) linkProduct(item *entity.Product) error {
+	var name sql.NullString
+	err := r.db.QueryRow(`SELECT campaign FROM products WHERE code = ?`, item.Code.Value()).Scan(&name)
+	if err == sql.ErrNoRows || (err == nil && !name.Valid) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	campaign, ok := r.storage.Get(name.String)
+	if !ok {
+		return ErrUnknownCampaign
+	}
+
+	item.Campaign = campaign
+	return nil
+}
+
+func findProduct(productRepository product.ProductRepository, productCode string) (*entity.Product, error) {
+	code, err := valueobject.NewCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := productRepository.Get(code)
+	if errors.Is(err, product.ErrNotFound) {
+		return nil, ErrUnknownProduct
+	}
+
+	return result, err
+}
+
+func (r *CampaignRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, campaign := range r.storage.Values() {
+		campaign.Acquire()
+		item, err := newRow(campaign)
+		campaign.Release()
+		if err != nil {
+			return nil, err
+		}
+		current[campaign.Name.Value()] = item
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO campaigns (id, name, product, duration, price_manipulation_limit, target_sales_count, status, total_sales, average_item_price, tax, stock_policy, backorder_policy, end_reason, ended_at, final_price, amendments, category, members, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET id = excluded.id, product = excluded.product, duration = excluded.duration, price_manipulation_limit = excluded.price_manipulation_limit,
+			target_sales_count = excluded.target_sales_count, status = excluded.status, total_sales = excluded.total_sales, average_item_price = excluded.average_item_price, tax = excluded.tax, stock_policy = excluded.stock_policy, backorder_policy = excluded.backorder_policy,
+			end_reason = excluded.end_reason, ended_at = excluded.ended_at, final_price = excluded.final_price, amendments = excluded.amendments, category = excluded.category, members = excluded.members, version = excluded.version`,
+			item.ID, item.Name, item.Product, item.Duration, item.PriceManipulationLimit, item.TargetSalesCount, item.Status, item.TotalSales, item.AverageItemPrice, item.Tax, item.StockPolicy, item.BackorderPolicy, item.EndReason, item.EndedAt, item.FinalPrice, item.Amendments, item.Category, item.Members, item.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range deletes {
+		_, err := tx.Exec(`DELETE FROM campaigns WHERE name = ?`, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(campaign *entity.Campaign) (row, error) {
+	amendments := make([]amendment, 0, len(campaign.Amendments))
+	for _, item := range campaign.Amendments {
+		amendments = append(amendments, amendment{
+			Time:                   item.Time.Unix(),
+			DurationExtension:      item.DurationExtension,
+			PriceManipulationLimit: item.PriceManipulationLimit,
+			TargetSalesCount:       item.TargetSalesCount,
+		})
+	}
+
+	encoded, err := json.Marshal(amendments)
+	if err != nil {
+		return row{}, err
+	}
+
+	members := make([]string, 0, len(campaign.Members))
+	for _, member := range campaign.Members {
+		members = append(members, member.Code.Value())
+	}
+
+	encodedMembers, err := json.Marshal(members)
+	if err != nil {
+		return row{}, err
+	}
+
+	item := row{
+		ID:                     campaign.ID.String(),
+		Name:                   campaign.Name.Value(),
+		Duration:               campaign.Duration.Value(),
+		PriceManipulationLimit: campaign.PriceManipulationLimit.Value(),
+		TargetSalesCount:       campaign.TargetSalesCount.Value(),
+		Status:                 campaign.Status.Value(),
+		TotalSales:             campaign.TotalSales.Value(),
+		AverageItemPrice:       campaign.AverageItemPrice.Value(),
+		Tax:                    campaign.Tax,
+		StockPolicy:            campaign.StockPolicy.Value(),
+		BackorderPolicy:        campaign.BackorderPolicy.Value(),
+		EndReason:              campaign.EndReason.Value(),
+		FinalPrice:             campaign.FinalPrice.Value(),
+		Amendments:             string(encoded),
+		Category:               campaign.Category.Value(),
+		Members:                string(encodedMembers),
+		Version:                campaign.Version,
+	}
+
+	if campaign.EndReason.Value() != "" {
+		item.EndedAt = sql.NullInt64{Int64: campaign.EndedAt.Unix(), Valid: true}
+	}
+
+	if campaign.Product != nil {
+		item.Product = sql.NullString{String: campaign.Product.Code.Value(), Valid: true}
+	}
+
+	return item, nil
+}
+
+// toCampaign rebuilds a campaign without its product and members, which are
+// linked once the row is read.
+func (item row) toCampaign() (*entity.Campaign, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := valueobject.NewName(item.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := valueobject.NewDuration(item.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	priceManipulationLimit, err := valueobject.NewPriceManipulationLimit(item.PriceManipulationLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	targetSalesCount, err := valueobject.NewTargetSalesCount(item.TargetSalesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := valueobject.NewStatus(item.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	// A campaign without sales holds zero values, which the value object
+	// constructors reject.
+	var totalSales valueobject.Quantity
+	if item.TotalSales != 0 {
+		totalSales, err = valueobject.NewQuantity(item.TotalSales)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var averageItemPrice valueobject.Price
+	if item.AverageItemPrice != 0 {
+		averageItemPrice, err = valueobject.NewPrice(item.AverageItemPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var stockPolicy valueobject.StockPolicy
+	if item.StockPolicy != "" {
+		stockPolicy, err = valueobject.NewStockPolicy(item.StockPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var backorderPolicy valueobject.BackorderPolicy
+	if item.BackorderPolicy != "" {
+		backorderPolicy, err = valueobject.NewBackorderPolicy(item.BackorderPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var endReason valueobject.EndReason
+	if item.EndReason != "" {
+		endReason, err = valueobject.NewEndReason(item.EndReason)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var endedAt time.Time
+	if item.EndedAt.Valid {
+		endedAt = time.Unix(item.EndedAt.Int64, 0).UTC()
+	}
+
+	var finalPrice valueobject.Price
+	if item.FinalPrice != 0 {
+		finalPrice, err = valueobject.NewPrice(item.FinalPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var category valueobject.Code
+	if item.Category != "" {
+		category, err = valueobject.NewCode(item.Category)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var amendments []amendment
+	err = json.Unmarshal([]byte(item.Amendments), &amendments)
+	if err != nil {
+		return nil, err
+	}
+
+	var campaignAmendments []entity.CampaignAmendment
+	for _, amendment := range amendments {
+		campaignAmendments = append(campaignAmendments, entity.CampaignAmendment{
+			Time:                   time.Unix(amendment.Time, 0).UTC(),
+			DurationExtension:      amendment.DurationExtension,
+			PriceManipulationLimit: amendment.PriceManipulationLimit,
+			TargetSalesCount:       amendment.TargetSalesCount,
+		})
+	}
+
+	return &entity.Campaign{
+		ID:                     id,
+		Name:                   name,
+		Category:               category,
+		Duration:               duration,
+		PriceManipulationLimit: priceManipulationLimit,
+		TargetSalesCount:       targetSalesCount,
+		Status:                 status,
+		TotalSales:             totalSales,
+		AverageItemPrice:       averageItemPrice,
+		Tax:                    item.Tax,
+		StockPolicy:            stockPolicy,
+		BackorderPolicy:        backorderPolicy,
+		EndReason:              endReason,
+		EndedAt:                endedAt,
+		FinalPrice:             finalPrice,
+		Amendments:             campaignAmendments,
+		Version:                item.Version,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/campaign"
+	"github.com/aaydin-tr/e-commerce/domain/campaign/campaigntest"
+	productSqlite "github.com/aaydin-tr/e-commerce/domain/product/sqlite"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t *testing.T, db *sql.DB) (*productSqlite.ProductRepository, *CampaignRepository) {
+	productRepository, err := productSqlite.NewProductRepository(db, storage.New[*entity.Product]())
+	require.NoError(t, err)
+
+	campaignRepository, err := NewCampaignRepository(db, storage.New[*entity.Campaign](), productRepository)
+	require.NoError(t, err)
+
+	return productRepository, campaignRepository
+}
+
+func newProduct(code string) *entity.Product {
+	productCode, _ := valueobject.NewCode(code)
+	price, _ := valueobject.NewPrice(100)
+	stock, _ := valueobject.NewStock(50)
+	return &entity.Product{ID: uuid.New(), Code: productCode, Price: price, Stock: stock, InititalStock: stock, InititalPrice: price}
+}
+
+func newCampaign(name string, product *entity.Product) *entity.Campaign {
+	campaignName, _ := valueobject.NewName(name)
+	duration, _ := valueobject.NewDuration(5)
+	limit, _ := valueobject.NewPriceManipulationLimit(20)
+	target, _ := valueobject.NewTargetSalesCount(10)
+	status, _ := valueobject.NewStatus(valueobject.Active)
+	return &entity.Campaign{ID: uuid.New(), Name: campaignName, Product: product, Duration: duration, PriceManipulationLimit: limit, TargetSalesCount: target, Status: status}
+}
+
+func TestSqliteCampaignRepository(t *testing.T) {
+	campaigntest.RunRepositorySuite(t, func(t *testing.T) campaign.CampaignRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		_, repository := open(t, db)
+		return repository
+	})
+}
+
+func TestCampaignRepositoryFlush(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	productRepository, campaignRepository := open(t, db)
+
+	p1 := newProduct("P1")
+	p2 := newProduct("P2")
+	p2.BackorderLimit, _ = valueobject.NewStock(5)
+	p2.Backordered, _ = valueobject.NewStock(3)
+	p2.BackorderQueue = []uuid.UUID{uuid.New(), uuid.New()}
+	p2.LaunchAt = time.Date(0, 0, 0, 4, 0, 0, 0, time.UTC)
+	w1, _ := valueobject.NewCode("W1")
+	p2.AssignStock(w1, 2)
+	p3 := newProduct("P3")
+	p3.Components = []entity.BundleComponent{{Product: p1.Code, Quantity: 1}, {Product: p2.Code, Quantity: 2}}
+	productRepository.Create(p1)
+	productRepository.Create(p2)
+	p4 := newProduct("P4")
+	p5 := newProduct("P5")
+	p4.Variants = []valueobject.Code{p5.Code}
+	p5.Parent = p4.Code
+	p5.Attributes = []entity.VariantAttribute{{Name: "colour", Value: "red"}, {Name: "size", Value: "S"}}
+	p5.PriceOverride = true
+	productRepository.Create(p3)
+	productRepository.Create(p4)
+	productRepository.Create(p5)
+
+	c1 := newCampaign("C1", p1)
+	c1.StockPolicy, _ = valueobject.NewStockPolicy(valueobject.KeepOnStockOut)
+	c1.BackorderPolicy, _ = valueobject.NewBackorderPolicy(valueobject.CountOnFulfillment)
+	campaignRepository.Create(c1)
+	p1.Campaign = c1
+
+	ended := newCampaign("C2", p2)
+	ended.Status, _ = valueobject.NewStatus(valueobject.Ended)
+	ended.TotalSales, _ = valueobject.NewQuantity(4)
+	ended.AverageItemPrice, _ = valueobject.NewPrice(90)
+	ended.Tax = 72
+	ended.Amendments = []entity.CampaignAmendment{{Time: time.Date(0, 0, 0, 3, 0, 0, 0, time.UTC), DurationExtension: 2, TargetSalesCount: 8}}
+	campaignRepository.Create(ended)
+
+	category := newCampaign("C3", nil)
+	category.Category, _ = valueobject.NewCode("K1")
+	category.Members = []*entity.Product{p4, p5}
+	campaignRepository.Create(category)
+	p4.Category = category.Category
+	p4.Campaign = category
+	p5.Campaign = category
+
+	err = database.Flush(db, productRepository, campaignRepository)
+	assert.NoError(t, err)
+
+	t.Run("reload links products and campaigns", func(t *testing.T) {
+		productRepository, campaignRepository := open(t, db)
+
+		loaded, err := productRepository.Get(p1.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, p1.ID, loaded.ID)
+		assert.Equal(t, p1.Price, loaded.Price)
+		assert.Equal(t, p1.Stock, loaded.Stock)
+
+		loadedCampaign, err := campaignRepository.Get(c1.Name)
+		assert.NoError(t, err)
+		assert.Same(t, loadedCampaign, loaded.Campaign)
+		assert.Same(t, loaded, loadedCampaign.Product)
+		assert.Equal(t, c1.ID, loadedCampaign.ID)
+		assert.Equal(t, c1.Duration, loadedCampaign.Duration)
+		assert.Equal(t, c1.StockPolicy, loadedCampaign.StockPolicy)
+		assert.Equal(t, c1.BackorderPolicy, loadedCampaign.BackorderPolicy)
+		assert.Nil(t, loaded.BackorderQueue)
+		assert.True(t, loaded.LaunchAt.IsZero())
+		assert.Nil(t, loaded.Inventory)
+
+		loadedP2, _ := productRepository.Get(p2.Code)
+		assert.Nil(t, loadedP2.Campaign)
+		assert.Equal(t, p2.BackorderLimit, loadedP2.BackorderLimit)
+		assert.Equal(t, p2.Backordered, loadedP2.Backordered)
+		assert.Equal(t, p2.BackorderQueue, loadedP2.BackorderQueue)
+		assert.Equal(t, p2.LaunchAt, loadedP2.LaunchAt)
+		assert.Equal(t, p2.Inventory, loadedP2.Inventory)
+		assert.Nil(t, loadedP2.Components)
+
+		loadedP3, _ := productRepository.Get(p3.Code)
+		assert.Equal(t, p3.Components, loadedP3.Components)
+		assert.Nil(t, loadedP3.Variants)
+		assert.Equal(t, "", loadedP3.Parent.Value())
+
+		loadedP4, _ := productRepository.Get(p4.Code)
+		assert.Equal(t, p4.Variants, loadedP4.Variants)
+		assert.False(t, loadedP4.PriceOverride)
+
+		loadedP5, _ := productRepository.Get(p5.Code)
+		assert.Equal(t, p5.Parent, loadedP5.Parent)
+		assert.Equal(t, p5.Attributes, loadedP5.Attributes)
+		assert.True(t, loadedP5.PriceOverride)
+		assert.Equal(t, "", loadedP5.Category.Value())
+		assert.Equal(t, p4.Category, loadedP4.Category)
+
+		loadedCategory, err := campaignRepository.Get(category.Name)
+		assert.NoError(t, err)
+		assert.Nil(t, loadedCategory.Product)
+		assert.Equal(t, category.Category, loadedCategory.Category)
+		assert.Equal(t, []*entity.Product{loadedP4, loadedP5}, loadedCategory.Members)
+		assert.Same(t, loadedP4, loadedCategory.Members[0])
+		assert.Same(t, loadedCategory, loadedP5.Campaign)
+		assert.Nil(t, loadedCampaign.Members)
+
+		loadedEnded, err := campaignRepository.Get(ended.Name)
+		assert.NoError(t, err)
+		assert.Same(t, loadedP2, loadedEnded.Product)
+		assert.Equal(t, ended.Status, loadedEnded.Status)
+		assert.Equal(t, 4, loadedEnded.TotalSales.Value())
+		assert.Equal(t, 90.0, loadedEnded.AverageItemPrice.Value())
+		assert.Equal(t, 72.0, loadedEnded.Tax)
+		assert.Equal(t, ended.Amendments, loadedEnded.Amendments)
+		assert.Nil(t, loadedCampaign.Amendments)
+	})
+
+	t.Run("changes and deletes are written", func(t *testing.T) {
+		c1.End(valueobject.Expired, time.Date(0, 0, 0, 6, 0, 0, 0, time.UTC))
+		p1.RemoveCampaign()
+		campaignRepository.Update(c1, c1.Version)
+		productRepository.Update(p1, p1.Version)
+
+		campaignRepository.Clear()
+		campaignRepository.Create(c1)
+		campaignRepository.Create(category)
+
+		err := database.Flush(db, productRepository, campaignRepository)
+		assert.NoError(t, err)
+
+		productRepository, campaignRepository := open(t, db)
+		assert.Len(t, campaignRepository.GetAll(), 2)
+
+		loaded, _ := productRepository.Get(p1.Code)
+		assert.Nil(t, loaded.Campaign)
+		assert.Equal(t, 1, loaded.Version)
+
+		loadedCampaign, _ := campaignRepository.Get(c1.Name)
+		assert.False(t, loadedCampaign.IsActive())
+		assert.Equal(t, c1.EndReason, loadedCampaign.EndReason)
+		assert.Equal(t, c1.EndedAt, loadedCampaign.EndedAt)
+		assert.Equal(t, c1.FinalPrice, loadedCampaign.FinalPrice)
+	})
+}
+
+func TestNewCampaignRepositoryUnknownProduct(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	productRepository, campaignRepository := open(t, db)
+	campaignRepository.Create(newCampaign("C1", newProduct("P1")))
+	err = database.Flush(db, productRepository, campaignRepository)
+	require.NoError(t, err)
+
+	productRepository, err = productSqlite.NewProductRepository(db, storage.New[*entity.Product]())
+	require.NoError(t, err)
+
+	_, err = NewCampaignRepository(db, storage.New[*entity.Campaign](), productRepository)
+	assert.ErrorIs(t, err, ErrUnknownProduct)
+}
@@ -0,0 +1,101 @@
+// Package categorytest provides the contract every
+// category.CategoryRepository implementation must satisfy.
+package categorytest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) category.CategoryRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func newCategory(code string) *entity.Category {
+	categoryCode, _ := valueobject.NewCode(code)
+	return &entity.Category{ID: uuid.New(), Code: categoryCode}
+}
+
+func testCreate(t *testing.T, repository category.CategoryRepository) {
+	stored := newCategory("C1")
+
+	t.Run("Create category", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create category which already exist", func(t *testing.T) {
+		err := repository.Create(newCategory("C1"))
+		assert.ErrorIs(t, err, category.ErrAlreadyExist)
+
+		result, err := repository.Get(stored.Code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func testGet(t *testing.T, repository category.CategoryRepository) {
+	stored := newCategory("C1")
+	repository.Create(stored)
+
+	t.Run("Get category", func(t *testing.T) {
+		code, _ := valueobject.NewCode("C1")
+
+		result, err := repository.Get(code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get category which not exist", func(t *testing.T) {
+		code, _ := valueobject.NewCode("C2")
+
+		result, err := repository.Get(code)
+		assert.ErrorIs(t, err, category.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetAll(t *testing.T, repository category.CategoryRepository) {
+	t.Run("Get all categories of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all categories ordered by code", func(t *testing.T) {
+		for _, code := range []string{"C3", "C1", "C2"} {
+			repository.Create(newCategory(code))
+		}
+
+		var codes []string
+		for _, item := range repository.GetAll() {
+			codes = append(codes, item.Code.Value())
+		}
+		assert.Equal(t, []string{"C1", "C2", "C3"}, codes)
+	})
+}
+
+func testClear(t *testing.T, repository category.CategoryRepository) {
+	repository.Create(newCategory("C1"))
+	repository.Create(newCategory("C2"))
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	code, _ := valueobject.NewCode("C1")
+	_, err := repository.Get(code)
+	assert.ErrorIs(t, err, category.ErrNotFound)
+
+	t.Run("Create category after clear", func(t *testing.T) {
+		err := repository.Create(newCategory("C1"))
+		assert.NoError(t, err)
+	})
+}
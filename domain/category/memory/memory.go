@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+type CategoryRepository struct {
+	storage types.Storage[*entity.Category]
+	mu      sync.Mutex
+}
+
+func NewCategoryRepository(storage types.Storage[*entity.Category]) *CategoryRepository {
+	return &CategoryRepository{storage: storage}
+}
+
+func (r *CategoryRepository) Create(newCategory *entity.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.storage.Get(newCategory.Code.Value())
+	if ok {
+		return category.ErrAlreadyExist
+	}
+
+	r.storage.Set(newCategory.Code.Value(), newCategory)
+	return nil
+}
+
+func (r *CategoryRepository) Get(code valueobject.Code) (*entity.Category, error) {
+	result, ok := r.storage.Get(code.Value())
+	if !ok {
+		return nil, category.ErrNotFound
+	}
+
+	return result, nil
+}
+
+func (r *CategoryRepository) GetAll() []*entity.Category {
+	var result []*entity.Category
+	for _, item := range r.storage.Values() {
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code.Value() < result[j].Code.Value()
+	})
+
+	return result
+}
+
+func (r *CategoryRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
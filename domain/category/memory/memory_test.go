@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/category/categorytest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+)
+
+func TestMemoryCategoryRepository(t *testing.T) {
+	categorytest.RunRepositorySuite(t, func(t *testing.T) category.CategoryRepository {
+		return NewCategoryRepository(storage.New[*entity.Category]())
+	})
+}
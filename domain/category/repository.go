@@ -0,0 +1,22 @@
+package category
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+var (
+	ErrNotFound     = errors.New("Category not found")
+	ErrAlreadyExist = errors.New("Category already exist")
+)
+
+//go:generate mockgen -destination=../../mock/repository/category/category.go -package=repository github.com/aaydin-tr/e-commerce/domain/category CategoryRepository
+type CategoryRepository interface {
+	Create(category *entity.Category) error
+	Get(code valueobject.Code) (*entity.Category, error)
+	// GetAll returns every category ordered by code.
+	GetAll() []*entity.Category
+	Clear()
+}
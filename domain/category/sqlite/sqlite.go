@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/aaydin-tr/e-commerce/domain/category/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE categories (
+		id TEXT NOT NULL,
+		code TEXT PRIMARY KEY,
+		parent TEXT NOT NULL,
+		version INTEGER NOT NULL
+	)`,
+}
+
+// CategoryRepository keeps categories in memory and writes them to the
+// database on Flush.
+type CategoryRepository struct {
+	*memory.CategoryRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Category]
+	persisted map[string]row
+}
+
+type row struct {
+	ID      string
+	Code    string
+	Parent  string
+	Version int
+}
+
+func NewCategoryRepository(db *sql.DB, storage types.Storage[*entity.Category]) (*CategoryRepository, error) {
+	err := database.Migrate(db, "categories", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CategoryRepository{
+		CategoryRepository: memory.NewCategoryRepository(storage),
+		db:                 db,
+		storage:            storage,
+		persisted:          make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CategoryRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, code, parent, version FROM categories`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Code, &item.Parent, &item.Version)
+		if err != nil {
+			return err
+		}
+
+		category, err := item.toCategory()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.Code, category)
+		r.persisted[item.Code] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *CategoryRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, category := range r.storage.Values() {
+		current[category.Code.Value()] = newRow(category)
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO categories (id, code, parent, version) VALUES (?, ?, ?, ?)
+			ON CONFLICT (code) DO UPDATE SET id = excluded.id, parent = excluded.parent, version = excluded.version`,
+			item.ID, item.Code, item.Parent, item.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, code := range deletes {
+		_, err := tx.Exec(`DELETE FROM categories WHERE code = ?`, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(category *entity.Category) row {
+	return row{
+		ID:      category.ID.String(),
+		Code:    category.Code.Value(),
+		Parent:  category.Parent.Value(),
+		Version: category.Version,
+	}
+}
+
+func (item row) toCategory() (*entity.Category, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(item.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	// A top-level category has no parent, which NewCode rejects.
+	var parent valueobject.Code
+	if item.Parent != "" {
+		parent, err = valueobject.NewCode(item.Parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &entity.Category{
+		ID:      id,
+		Code:    code,
+		Parent:  parent,
+		Version: item.Version,
+	}, nil
+}
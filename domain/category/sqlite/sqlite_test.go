@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/category/categorytest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteCategoryRepository(t *testing.T) {
+	categorytest.RunRepositorySuite(t, func(t *testing.T) category.CategoryRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewCategoryRepository(db, storage.New[*entity.Category]())
+		require.NoError(t, err)
+		return repository
+	})
+}
+
+func TestCategoryRepositoryFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := database.Open(path)
+	require.NoError(t, err)
+
+	repository, err := NewCategoryRepository(db, storage.New[*entity.Category]())
+	require.NoError(t, err)
+
+	parent, _ := valueobject.NewCode("C1")
+	require.NoError(t, repository.Create(&entity.Category{ID: uuid.New(), Code: parent}))
+	code, _ := valueobject.NewCode("C2")
+	stored := &entity.Category{ID: uuid.New(), Code: code, Parent: parent}
+	require.NoError(t, repository.Create(stored))
+	require.NoError(t, database.Flush(db, repository))
+	require.NoError(t, db.Close())
+
+	db, err = database.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	reopened, err := NewCategoryRepository(db, storage.New[*entity.Category]())
+	require.NoError(t, err)
+
+	loaded, err := reopened.Get(code)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, loaded)
+}
@@ -0,0 +1,133 @@
+// Package coupontest provides the contract every
+// coupon.CouponRepository implementation must satisfy.
+package coupontest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) coupon.CouponRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func newCoupon(code string) *entity.Coupon {
+	couponCode, _ := valueobject.NewCode(code)
+	discount, _ := valueobject.NewDiscount(valueobject.FixedDiscount, 10)
+	return &entity.Coupon{ID: uuid.New(), Code: couponCode, Discount: discount}
+}
+
+func testCreate(t *testing.T, repository coupon.CouponRepository) {
+	stored := newCoupon("C1")
+
+	t.Run("Create coupon", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create coupon which already exist", func(t *testing.T) {
+		err := repository.Create(newCoupon("C1"))
+		assert.ErrorIs(t, err, coupon.ErrAlreadyExist)
+
+		result, err := repository.Get(stored.Code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func testGet(t *testing.T, repository coupon.CouponRepository) {
+	stored := newCoupon("C1")
+	repository.Create(stored)
+
+	t.Run("Get coupon", func(t *testing.T) {
+		code, _ := valueobject.NewCode("C1")
+
+		result, err := repository.Get(code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get coupon which not exist", func(t *testing.T) {
+		code, _ := valueobject.NewCode("C2")
+
+		result, err := repository.Get(code)
+		assert.ErrorIs(t, err, coupon.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetAll(t *testing.T, repository coupon.CouponRepository) {
+	t.Run("Get all coupons of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all coupons ordered by code", func(t *testing.T) {
+		for _, code := range []string{"C3", "C1", "C2"} {
+			repository.Create(newCoupon(code))
+		}
+
+		var codes []string
+		for _, item := range repository.GetAll() {
+			codes = append(codes, item.Code.Value())
+		}
+		assert.Equal(t, []string{"C1", "C2", "C3"}, codes)
+	})
+}
+
+func testUpdate(t *testing.T, repository coupon.CouponRepository) {
+	stored := newCoupon("C1")
+	repository.Create(stored)
+
+	t.Run("Update coupon", func(t *testing.T) {
+		stored.Uses = 1
+		err := repository.Update(stored, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update coupon with stale version", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+
+		var conflictErr *types.VersionConflictError
+		assert.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "coupon", conflictErr.Aggregate)
+		assert.Equal(t, "C1", conflictErr.Key)
+		assert.Equal(t, 0, conflictErr.Expected)
+		assert.Equal(t, 1, conflictErr.Actual)
+	})
+
+	t.Run("Update coupon which not exist", func(t *testing.T) {
+		err := repository.Update(newCoupon("C2"), 0)
+		assert.ErrorIs(t, err, coupon.ErrNotFound)
+	})
+}
+
+func testClear(t *testing.T, repository coupon.CouponRepository) {
+	repository.Create(newCoupon("C1"))
+	repository.Create(newCoupon("C2"))
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	code, _ := valueobject.NewCode("C1")
+	_, err := repository.Get(code)
+	assert.ErrorIs(t, err, coupon.ErrNotFound)
+
+	t.Run("Create coupon after clear", func(t *testing.T) {
+		err := repository.Create(newCoupon("C1"))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+type CouponRepository struct {
+	storage types.Storage[*entity.Coupon]
+	mu      sync.Mutex
+}
+
+func NewCouponRepository(storage types.Storage[*entity.Coupon]) *CouponRepository {
+	return &CouponRepository{storage: storage}
+}
+
+func (r *CouponRepository) Create(newCoupon *entity.Coupon) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.storage.Get(newCoupon.Code.Value())
+	if ok {
+		return coupon.ErrAlreadyExist
+	}
+
+	r.storage.Set(newCoupon.Code.Value(), newCoupon)
+	return nil
+}
+
+func (r *CouponRepository) Get(code valueobject.Code) (*entity.Coupon, error) {
+	result, ok := r.storage.Get(code.Value())
+	if !ok {
+		return nil, coupon.ErrNotFound
+	}
+
+	return result, nil
+}
+
+func (r *CouponRepository) GetAll() []*entity.Coupon {
+	var result []*entity.Coupon
+	for _, item := range r.storage.Values() {
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code.Value() < result[j].Code.Value()
+	})
+
+	return result
+}
+
+func (r *CouponRepository) Update(updatedCoupon *entity.Coupon, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.storage.Get(updatedCoupon.Code.Value())
+	if !ok {
+		return coupon.ErrNotFound
+	}
+
+	if current.Version != version {
+		return &types.VersionConflictError{Aggregate: "coupon", Key: updatedCoupon.Code.Value(), Expected: version, Actual: current.Version}
+	}
+
+	updatedCoupon.Version = version + 1
+	r.storage.Set(updatedCoupon.Code.Value(), updatedCoupon)
+	return nil
+}
+
+func (r *CouponRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/domain/coupon/coupontest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+)
+
+func TestMemoryCouponRepository(t *testing.T) {
+	coupontest.RunRepositorySuite(t, func(t *testing.T) coupon.CouponRepository {
+		return NewCouponRepository(storage.New[*entity.Coupon]())
+	})
+}
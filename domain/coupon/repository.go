@@ -0,0 +1,25 @@
+package coupon
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+var (
+	ErrNotFound     = errors.New("Coupon not found")
+	ErrAlreadyExist = errors.New("Coupon already exist")
+)
+
+//go:generate mockgen -destination=../../mock/repository/coupon/coupon.go -package=repository github.com/aaydin-tr/e-commerce/domain/coupon CouponRepository
+type CouponRepository interface {
+	Create(coupon *entity.Coupon) error
+	Get(code valueobject.Code) (*entity.Coupon, error)
+	// GetAll returns every coupon ordered by code.
+	GetAll() []*entity.Coupon
+	// Update stores coupon if the stored version still equals version and
+	// increments it, otherwise it returns a *types.VersionConflictError.
+	Update(coupon *entity.Coupon, version int) error
+	Clear()
+}
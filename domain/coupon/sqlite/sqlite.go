@@ -0,0 +1,237 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE coupons (
+		id TEXT NOT NULL,
+		code TEXT PRIMARY KEY,
+		discount_type TEXT NOT NULL,
+		amount REAL NOT NULL,
+		usage_limit INTEGER NOT NULL,
+		uses INTEGER NOT NULL,
+		expires_at INTEGER,
+		products TEXT NOT NULL,
+		categories TEXT NOT NULL,
+		version INTEGER NOT NULL
+	)`,
+}
+
+// CouponRepository keeps coupons in memory and writes them to the database on
+// Flush.
+type CouponRepository struct {
+	*memory.CouponRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Coupon]
+	persisted map[string]row
+}
+
+// row keeps the expiry in Unix seconds, NULL for a coupon that never expires,
+// and the product and category restrictions as JSON, so rows stay comparable.
+type row struct {
+	ID           string
+	Code         string
+	DiscountType string
+	Amount       float64
+	UsageLimit   int
+	Uses         int
+	ExpiresAt    sql.NullInt64
+	Products     string
+	Categories   string
+	Version      int
+}
+
+func NewCouponRepository(db *sql.DB, storage types.Storage[*entity.Coupon]) (*CouponRepository, error) {
+	err := database.Migrate(db, "coupons", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CouponRepository{
+		CouponRepository: memory.NewCouponRepository(storage),
+		db:               db,
+		storage:          storage,
+		persisted:        make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *CouponRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, code, discount_type, amount, usage_limit, uses, expires_at, products, categories, version FROM coupons`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Code, &item.DiscountType, &item.Amount, &item.UsageLimit, &item.Uses, &item.ExpiresAt, &item.Products, &item.Categories, &item.Version)
+		if err != nil {
+			return err
+		}
+
+		coupon, err := item.toCoupon()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.Code, coupon)
+		r.persisted[item.Code] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *CouponRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, coupon := range r.storage.Values() {
+		item, err := newRow(coupon)
+		if err != nil {
+			return nil, err
+		}
+		current[coupon.Code.Value()] = item
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO coupons (id, code, discount_type, amount, usage_limit, uses, expires_at, products, categories, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (code) DO UPDATE SET id = excluded.id, discount_type = excluded.discount_type, amount = excluded.amount, usage_limit = excluded.usage_limit, uses = excluded.uses,
+			expires_at = excluded.expires_at, products = excluded.products, categories = excluded.categories, version = excluded.version`,
+			item.ID, item.Code, item.DiscountType, item.Amount, item.UsageLimit, item.Uses, item.ExpiresAt, item.Products, item.Categories, item.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, code := range deletes {
+		_, err := tx.Exec(`DELETE FROM coupons WHERE code = ?`, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(coupon *entity.Coupon) (row, error) {
+	products, err := encodeCodes(coupon.Products)
+	if err != nil {
+		return row{}, err
+	}
+
+	categories, err := encodeCodes(coupon.Categories)
+	if err != nil {
+		return row{}, err
+	}
+
+	item := row{
+		ID:           coupon.ID.String(),
+		Code:         coupon.Code.Value(),
+		DiscountType: coupon.Discount.Type(),
+		Amount:       coupon.Discount.Amount(),
+		UsageLimit:   coupon.UsageLimit,
+		Uses:         coupon.Uses,
+		Products:     products,
+		Categories:   categories,
+		Version:      coupon.Version,
+	}
+	if !coupon.ExpiresAt.IsZero() {
+		item.ExpiresAt = sql.NullInt64{Int64: coupon.ExpiresAt.Unix(), Valid: true}
+	}
+
+	return item, nil
+}
+
+func (item row) toCoupon() (*entity.Coupon, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(item.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := valueobject.NewDiscount(item.DiscountType, item.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := decodeCodes(item.Products)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := decodeCodes(item.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt time.Time
+	if item.ExpiresAt.Valid {
+		expiresAt = time.Unix(item.ExpiresAt.Int64, 0).UTC()
+	}
+
+	return &entity.Coupon{
+		ID:         id,
+		Code:       code,
+		Discount:   discount,
+		UsageLimit: item.UsageLimit,
+		Uses:       item.Uses,
+		ExpiresAt:  expiresAt,
+		Products:   products,
+		Categories: categories,
+		Version:    item.Version,
+	}, nil
+}
+
+func encodeCodes(codes []valueobject.Code) (string, error) {
+	values := make([]string, 0, len(codes))
+	for _, code := range codes {
+		values = append(values, code.Value())
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
+
+func decodeCodes(encoded string) ([]valueobject.Code, error) {
+	var values []string
+	err := json.Unmarshal([]byte(encoded), &values)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []valueobject.Code
+	for _, value := range values {
+		code, err := valueobject.NewCode(value)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
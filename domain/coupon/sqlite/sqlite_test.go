@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/domain/coupon/coupontest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteCouponRepository(t *testing.T) {
+	coupontest.RunRepositorySuite(t, func(t *testing.T) coupon.CouponRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewCouponRepository(db, storage.New[*entity.Coupon]())
+		require.NoError(t, err)
+		return repository
+	})
+}
+
+func TestCouponRepositoryFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := database.Open(path)
+	require.NoError(t, err)
+
+	repository, err := NewCouponRepository(db, storage.New[*entity.Coupon]())
+	require.NoError(t, err)
+
+	code, _ := valueobject.NewCode("C1")
+	discount, _ := valueobject.NewDiscount(valueobject.PercentDiscount, 10)
+	product, _ := valueobject.NewCode("P1")
+	category, _ := valueobject.NewCode("K1")
+	stored := &entity.Coupon{
+		ID:         uuid.New(),
+		Code:       code,
+		Discount:   discount,
+		UsageLimit: 5,
+		Uses:       2,
+		ExpiresAt:  time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		Products:   []valueobject.Code{product},
+		Categories: []valueobject.Code{category},
+	}
+	require.NoError(t, repository.Create(stored))
+	require.NoError(t, database.Flush(db, repository))
+	require.NoError(t, db.Close())
+
+	db, err = database.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	reopened, err := NewCouponRepository(db, storage.New[*entity.Coupon]())
+	require.NoError(t, err)
+
+	loaded, err := reopened.Get(code)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, loaded)
+}
@@ -0,0 +1,68 @@
+// Package ledgertest provides the contract every ledger.LedgerRepository
+// implementation must satisfy.
+package ledgertest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) ledger.LedgerRepository) {
+	t.Run("Append", func(t *testing.T) { testAppend(t, newRepository(t)) })
+	t.Run("ListByProduct", func(t *testing.T) { testListByProduct(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func testAppend(t *testing.T, repository ledger.LedgerRepository) {
+	first := &entity.StockMovement{ID: uuid.New(), ProductID: uuid.New(), Quantity: 10}
+	second := &entity.StockMovement{ID: uuid.New(), ProductID: first.ProductID, Quantity: -4}
+
+	t.Run("Append movements in order", func(t *testing.T) {
+		assert.NoError(t, repository.Append(second))
+		assert.NoError(t, repository.Append(first))
+		assert.Less(t, second.Sequence, first.Sequence)
+		assert.Equal(t, []*entity.StockMovement{second, first}, repository.GetAll())
+	})
+
+	t.Run("Append movement which already exist", func(t *testing.T) {
+		err := repository.Append(&entity.StockMovement{ID: first.ID})
+		assert.ErrorIs(t, err, ledger.ErrAlreadyExist)
+		assert.Len(t, repository.GetAll(), 2)
+	})
+}
+
+func testListByProduct(t *testing.T, repository ledger.LedgerRepository) {
+	productID := uuid.New()
+	var expected []*entity.StockMovement
+	for i := 0; i < 5; i++ {
+		movement := &entity.StockMovement{ID: uuid.New(), ProductID: productID, Quantity: i}
+		repository.Append(movement)
+		expected = append(expected, movement)
+		repository.Append(&entity.StockMovement{ID: uuid.New(), ProductID: uuid.New(), Quantity: i})
+	}
+
+	assert.Equal(t, expected, repository.ListByProduct(productID))
+	assert.Len(t, repository.ListByProduct(uuid.New()), 0)
+}
+
+func testClear(t *testing.T, repository ledger.LedgerRepository) {
+	productID := uuid.New()
+	repository.Append(&entity.StockMovement{ID: uuid.New(), ProductID: productID})
+	repository.Append(&entity.StockMovement{ID: uuid.New(), ProductID: productID})
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+	assert.Len(t, repository.ListByProduct(productID), 0)
+
+	t.Run("Append movement after clear", func(t *testing.T) {
+		movement := &entity.StockMovement{ID: uuid.New(), ProductID: productID}
+		assert.NoError(t, repository.Append(movement))
+		assert.Equal(t, []*entity.StockMovement{movement}, repository.ListByProduct(productID))
+	})
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/google/uuid"
+)
+
+type LedgerRepository struct {
+	storage types.Storage[*entity.StockMovement]
+	mu      sync.Mutex
+	// sequence is the last sequence handed out, picked up from the stored
+	// movements on the first Append. Movements rolled back with their unit of
+	// work and cleared movements leave gaps, which keep the order intact.
+	sequence int
+}
+
+const productIndex = "product"
+
+func NewLedgerRepository(storage types.Storage[*entity.StockMovement]) *LedgerRepository {
+	storage.AddIndex(productIndex, func(item *entity.StockMovement) string {
+		return item.ProductID.String()
+	})
+
+	return &LedgerRepository{storage: storage}
+}
+
+func (r *LedgerRepository) Append(movement *entity.StockMovement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.storage.Get(movement.ID.String())
+	if ok {
+		return ledger.ErrAlreadyExist
+	}
+
+	if r.sequence == 0 {
+		for _, item := range r.storage.Values() {
+			if item.Sequence > r.sequence {
+				r.sequence = item.Sequence
+			}
+		}
+	}
+
+	r.sequence++
+	movement.Sequence = r.sequence
+	r.storage.Set(movement.ID.String(), movement)
+	return nil
+}
+
+func (r *LedgerRepository) ListByProduct(productID uuid.UUID) []*entity.StockMovement {
+	return sortBySequence(r.storage.GetBy(productIndex, productID.String()))
+}
+
+func (r *LedgerRepository) GetAll() []*entity.StockMovement {
+	return sortBySequence(r.storage.Values())
+}
+
+func (r *LedgerRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
+
+func sortBySequence(movements []*entity.StockMovement) []*entity.StockMovement {
+	result := append([]*entity.StockMovement(nil), movements...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Sequence < result[j].Sequence
+	})
+
+	return result
+}
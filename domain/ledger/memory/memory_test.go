@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
+	"github.com/aaydin-tr/e-commerce/domain/ledger/ledgertest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+)
+
+func TestMemoryLedgerRepository(t *testing.T) {
+	ledgertest.RunRepositorySuite(t, func(t *testing.T) ledger.LedgerRepository {
+		return NewLedgerRepository(storage.New[*entity.StockMovement]())
+	})
+}
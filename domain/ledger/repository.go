@@ -0,0 +1,24 @@
+package ledger
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAlreadyExist = errors.New("Stock movement already exist")
+)
+
+//go:generate mockgen -destination=../../mock/repository/ledger/ledger.go -package=repository github.com/aaydin-tr/e-commerce/domain/ledger LedgerRepository
+type LedgerRepository interface {
+	// Append numbers the movement after every earlier one and stores it.
+	// Movements are never changed once appended.
+	Append(movement *entity.StockMovement) error
+	// ListByProduct returns the movements of a product in ledger order.
+	ListByProduct(productID uuid.UUID) []*entity.StockMovement
+	// GetAll returns every movement in ledger order.
+	GetAll() []*entity.StockMovement
+	Clear()
+}
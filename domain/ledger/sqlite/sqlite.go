@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE stock_movements (
+		id TEXT PRIMARY KEY,
+		sequence INTEGER NOT NULL,
+		product_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		reference TEXT NOT NULL,
+		time INTEGER NOT NULL
+	)`,
+}
+
+// LedgerRepository keeps stock movements in memory and writes them to the
+// database on Flush.
+type LedgerRepository struct {
+	*memory.LedgerRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.StockMovement]
+	persisted map[string]row
+}
+
+// row keeps the movement time in Unix seconds.
+type row struct {
+	ID        string
+	Sequence  int
+	ProductID string
+	Type      string
+	Quantity  int
+	Reference string
+	Time      int64
+}
+
+func NewLedgerRepository(db *sql.DB, storage types.Storage[*entity.StockMovement]) (*LedgerRepository, error) {
+	err := database.Migrate(db, "stock_movements", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LedgerRepository{
+		LedgerRepository: memory.NewLedgerRepository(storage),
+		db:               db,
+		storage:          storage,
+		persisted:        make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *LedgerRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, sequence, product_id, type, quantity, reference, time FROM stock_movements`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Sequence, &item.ProductID, &item.Type, &item.Quantity, &item.Reference, &item.Time)
+		if err != nil {
+			return err
+		}
+
+		movement, err := item.toMovement()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.ID, movement)
+		r.persisted[item.ID] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *LedgerRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, movement := range r.storage.Values() {
+		current[movement.ID.String()] = newRow(movement)
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO stock_movements (id, sequence, product_id, type, quantity, reference, time) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET sequence = excluded.sequence, product_id = excluded.product_id, type = excluded.type, quantity = excluded.quantity,
+			reference = excluded.reference, time = excluded.time`,
+			item.ID, item.Sequence, item.ProductID, item.Type, item.Quantity, item.Reference, item.Time)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range deletes {
+		_, err := tx.Exec(`DELETE FROM stock_movements WHERE id = ?`, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(movement *entity.StockMovement) row {
+	return row{
+		ID:        movement.ID.String(),
+		Sequence:  movement.Sequence,
+		ProductID: movement.ProductID.String(),
+		Type:      movement.Type.Value(),
+		Quantity:  movement.Quantity,
+		Reference: movement.Reference.String(),
+		Time:      movement.Time.Unix(),
+	}
+}
+
+func (item row) toMovement() (*entity.StockMovement, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	productID, err := uuid.Parse(item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	movementType, err := valueobject.NewMovementType(item.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	reference, err := uuid.Parse(item.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.StockMovement{
+		ID:        id,
+		Sequence:  item.Sequence,
+		ProductID: productID,
+		Type:      movementType,
+		Quantity:  item.Quantity,
+		Reference: reference,
+		Time:      time.Unix(item.Time, 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
+	"github.com/aaydin-tr/e-commerce/domain/ledger/ledgertest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteLedgerRepository(t *testing.T) {
+	ledgertest.RunRepositorySuite(t, func(t *testing.T) ledger.LedgerRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewLedgerRepository(db, storage.New[*entity.StockMovement]())
+		require.NoError(t, err)
+		return repository
+	})
+}
+
+func TestLedgerRepositoryFlush(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository, err := NewLedgerRepository(db, storage.New[*entity.StockMovement]())
+	require.NoError(t, err)
+
+	productID := uuid.New()
+	initial, _ := valueobject.NewMovementType(valueobject.InitialMovement)
+	sale, _ := valueobject.NewMovementType(valueobject.SaleMovement)
+	first := &entity.StockMovement{ID: uuid.New(), ProductID: productID, Type: initial, Quantity: 10, Reference: productID, Time: time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC)}
+	second := &entity.StockMovement{ID: uuid.New(), ProductID: productID, Type: sale, Quantity: -3, Reference: uuid.New(), Time: time.Date(0, 0, 0, 2, 0, 0, 0, time.UTC)}
+	repository.Append(first)
+	repository.Append(second)
+
+	err = database.Flush(db, repository)
+	assert.NoError(t, err)
+
+	loaded, err := NewLedgerRepository(db, storage.New[*entity.StockMovement]())
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.StockMovement{first, second}, loaded.ListByProduct(productID))
+
+	third := &entity.StockMovement{ID: uuid.New(), ProductID: productID, Type: sale, Quantity: -1, Reference: uuid.New(), Time: second.Time}
+	assert.NoError(t, loaded.Append(third))
+	assert.Greater(t, third.Sequence, second.Sequence)
+}
@@ -1,20 +1,35 @@
 package memory
 
 import (
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/aaydin-tr/e-commerce/domain/order"
 	"github.com/aaydin-tr/e-commerce/entity"
 	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
 )
 
 type OrderRepository struct {
 	storage types.Storage[*entity.Order]
+	mu      sync.Mutex
 }
 
+const productIndex = "product"
+
 func NewOrderRepository(storage types.Storage[*entity.Order]) *OrderRepository {
+	storage.AddIndex(productIndex, func(item *entity.Order) string {
+		return item.ProductID.String()
+	})
+
 	return &OrderRepository{storage: storage}
 }
 
 func (r *OrderRepository) Create(newOrder *entity.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	_, ok := r.storage.Get(newOrder.ID.String())
 	if ok {
 		return order.ErrOrderAlreadyExist
@@ -23,3 +38,96 @@ func (r *OrderRepository) Create(newOrder *entity.Order) error {
 	r.storage.Set(newOrder.ID.String(), newOrder)
 	return nil
 }
+
+func (r *OrderRepository) Get(id uuid.UUID) (*entity.Order, error) {
+	result, ok := r.storage.Get(id.String())
+	if !ok {
+		return nil, order.ErrOrderNotFound
+	}
+
+	return result, nil
+}
+
+func (r *OrderRepository) GetAll() []*entity.Order {
+	var result []*entity.Order
+	for _, item := range r.storage.Values() {
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID.String() < result[j].ID.String()
+	})
+
+	return result
+}
+
+func (r *OrderRepository) ListByProduct(productID uuid.UUID) []*entity.Order {
+	return r.storage.GetBy(productIndex, productID.String())
+}
+
+func (r *OrderRepository) ListByTimeRange(from time.Time, to time.Time) []*entity.Order {
+	var result []*entity.Order
+	for _, item := range r.storage.Values() {
+		if !item.CreatedAt.Before(from) && item.CreatedAt.Before(to) {
+			result = append(result, item)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID.String() < result[j].ID.String()
+	})
+
+	return result
+}
+
+func (r *OrderRepository) UnitsSold(productID uuid.UUID) int {
+	units := 0
+	for _, item := range r.ListByProduct(productID) {
+		if item.Status.Value() == valueobject.Cancelled {
+			continue
+		}
+		units += item.Quantity.Value()
+	}
+
+	return units
+}
+
+func (r *OrderRepository) Revenue(productID uuid.UUID) float64 {
+	revenue := 0.0
+	for _, item := range r.ListByProduct(productID) {
+		if item.Status.Value() == valueobject.Cancelled {
+			continue
+		}
+		revenue += item.Total()
+	}
+
+	return revenue
+}
+
+func (r *OrderRepository) Update(updatedOrder *entity.Order, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.storage.Get(updatedOrder.ID.String())
+	if !ok {
+		return order.ErrOrderNotFound
+	}
+
+	if current.Version != version {
+		return &types.VersionConflictError{Aggregate: "order", Key: updatedOrder.ID.String(), Expected: version, Actual: current.Version}
+	}
+
+	updatedOrder.Version = version + 1
+	r.storage.Set(updatedOrder.ID.String(), updatedOrder)
+	return nil
+}
+
+func (r *OrderRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
@@ -0,0 +1,220 @@
+// Package ordertest provides the contract every order.OrderRepository
+// implementation must satisfy.
+package ordertest
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/order"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) order.OrderRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("ListByProduct", func(t *testing.T) { testListByProduct(t, newRepository(t)) })
+	t.Run("ListByTimeRange", func(t *testing.T) { testListByTimeRange(t, newRepository(t)) })
+	t.Run("Sales", func(t *testing.T) { testSales(t, newRepository(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func testCreate(t *testing.T, repository order.OrderRepository) {
+	stored := &entity.Order{ID: uuid.New()}
+
+	t.Run("Create order", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create order which already exist", func(t *testing.T) {
+		err := repository.Create(&entity.Order{ID: stored.ID})
+		assert.ErrorIs(t, err, order.ErrOrderAlreadyExist)
+
+		orders := repository.GetAll()
+		assert.Len(t, orders, 1)
+		assert.Same(t, stored, orders[0])
+	})
+}
+
+func testGet(t *testing.T, repository order.OrderRepository) {
+	stored := &entity.Order{ID: uuid.New()}
+	repository.Create(stored)
+
+	t.Run("Get order", func(t *testing.T) {
+		result, err := repository.Get(stored.ID)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get order which not exist", func(t *testing.T) {
+		result, err := repository.Get(uuid.New())
+		assert.ErrorIs(t, err, order.ErrOrderNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetAll(t *testing.T, repository order.OrderRepository) {
+	t.Run("Get all orders of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all orders ordered by ID", func(t *testing.T) {
+		var ids []string
+		for i := 0; i < 3; i++ {
+			id := uuid.New()
+			ids = append(ids, id.String())
+			repository.Create(&entity.Order{ID: id})
+		}
+		sort.Strings(ids)
+
+		var result []string
+		for _, item := range repository.GetAll() {
+			result = append(result, item.ID.String())
+		}
+		assert.Equal(t, ids, result)
+	})
+}
+
+func testListByProduct(t *testing.T, repository order.OrderRepository) {
+	productID := uuid.New()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		ids = append(ids, id.String())
+		repository.Create(&entity.Order{ID: id, ProductID: productID})
+	}
+	sort.Strings(ids)
+	repository.Create(&entity.Order{ID: uuid.New(), ProductID: uuid.New()})
+
+	t.Run("List orders of product ordered by ID", func(t *testing.T) {
+		var result []string
+		for _, item := range repository.ListByProduct(productID) {
+			result = append(result, item.ID.String())
+		}
+		assert.Equal(t, ids, result)
+	})
+
+	t.Run("List orders of product without orders", func(t *testing.T) {
+		assert.Len(t, repository.ListByProduct(uuid.New()), 0)
+	})
+
+	t.Run("List orders of product after clear", func(t *testing.T) {
+		repository.Clear()
+		assert.Len(t, repository.ListByProduct(productID), 0)
+	})
+}
+
+func testListByTimeRange(t *testing.T, repository order.OrderRepository) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, hour := range []int{3, 1, 2, 0} {
+		repository.Create(&entity.Order{ID: uuid.New(), CreatedAt: start.Add(time.Duration(hour) * time.Hour)})
+	}
+
+	t.Run("List orders from inclusive to exclusive ordered by time", func(t *testing.T) {
+		var hours []int
+		for _, item := range repository.ListByTimeRange(start.Add(time.Hour), start.Add(3*time.Hour)) {
+			hours = append(hours, int(item.CreatedAt.Sub(start)/time.Hour))
+		}
+		assert.Equal(t, []int{1, 2}, hours)
+	})
+
+	t.Run("List orders of empty range", func(t *testing.T) {
+		assert.Len(t, repository.ListByTimeRange(start.Add(5*time.Hour), start.Add(6*time.Hour)), 0)
+	})
+}
+
+func testSales(t *testing.T, repository order.OrderRepository) {
+	productID := uuid.New()
+	for _, item := range []struct {
+		quantity int
+		price    float64
+	}{{quantity: 2, price: 100}, {quantity: 3, price: 80}} {
+		quantity, _ := valueobject.NewQuantity(item.quantity)
+		price, _ := valueobject.NewPrice(item.price)
+		repository.Create(&entity.Order{ID: uuid.New(), ProductID: productID, Quantity: quantity, Price: price})
+	}
+
+	quantity, _ := valueobject.NewQuantity(10)
+	price, _ := valueobject.NewPrice(10)
+	repository.Create(&entity.Order{ID: uuid.New(), ProductID: uuid.New(), Quantity: quantity, Price: price})
+
+	t.Run("Units sold", func(t *testing.T) {
+		assert.Equal(t, 5, repository.UnitsSold(productID))
+		assert.Equal(t, 0, repository.UnitsSold(uuid.New()))
+	})
+
+	t.Run("Revenue", func(t *testing.T) {
+		assert.Equal(t, 440.0, repository.Revenue(productID))
+		assert.Equal(t, 0.0, repository.Revenue(uuid.New()))
+	})
+
+	t.Run("Cancelled orders are not counted", func(t *testing.T) {
+		quantity, _ := valueobject.NewQuantity(4)
+		price, _ := valueobject.NewPrice(50)
+		status, _ := valueobject.NewOrderStatus(valueobject.Placed)
+		cancelled := &entity.Order{ID: uuid.New(), ProductID: productID, Quantity: quantity, Price: price, Status: status}
+		repository.Create(cancelled)
+		assert.Equal(t, 9, repository.UnitsSold(productID))
+		assert.Equal(t, 640.0, repository.Revenue(productID))
+
+		version := cancelled.Version
+		cancelled.ChangeStatus(valueobject.Cancelled)
+		err := repository.Update(cancelled, version)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, repository.UnitsSold(productID))
+		assert.Equal(t, 440.0, repository.Revenue(productID))
+	})
+}
+
+func testUpdate(t *testing.T, repository order.OrderRepository) {
+	stored := &entity.Order{ID: uuid.New()}
+	repository.Create(stored)
+
+	t.Run("Update order", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update order with stale version", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+
+		var conflictErr *types.VersionConflictError
+		assert.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "order", conflictErr.Aggregate)
+		assert.Equal(t, stored.ID.String(), conflictErr.Key)
+		assert.Equal(t, 0, conflictErr.Expected)
+		assert.Equal(t, 1, conflictErr.Actual)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update order which not exist", func(t *testing.T) {
+		err := repository.Update(&entity.Order{ID: uuid.New()}, 0)
+		assert.ErrorIs(t, err, order.ErrOrderNotFound)
+	})
+}
+
+func testClear(t *testing.T, repository order.OrderRepository) {
+	stored := &entity.Order{ID: uuid.New()}
+	repository.Create(stored)
+	repository.Create(&entity.Order{ID: uuid.New()})
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	t.Run("Create order after clear", func(t *testing.T) {
+		err := repository.Create(&entity.Order{ID: stored.ID})
+		assert.NoError(t, err)
+	})
+}
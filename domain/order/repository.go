@@ -2,15 +2,36 @@ package order
 
 import (
 	"errors"
+	"time"
 
 	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/google/uuid"
 )
 
 var (
 	ErrOrderAlreadyExist = errors.New("Order already exist")
+	ErrOrderNotFound     = errors.New("Order not found")
 )
 
 //go:generate mockgen -destination=../../mock/repository/order/order.go -package=repository github.com/aaydin-tr/e-commerce/domain/order OrderRepository
 type OrderRepository interface {
 	Create(order *entity.Order) error
+	Get(id uuid.UUID) (*entity.Order, error)
+	// GetAll returns every order ordered by ID.
+	GetAll() []*entity.Order
+	// ListByProduct returns the orders of the product ordered by ID.
+	ListByProduct(productID uuid.UUID) []*entity.Order
+	// ListByTimeRange returns the orders created at or after from and before
+	// to, ordered by creation time.
+	ListByTimeRange(from time.Time, to time.Time) []*entity.Order
+	// UnitsSold returns the total quantity ordered of the product, leaving
+	// out cancelled orders.
+	UnitsSold(productID uuid.UUID) int
+	// Revenue returns the total amount paid for the product, leaving out
+	// cancelled orders.
+	Revenue(productID uuid.UUID) float64
+	Clear()
+	// Update stores order if the stored version still equals version and
+	// increments it, otherwise it returns a *types.VersionConflictError.
+	Update(order *entity.Order, version int) error
 }
@@ -0,0 +1,306 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/order/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE orders (
+		id TEXT PRIMARY KEY,
+		product_id TEXT NOT NULL,
+		quantity INTEGER NOT NULL,
+		version INTEGER NOT NULL
+	)`,
+	`ALTER TABLE orders ADD COLUMN price REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN created_at INTEGER`,
+	`ALTER TABLE orders ADD COLUMN campaign TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN status TEXT NOT NULL DEFAULT 'Placed'`,
+	`ALTER TABLE orders ADD COLUMN backordered INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN allocations TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE orders ADD COLUMN coupon TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN discount REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN list_price REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN tier_percent REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN region TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN tax_rate REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN tax REAL NOT NULL DEFAULT 0`,
+}
+
+// OrderRepository keeps orders in memory and writes them to the database on
+// Flush.
+type OrderRepository struct {
+	*memory.OrderRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Order]
+	persisted map[string]row
+}
+
+// row keeps the creation time in Unix seconds and the warehouse allocations as
+// JSON, so rows stay comparable. Orders written before the price and creation
+// time were recorded have neither.
+type row struct {
+	ID          string
+	ProductID   string
+	Quantity    int
+	Price       float64
+	ListPrice   float64
+	TierPercent float64
+	Campaign    string
+	Coupon      string
+	Discount    float64
+	Region      string
+	TaxRate     float64
+	Tax         float64
+	Backordered int
+	Status      string
+	CreatedAt   sql.NullInt64
+	Allocations string
+	Version     int
+}
+
+type allocation struct {
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+	Product   string `json:"product,omitempty"`
+}
+
+func NewOrderRepository(db *sql.DB, storage types.Storage[*entity.Order]) (*OrderRepository, error) {
+	err := database.Migrate(db, "orders", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &OrderRepository{
+		OrderRepository: memory.NewOrderRepository(storage),
+		db:              db,
+		storage:         storage,
+		persisted:       make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *OrderRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, product_id, quantity, price, list_price, tier_percent, campaign, coupon, discount, region, tax_rate, tax, backordered, status, created_at, allocations, version FROM orders`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.ProductID, &item.Quantity, &item.Price, &item.ListPrice, &item.TierPercent, &item.Campaign, &item.Coupon, &item.Discount, &item.Region, &item.TaxRate, &item.Tax, &item.Backordered, &item.Status, &item.CreatedAt, &item.Allocations, &item.Version)
+		if err != nil {
+			return err
+		}
+
+		order, err := item.toOrder()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.ID, order)
+		r.persisted[item.ID] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *OrderRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, order := range r.storage.Values() {
+		item, err := newRow(order)
+		if err != nil {
+			return nil, err
+		}
+		current[order.ID.String()] = item
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO orders (id, product_id, quantity, price, list_price, tier_percent, campaign, coupon, discount, region, tax_rate, tax, backordered, status, created_at, allocations, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET product_id = excluded.product_id, quantity = excluded.quantity, price = excluded.price, list_price = excluded.list_price, tier_percent = excluded.tier_percent, campaign = excluded.campaign, coupon = excluded.coupon,
+			discount = excluded.discount, region = excluded.region, tax_rate = excluded.tax_rate, tax = excluded.tax, backordered = excluded.backordered, status = excluded.status, created_at = excluded.created_at, allocations = excluded.allocations, version = excluded.version`,
+			item.ID, item.ProductID, item.Quantity, item.Price, item.ListPrice, item.TierPercent, item.Campaign, item.Coupon, item.Discount, item.Region, item.TaxRate, item.Tax, item.Backordered, item.Status, item.CreatedAt, item.Allocations, item.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range deletes {
+		_, err := tx.Exec(`DELETE FROM orders WHERE id = ?`, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(order *entity.Order) (row, error) {
+	allocations := make([]allocation, 0, len(order.Allocations))
+	for _, item := range order.Allocations {
+		allocations = append(allocations, allocation{Warehouse: item.Warehouse.Value(), Quantity: item.Quantity, Product: item.Product.Value()})
+	}
+
+	encoded, err := json.Marshal(allocations)
+	if err != nil {
+		return row{}, err
+	}
+
+	return row{
+		ID:          order.ID.String(),
+		ProductID:   order.ProductID.String(),
+		Quantity:    order.Quantity.Value(),
+		Price:       order.Price.Value(),
+		ListPrice:   order.ListPrice.Value(),
+		TierPercent: order.TierPercent,
+		Campaign:    order.Campaign.Value(),
+		Coupon:      order.Coupon.Value(),
+		Discount:    order.Discount.Value(),
+		Region:      order.Region.Value(),
+		TaxRate:     order.TaxRate,
+		Tax:         order.Tax,
+		Backordered: order.Backordered.Value(),
+		Status:      order.Status.Value(),
+		CreatedAt:   sql.NullInt64{Int64: order.CreatedAt.Unix(), Valid: true},
+		Allocations: string(encoded),
+		Version:     order.Version,
+	}, nil
+}
+
+func (item row) toOrder() (*entity.Order, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	productID, err := uuid.Parse(item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := valueobject.NewQuantity(item.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	var price valueobject.Price
+	if item.Price != 0 {
+		price, err = valueobject.NewPrice(item.Price)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var listPrice valueobject.Price
+	if item.TierPercent != 0 {
+		listPrice, err = valueobject.NewPrice(item.ListPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var campaign valueobject.Name
+	if item.Campaign != "" {
+		campaign, err = valueobject.NewName(item.Campaign)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var coupon valueobject.Code
+	var discount valueobject.Price
+	if item.Coupon != "" {
+		coupon, err = valueobject.NewCode(item.Coupon)
+		if err != nil {
+			return nil, err
+		}
+
+		discount, err = valueobject.NewPrice(item.Discount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var region valueobject.Code
+	if item.Region != "" {
+		region, err = valueobject.NewCode(item.Region)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	backordered, err := valueobject.NewStock(item.Backordered)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := valueobject.NewOrderStatus(item.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []allocation
+	err = json.Unmarshal([]byte(item.Allocations), &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []entity.Allocation
+	for _, value := range stored {
+		warehouse, err := valueobject.NewCode(value.Warehouse)
+		if err != nil {
+			return nil, err
+		}
+
+		var product valueobject.Code
+		if value.Product != "" {
+			product, err = valueobject.NewCode(value.Product)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		allocations = append(allocations, entity.Allocation{Warehouse: warehouse, Quantity: value.Quantity, Product: product})
+	}
+
+	var createdAt time.Time
+	if item.CreatedAt.Valid {
+		createdAt = time.Unix(item.CreatedAt.Int64, 0).UTC()
+	}
+
+	return &entity.Order{
+		ID:          id,
+		ProductID:   productID,
+		Quantity:    quantity,
+		Price:       price,
+		ListPrice:   listPrice,
+		TierPercent: item.TierPercent,
+		Campaign:    campaign,
+		Coupon:      coupon,
+		Discount:    discount,
+		Region:      region,
+		TaxRate:     item.TaxRate,
+		Tax:         item.Tax,
+		Backordered: backordered,
+		Status:      status,
+		CreatedAt:   createdAt,
+		Allocations: allocations,
+		Version:     item.Version,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/order"
+	"github.com/aaydin-tr/e-commerce/domain/order/ordertest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteOrderRepository(t *testing.T) {
+	ordertest.RunRepositorySuite(t, func(t *testing.T) order.OrderRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewOrderRepository(db, storage.New[*entity.Order]())
+		require.NoError(t, err)
+		return repository
+	})
+}
+
+func TestOrderRepositoryFlush(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository, err := NewOrderRepository(db, storage.New[*entity.Order]())
+	require.NoError(t, err)
+
+	quantity, _ := valueobject.NewQuantity(3)
+	price, _ := valueobject.NewPrice(90)
+	listPrice, _ := valueobject.NewPrice(100)
+	campaign, _ := valueobject.NewName("C1")
+	coupon, _ := valueobject.NewCode("SAVE10")
+	discount, _ := valueobject.NewPrice(27)
+	region, _ := valueobject.NewCode("EU")
+	paid, _ := valueobject.NewOrderStatus(valueobject.Paid)
+	placed, _ := valueobject.NewOrderStatus(valueobject.Placed)
+	createdAt := time.Date(0, 0, 0, 5, 0, 0, 0, time.UTC)
+	backordered, _ := valueobject.NewStock(2)
+	warehouse, _ := valueobject.NewCode("W1")
+	component, _ := valueobject.NewCode("P1")
+	allocations := []entity.Allocation{{Warehouse: warehouse, Quantity: 1}, {Warehouse: warehouse, Quantity: 2, Product: component}}
+	first := &entity.Order{ID: uuid.New(), ProductID: uuid.New(), Quantity: quantity, Price: price, ListPrice: listPrice, TierPercent: 10, Campaign: campaign, Coupon: coupon, Discount: discount, Region: region, TaxRate: 20, Tax: 48.6, Backordered: backordered, Status: paid, CreatedAt: createdAt, Allocations: allocations}
+	repository.Create(first)
+	repository.Create(&entity.Order{ID: uuid.New(), ProductID: uuid.New(), Quantity: quantity, Status: placed})
+
+	err = database.Flush(db, repository)
+	assert.NoError(t, err)
+
+	loaded, err := NewOrderRepository(db, storage.New[*entity.Order]())
+	require.NoError(t, err)
+	assert.Len(t, loaded.GetAll(), 2)
+
+	repository.Clear()
+	repository.Create(first)
+	err = database.Flush(db, repository)
+	assert.NoError(t, err)
+
+	loaded, err = NewOrderRepository(db, storage.New[*entity.Order]())
+	require.NoError(t, err)
+	orders := loaded.GetAll()
+	assert.Len(t, orders, 1)
+	assert.Equal(t, first.ID, orders[0].ID)
+	assert.Equal(t, first.ProductID, orders[0].ProductID)
+	assert.Equal(t, first.Quantity, orders[0].Quantity)
+	assert.Equal(t, first.Price, orders[0].Price)
+	assert.Equal(t, first.ListPrice, orders[0].ListPrice)
+	assert.Equal(t, first.TierPercent, orders[0].TierPercent)
+	assert.Equal(t, first.Campaign, orders[0].Campaign)
+	assert.Equal(t, first.Coupon, orders[0].Coupon)
+	assert.Equal(t, first.Discount, orders[0].Discount)
+	assert.Equal(t, first.Region, orders[0].Region)
+	assert.Equal(t, first.TaxRate, orders[0].TaxRate)
+	assert.Equal(t, first.Tax, orders[0].Tax)
+	assert.Equal(t, first.Backordered, orders[0].Backordered)
+	assert.Equal(t, first.Status, orders[0].Status)
+	assert.Equal(t, first.CreatedAt, orders[0].CreatedAt)
+	assert.Equal(t, first.Allocations, orders[0].Allocations)
+}
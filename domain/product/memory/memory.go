@@ -1,17 +1,28 @@
 package memory
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/aaydin-tr/e-commerce/domain/product"
 	"github.com/aaydin-tr/e-commerce/entity"
 	"github.com/aaydin-tr/e-commerce/types"
 	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
 )
 
 type ProductRepository struct {
 	storage types.Storage[*entity.Product]
+	mu      sync.Mutex
 }
 
+const idIndex = "id"
+
 func NewProductRepository(storage types.Storage[*entity.Product]) *ProductRepository {
+	storage.AddIndex(idIndex, func(item *entity.Product) string {
+		return item.ID.String()
+	})
+
 	return &ProductRepository{storage: storage}
 }
 
@@ -24,7 +35,18 @@ func (r *ProductRepository) Get(code valueobject.Code) (*entity.Product, error)
 	return result, nil
 }
 
+func (r *ProductRepository) GetByID(id uuid.UUID) (*entity.Product, error) {
+	result := r.storage.GetBy(idIndex, id.String())
+	if len(result) == 0 {
+		return nil, product.ErrNotFound
+	}
+
+	return result[0], nil
+}
+
 func (r *ProductRepository) Create(newProduct *entity.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	_, ok := r.storage.Get(newProduct.Code.Value())
 	if ok {
 		return product.ErrAlreadyExist
@@ -33,3 +55,41 @@ func (r *ProductRepository) Create(newProduct *entity.Product) error {
 	r.storage.Set(newProduct.Code.Value(), newProduct)
 	return nil
 }
+
+func (r *ProductRepository) GetAll() []*entity.Product {
+	var result []*entity.Product
+	for _, item := range r.storage.Values() {
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code.Value() < result[j].Code.Value()
+	})
+
+	return result
+}
+
+func (r *ProductRepository) Update(updatedProduct *entity.Product, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.storage.Get(updatedProduct.Code.Value())
+	if !ok {
+		return product.ErrNotFound
+	}
+
+	if current.Version != version {
+		return &types.VersionConflictError{Aggregate: "product", Key: updatedProduct.Code.Value(), Expected: version, Actual: current.Version}
+	}
+
+	updatedProduct.Version = version + 1
+	r.storage.Set(updatedProduct.Code.Value(), updatedProduct)
+	return nil
+}
+
+func (r *ProductRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
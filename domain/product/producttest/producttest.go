@@ -0,0 +1,157 @@
+// Package producttest provides the contract every product.ProductRepository
+// implementation must satisfy.
+package producttest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) product.ProductRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetByID", func(t *testing.T) { testGetByID(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func newProduct(code string) *entity.Product {
+	productCode, _ := valueobject.NewCode(code)
+	return &entity.Product{ID: uuid.New(), Code: productCode}
+}
+
+func testCreate(t *testing.T, repository product.ProductRepository) {
+	stored := newProduct("P1")
+
+	t.Run("Create product", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create product which already exist", func(t *testing.T) {
+		err := repository.Create(newProduct("P1"))
+		assert.ErrorIs(t, err, product.ErrAlreadyExist)
+
+		result, err := repository.Get(stored.Code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func testGet(t *testing.T, repository product.ProductRepository) {
+	stored := newProduct("P1")
+	repository.Create(stored)
+
+	t.Run("Get product", func(t *testing.T) {
+		code, _ := valueobject.NewCode("P1")
+
+		result, err := repository.Get(code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get product which not exist", func(t *testing.T) {
+		code, _ := valueobject.NewCode("P2")
+
+		result, err := repository.Get(code)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetByID(t *testing.T, repository product.ProductRepository) {
+	stored := newProduct("P1")
+	repository.Create(stored)
+	repository.Create(newProduct("P2"))
+
+	t.Run("Get product by ID", func(t *testing.T) {
+		result, err := repository.GetByID(stored.ID)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get product by ID which not exist", func(t *testing.T) {
+		result, err := repository.GetByID(uuid.New())
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("Get product by ID after clear", func(t *testing.T) {
+		repository.Clear()
+		_, err := repository.GetByID(stored.ID)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+}
+
+func testGetAll(t *testing.T, repository product.ProductRepository) {
+	t.Run("Get all products of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all products ordered by code", func(t *testing.T) {
+		for _, code := range []string{"P3", "P1", "P2"} {
+			repository.Create(newProduct(code))
+		}
+
+		var codes []string
+		for _, item := range repository.GetAll() {
+			codes = append(codes, item.Code.Value())
+		}
+		assert.Equal(t, []string{"P1", "P2", "P3"}, codes)
+	})
+}
+
+func testUpdate(t *testing.T, repository product.ProductRepository) {
+	stored := newProduct("P1")
+	repository.Create(stored)
+
+	t.Run("Update product", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update product with stale version", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+
+		var conflictErr *types.VersionConflictError
+		assert.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "product", conflictErr.Aggregate)
+		assert.Equal(t, "P1", conflictErr.Key)
+		assert.Equal(t, 0, conflictErr.Expected)
+		assert.Equal(t, 1, conflictErr.Actual)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update product which not exist", func(t *testing.T) {
+		err := repository.Update(newProduct("P2"), 0)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+}
+
+func testClear(t *testing.T, repository product.ProductRepository) {
+	repository.Create(newProduct("P1"))
+	repository.Create(newProduct("P2"))
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	code, _ := valueobject.NewCode("P1")
+	_, err := repository.Get(code)
+	assert.ErrorIs(t, err, product.ErrNotFound)
+
+	t.Run("Create product after clear", func(t *testing.T) {
+		err := repository.Create(newProduct("P1"))
+		assert.NoError(t, err)
+	})
+}
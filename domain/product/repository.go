@@ -5,6 +5,7 @@ import (
 
 	"github.com/aaydin-tr/e-commerce/entity"
 	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
 )
 
 var (
@@ -15,5 +16,12 @@ var (
 //go:generate mockgen -destination=../../mock/repository/product/product.go -package=repository github.com/aaydin-tr/e-commerce/domain/product ProductRepository
 type ProductRepository interface {
 	Get(code valueobject.Code) (*entity.Product, error)
+	GetByID(id uuid.UUID) (*entity.Product, error)
 	Create(product *entity.Product) error
+	// GetAll returns every product ordered by code.
+	GetAll() []*entity.Product
+	Clear()
+	// Update stores product if the stored version still equals version and
+	// increments it, otherwise it returns a *types.VersionConflictError.
+	Update(product *entity.Product, version int) error
 }
@@ -0,0 +1,471 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/product/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE products (
+		id TEXT NOT NULL,
+		code TEXT PRIMARY KEY,
+		price REAL NOT NULL,
+		stock INTEGER NOT NULL,
+		campaign TEXT,
+		version INTEGER NOT NULL,
+		initial_stock INTEGER NOT NULL,
+		initial_price REAL NOT NULL,
+		total_demand_count INTEGER NOT NULL
+	)`,
+	`ALTER TABLE products ADD COLUMN backorder_limit INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE products ADD COLUMN backordered INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE products ADD COLUMN backorder_queue TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE products ADD COLUMN launch_at INTEGER`,
+	`ALTER TABLE products ADD COLUMN inventory TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE products ADD COLUMN components TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE products ADD COLUMN variants TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE products ADD COLUMN parent TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE products ADD COLUMN attributes TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE products ADD COLUMN price_override INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE products ADD COLUMN category TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE products ADD COLUMN price_tiers TEXT NOT NULL DEFAULT '[]'`,
+	`ALTER TABLE products ADD COLUMN tax_class TEXT NOT NULL DEFAULT ''`,
+}
+
+// ProductRepository keeps products in memory, so every Get returns the same
+// entity, and writes them to the database on Flush. The campaign of a product
+// is stored by name and linked by the campaign repository.
+type ProductRepository struct {
+	*memory.ProductRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Product]
+	persisted map[string]row
+}
+
+type row struct {
+	ID               string
+	Code             string
+	Price            float64
+	Stock            int
+	Campaign         sql.NullString
+	Version          int
+	InitialStock     int
+	InitialPrice     float64
+	TotalDemandCount int
+	BackorderLimit   int
+	Backordered      int
+	// BackorderQueue holds the queued order IDs as JSON, so rows stay
+	// comparable. LaunchAt is in Unix seconds and NULL when the product is on
+	// sale from the start.
+	BackorderQueue string
+	LaunchAt       sql.NullInt64
+	// Inventory holds the warehouse stock levels, Components the bundle
+	// components, Variants the variant codes, Attributes the variant
+	// attributes and PriceTiers the volume tiers as JSON.
+	Inventory     string
+	Components    string
+	Variants      string
+	Parent        string
+	Attributes    string
+	PriceOverride bool
+	Category      string
+	PriceTiers    string
+	TaxClass      string
+}
+
+type warehouseStock struct {
+	Warehouse string `json:"warehouse"`
+	Stock     int    `json:"stock"`
+}
+
+type component struct {
+	Product  string `json:"product"`
+	Quantity int    `json:"quantity"`
+}
+
+type attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type priceTier struct {
+	MinQuantity int     `json:"min_quantity"`
+	Percent     float64 `json:"percent"`
+}
+
+func NewProductRepository(db *sql.DB, storage types.Storage[*entity.Product]) (*ProductRepository, error) {
+	err := database.Migrate(db, "products", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ProductRepository{
+		ProductRepository: memory.NewProductRepository(storage),
+		db:                db,
+		storage:           storage,
+		persisted:         make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *ProductRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, code, price, stock, campaign, version, initial_stock, initial_price, total_demand_count, backorder_limit, backordered, backorder_queue, launch_at, inventory, components, variants, parent, attributes, price_override, category, price_tiers, tax_class FROM products`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Code, &item.Price, &item.Stock, &item.Campaign, &item.Version, &item.InitialStock, &item.InitialPrice, &item.TotalDemandCount, &item.BackorderLimit, &item.Backordered, &item.BackorderQueue, &item.LaunchAt, &item.Inventory, &item.Components, &item.Variants, &item.Parent, &item.Attributes, &item.PriceOverride, &item.Category, &item.PriceTiers, &item.TaxClass)
+		if err != nil {
+			return err
+		}
+
+		product, err := item.toProduct()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.Code, product)
+		r.persisted[item.Code] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *ProductRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, product := range r.storage.Values() {
+		product.Acquire()
+		item, err := newRow(product)
+		product.Release()
+		if err != nil {
+			return nil, err
+		}
+		current[product.Code.Value()] = item
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO products (id, code, price, stock, campaign, version, initial_stock, initial_price, total_demand_count, backorder_limit, backordered, backorder_queue, launch_at, inventory, components, variants, parent, attributes, price_override, category, price_tiers, tax_class) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (code) DO UPDATE SET id = excluded.id, price = excluded.price, stock = excluded.stock, campaign = excluded.campaign, version = excluded.version,
+			initial_stock = excluded.initial_stock, initial_price = excluded.initial_price, total_demand_count = excluded.total_demand_count,
+			backorder_limit = excluded.backorder_limit, backordered = excluded.backordered, backorder_queue = excluded.backorder_queue, launch_at = excluded.launch_at, inventory = excluded.inventory, components = excluded.components,
+			variants = excluded.variants, parent = excluded.parent, attributes = excluded.attributes, price_override = excluded.price_override, category = excluded.category, price_tiers = excluded.price_tiers, tax_class = excluded.tax_class`,
+			item.ID, item.Code, item.Price, item.Stock, item.Campaign, item.Version, item.InitialStock, item.InitialPrice, item.TotalDemandCount,
+			item.BackorderLimit, item.Backordered, item.BackorderQueue, item.LaunchAt, item.Inventory, item.Components, item.Variants, item.Parent, item.Attributes, item.PriceOverride, item.Category, item.PriceTiers, item.TaxClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, code := range deletes {
+		_, err := tx.Exec(`DELETE FROM products WHERE code = ?`, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(product *entity.Product) (row, error) {
+	queue := make([]string, 0, len(product.BackorderQueue))
+	for _, id := range product.BackorderQueue {
+		queue = append(queue, id.String())
+	}
+
+	encoded, err := json.Marshal(queue)
+	if err != nil {
+		return row{}, err
+	}
+
+	inventory := make([]warehouseStock, 0, len(product.Inventory))
+	for _, item := range product.Inventory {
+		inventory = append(inventory, warehouseStock{Warehouse: item.Warehouse.Value(), Stock: item.Stock.Value()})
+	}
+
+	encodedInventory, err := json.Marshal(inventory)
+	if err != nil {
+		return row{}, err
+	}
+
+	components := make([]component, 0, len(product.Components))
+	for _, item := range product.Components {
+		components = append(components, component{Product: item.Product.Value(), Quantity: item.Quantity})
+	}
+
+	encodedComponents, err := json.Marshal(components)
+	if err != nil {
+		return row{}, err
+	}
+
+	variants := make([]string, 0, len(product.Variants))
+	for _, code := range product.Variants {
+		variants = append(variants, code.Value())
+	}
+
+	encodedVariants, err := json.Marshal(variants)
+	if err != nil {
+		return row{}, err
+	}
+
+	attributes := make([]attribute, 0, len(product.Attributes))
+	for _, item := range product.Attributes {
+		attributes = append(attributes, attribute{Name: item.Name, Value: item.Value})
+	}
+
+	encodedAttributes, err := json.Marshal(attributes)
+	if err != nil {
+		return row{}, err
+	}
+
+	tiers := make([]priceTier, 0, len(product.PriceTiers))
+	for _, item := range product.PriceTiers {
+		tiers = append(tiers, priceTier{MinQuantity: item.MinQuantity, Percent: item.Percent})
+	}
+
+	encodedTiers, err := json.Marshal(tiers)
+	if err != nil {
+		return row{}, err
+	}
+
+	item := row{
+		ID:               product.ID.String(),
+		Code:             product.Code.Value(),
+		Price:            product.Price.Value(),
+		Stock:            product.Stock.Value(),
+		Version:          product.Version,
+		InitialStock:     product.InititalStock.Value(),
+		InitialPrice:     product.InititalPrice.Value(),
+		TotalDemandCount: product.TotalDemandCount.Value(),
+		BackorderLimit:   product.BackorderLimit.Value(),
+		Backordered:      product.Backordered.Value(),
+		BackorderQueue:   string(encoded),
+		Inventory:        string(encodedInventory),
+		Components:       string(encodedComponents),
+		Variants:         string(encodedVariants),
+		Parent:           product.Parent.Value(),
+		Attributes:       string(encodedAttributes),
+		PriceOverride:    product.PriceOverride,
+		Category:         product.Category.Value(),
+		PriceTiers:       string(encodedTiers),
+		TaxClass:         product.TaxClass.Value(),
+	}
+
+	if product.Campaign != nil {
+		item.Campaign = sql.NullString{String: product.Campaign.Name.Value(), Valid: true}
+	}
+
+	if !product.LaunchAt.IsZero() {
+		item.LaunchAt = sql.NullInt64{Int64: product.LaunchAt.Unix(), Valid: true}
+	}
+
+	return item, nil
+}
+
+func (item row) toProduct() (*entity.Product, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(item.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := valueobject.NewPrice(item.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := valueobject.NewStock(item.Stock)
+	if err != nil {
+		return nil, err
+	}
+
+	initialStock, err := valueobject.NewStock(item.InitialStock)
+	if err != nil {
+		return nil, err
+	}
+
+	initialPrice, err := valueobject.NewPrice(item.InitialPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	totalDemandCount, err := valueobject.NewDemand(item.TotalDemandCount)
+	if err != nil {
+		return nil, err
+	}
+
+	backorderLimit, err := valueobject.NewStock(item.BackorderLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	backordered, err := valueobject.NewStock(item.Backordered)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue []string
+	err = json.Unmarshal([]byte(item.BackorderQueue), &queue)
+	if err != nil {
+		return nil, err
+	}
+
+	var backorderQueue []uuid.UUID
+	for _, value := range queue {
+		orderID, err := uuid.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+
+		backorderQueue = append(backorderQueue, orderID)
+	}
+
+	var stored []warehouseStock
+	err = json.Unmarshal([]byte(item.Inventory), &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	var inventory []entity.WarehouseStock
+	for _, value := range stored {
+		warehouse, err := valueobject.NewCode(value.Warehouse)
+		if err != nil {
+			return nil, err
+		}
+
+		warehouseStock, err := valueobject.NewStock(value.Stock)
+		if err != nil {
+			return nil, err
+		}
+
+		inventory = append(inventory, entity.WarehouseStock{Warehouse: warehouse, Stock: warehouseStock})
+	}
+
+	var storedComponents []component
+	err = json.Unmarshal([]byte(item.Components), &storedComponents)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []entity.BundleComponent
+	for _, value := range storedComponents {
+		product, err := valueobject.NewCode(value.Product)
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, entity.BundleComponent{Product: product, Quantity: value.Quantity})
+	}
+
+	var storedVariants []string
+	err = json.Unmarshal([]byte(item.Variants), &storedVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []valueobject.Code
+	for _, value := range storedVariants {
+		variant, err := valueobject.NewCode(value)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, variant)
+	}
+
+	var parent valueobject.Code
+	if item.Parent != "" {
+		parent, err = valueobject.NewCode(item.Parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var category valueobject.Code
+	if item.Category != "" {
+		category, err = valueobject.NewCode(item.Category)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var storedAttributes []attribute
+	err = json.Unmarshal([]byte(item.Attributes), &storedAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes []entity.VariantAttribute
+	for _, value := range storedAttributes {
+		attributes = append(attributes, entity.VariantAttribute{Name: value.Name, Value: value.Value})
+	}
+
+	var taxClass valueobject.Code
+	if item.TaxClass != "" {
+		taxClass, err = valueobject.NewCode(item.TaxClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var storedTiers []priceTier
+	err = json.Unmarshal([]byte(item.PriceTiers), &storedTiers)
+	if err != nil {
+		return nil, err
+	}
+
+	var priceTiers []entity.PriceTier
+	for _, value := range storedTiers {
+		priceTiers = append(priceTiers, entity.PriceTier{MinQuantity: value.MinQuantity, Percent: value.Percent})
+	}
+
+	var launchAt time.Time
+	if item.LaunchAt.Valid {
+		launchAt = time.Unix(item.LaunchAt.Int64, 0).UTC()
+	}
+
+	return &entity.Product{
+		ID:               id,
+		Code:             code,
+		Price:            price,
+		Stock:            stock,
+		Version:          item.Version,
+		InititalStock:    initialStock,
+		InititalPrice:    initialPrice,
+		TotalDemandCount: totalDemandCount,
+		BackorderLimit:   backorderLimit,
+		Backordered:      backordered,
+		BackorderQueue:   backorderQueue,
+		LaunchAt:         launchAt,
+		Inventory:        inventory,
+		Components:       components,
+		Variants:         variants,
+		Parent:           parent,
+		Attributes:       attributes,
+		PriceOverride:    item.PriceOverride,
+		Category:         category,
+		PriceTiers:       priceTiers,
+		TaxClass:         taxClass,
+	}, nil
+}
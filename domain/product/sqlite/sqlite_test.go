@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/domain/product/producttest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteProductRepository(t *testing.T) {
+	producttest.RunRepositorySuite(t, func(t *testing.T) product.ProductRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewProductRepository(db, storage.New[*entity.Product]())
+		require.NoError(t, err)
+		return repository
+	})
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+type RegionRepository struct {
+	storage types.Storage[*entity.Region]
+	mu      sync.Mutex
+}
+
+func NewRegionRepository(storage types.Storage[*entity.Region]) *RegionRepository {
+	return &RegionRepository{storage: storage}
+}
+
+func (r *RegionRepository) Create(newRegion *entity.Region) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.storage.Get(newRegion.Code.Value())
+	if ok {
+		return region.ErrAlreadyExist
+	}
+
+	r.storage.Set(newRegion.Code.Value(), newRegion)
+	return nil
+}
+
+func (r *RegionRepository) Get(code valueobject.Code) (*entity.Region, error) {
+	result, ok := r.storage.Get(code.Value())
+	if !ok {
+		return nil, region.ErrNotFound
+	}
+
+	return result, nil
+}
+
+func (r *RegionRepository) GetAll() []*entity.Region {
+	var result []*entity.Region
+	for _, item := range r.storage.Values() {
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code.Value() < result[j].Code.Value()
+	})
+
+	return result
+}
+
+func (r *RegionRepository) Update(updatedRegion *entity.Region, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.storage.Get(updatedRegion.Code.Value())
+	if !ok {
+		return region.ErrNotFound
+	}
+
+	if current.Version != version {
+		return &types.VersionConflictError{Aggregate: "region", Key: updatedRegion.Code.Value(), Expected: version, Actual: current.Version}
+	}
+
+	updatedRegion.Version = version + 1
+	r.storage.Set(updatedRegion.Code.Value(), updatedRegion)
+	return nil
+}
+
+func (r *RegionRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
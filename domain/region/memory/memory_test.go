@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	"github.com/aaydin-tr/e-commerce/domain/region/regiontest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+)
+
+func TestMemoryRegionRepository(t *testing.T) {
+	regiontest.RunRepositorySuite(t, func(t *testing.T) region.RegionRepository {
+		return NewRegionRepository(storage.New[*entity.Region]())
+	})
+}
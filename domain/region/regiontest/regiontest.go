@@ -0,0 +1,133 @@
+// Package regiontest provides the contract every
+// region.RegionRepository implementation must satisfy.
+package regiontest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) region.RegionRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func newRegion(code string) *entity.Region {
+	regionCode, _ := valueobject.NewCode(code)
+	return &entity.Region{ID: uuid.New(), Code: regionCode}
+}
+
+func testCreate(t *testing.T, repository region.RegionRepository) {
+	stored := newRegion("R1")
+
+	t.Run("Create region", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create region which already exist", func(t *testing.T) {
+		err := repository.Create(newRegion("R1"))
+		assert.ErrorIs(t, err, region.ErrAlreadyExist)
+
+		result, err := repository.Get(stored.Code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func testGet(t *testing.T, repository region.RegionRepository) {
+	stored := newRegion("R1")
+	repository.Create(stored)
+
+	t.Run("Get region", func(t *testing.T) {
+		code, _ := valueobject.NewCode("R1")
+
+		result, err := repository.Get(code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get region which not exist", func(t *testing.T) {
+		code, _ := valueobject.NewCode("R2")
+
+		result, err := repository.Get(code)
+		assert.ErrorIs(t, err, region.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetAll(t *testing.T, repository region.RegionRepository) {
+	t.Run("Get all regions of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all regions ordered by code", func(t *testing.T) {
+		for _, code := range []string{"R3", "R1", "R2"} {
+			repository.Create(newRegion(code))
+		}
+
+		var codes []string
+		for _, item := range repository.GetAll() {
+			codes = append(codes, item.Code.Value())
+		}
+		assert.Equal(t, []string{"R1", "R2", "R3"}, codes)
+	})
+}
+
+func testUpdate(t *testing.T, repository region.RegionRepository) {
+	stored := newRegion("R1")
+	repository.Create(stored)
+
+	t.Run("Update region", func(t *testing.T) {
+		class, _ := valueobject.NewCode(entity.StandardTaxClass)
+		stored.SetRate(class, 20)
+		err := repository.Update(stored, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stored.Version)
+	})
+
+	t.Run("Update region with stale version", func(t *testing.T) {
+		err := repository.Update(stored, 0)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+
+		var conflictErr *types.VersionConflictError
+		assert.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "region", conflictErr.Aggregate)
+		assert.Equal(t, "R1", conflictErr.Key)
+		assert.Equal(t, 0, conflictErr.Expected)
+		assert.Equal(t, 1, conflictErr.Actual)
+	})
+
+	t.Run("Update region which not exist", func(t *testing.T) {
+		err := repository.Update(newRegion("R2"), 0)
+		assert.ErrorIs(t, err, region.ErrNotFound)
+	})
+}
+
+func testClear(t *testing.T, repository region.RegionRepository) {
+	repository.Create(newRegion("R1"))
+	repository.Create(newRegion("R2"))
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	code, _ := valueobject.NewCode("R1")
+	_, err := repository.Get(code)
+	assert.ErrorIs(t, err, region.ErrNotFound)
+
+	t.Run("Create region after clear", func(t *testing.T) {
+		err := repository.Create(newRegion("R1"))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+package region
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+var (
+	ErrNotFound     = errors.New("Region not found")
+	ErrAlreadyExist = errors.New("Region already exist")
+)
+
+//go:generate mockgen -destination=../../mock/repository/region/region.go -package=repository github.com/aaydin-tr/e-commerce/domain/region RegionRepository
+type RegionRepository interface {
+	Create(region *entity.Region) error
+	Get(code valueobject.Code) (*entity.Region, error)
+	// GetAll returns every region ordered by code.
+	GetAll() []*entity.Region
+	// Update stores region if the stored version still equals version and
+	// increments it, otherwise it returns a *types.VersionConflictError.
+	Update(region *entity.Region, version int) error
+	Clear()
+}
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/aaydin-tr/e-commerce/domain/region/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE regions (
+		id TEXT NOT NULL,
+		code TEXT PRIMARY KEY,
+		rates TEXT NOT NULL,
+		version INTEGER NOT NULL
+	)`,
+}
+
+// RegionRepository keeps regions in memory and writes them to the database on
+// Flush.
+type RegionRepository struct {
+	*memory.RegionRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Region]
+	persisted map[string]row
+}
+
+// row keeps the tax rates as JSON, so rows stay comparable.
+type row struct {
+	ID      string
+	Code    string
+	Rates   string
+	Version int
+}
+
+type taxRate struct {
+	Class   string  `json:"class"`
+	Percent float64 `json:"percent"`
+}
+
+func NewRegionRepository(db *sql.DB, storage types.Storage[*entity.Region]) (*RegionRepository, error) {
+	err := database.Migrate(db, "regions", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RegionRepository{
+		RegionRepository: memory.NewRegionRepository(storage),
+		db:               db,
+		storage:          storage,
+		persisted:        make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *RegionRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, code, rates, version FROM regions`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Code, &item.Rates, &item.Version)
+		if err != nil {
+			return err
+		}
+
+		region, err := item.toRegion()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.Code, region)
+		r.persisted[item.Code] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *RegionRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, region := range r.storage.Values() {
+		item, err := newRow(region)
+		if err != nil {
+			return nil, err
+		}
+		current[region.Code.Value()] = item
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO regions (id, code, rates, version) VALUES (?, ?, ?, ?)
+			ON CONFLICT (code) DO UPDATE SET id = excluded.id, rates = excluded.rates, version = excluded.version`,
+			item.ID, item.Code, item.Rates, item.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, code := range deletes {
+		_, err := tx.Exec(`DELETE FROM regions WHERE code = ?`, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(region *entity.Region) (row, error) {
+	rates := make([]taxRate, 0, len(region.Rates))
+	for _, item := range region.Rates {
+		rates = append(rates, taxRate{Class: item.Class.Value(), Percent: item.Percent})
+	}
+
+	encoded, err := json.Marshal(rates)
+	if err != nil {
+		return row{}, err
+	}
+
+	return row{
+		ID:      region.ID.String(),
+		Code:    region.Code.Value(),
+		Rates:   string(encoded),
+		Version: region.Version,
+	}, nil
+}
+
+func (item row) toRegion() (*entity.Region, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(item.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored []taxRate
+	err = json.Unmarshal([]byte(item.Rates), &stored)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []entity.TaxRate
+	for _, value := range stored {
+		class, err := valueobject.NewCode(value.Class)
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, entity.TaxRate{Class: class, Percent: value.Percent})
+	}
+
+	return &entity.Region{
+		ID:      id,
+		Code:    code,
+		Rates:   rates,
+		Version: item.Version,
+	}, nil
+}
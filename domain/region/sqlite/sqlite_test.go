@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	"github.com/aaydin-tr/e-commerce/domain/region/regiontest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteRegionRepository(t *testing.T) {
+	regiontest.RunRepositorySuite(t, func(t *testing.T) region.RegionRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewRegionRepository(db, storage.New[*entity.Region]())
+		require.NoError(t, err)
+		return repository
+	})
+}
+
+func TestRegionRepositoryFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := database.Open(path)
+	require.NoError(t, err)
+
+	repository, err := NewRegionRepository(db, storage.New[*entity.Region]())
+	require.NoError(t, err)
+
+	code, _ := valueobject.NewCode("EU")
+	reduced, _ := valueobject.NewCode("REDUCED")
+	standard, _ := valueobject.NewCode(entity.StandardTaxClass)
+	stored := &entity.Region{
+		ID:    uuid.New(),
+		Code:  code,
+		Rates: []entity.TaxRate{{Class: reduced, Percent: 7}, {Class: standard, Percent: 19}},
+	}
+	require.NoError(t, repository.Create(stored))
+	require.NoError(t, database.Flush(db, repository))
+	require.NoError(t, db.Close())
+
+	db, err = database.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	reopened, err := NewRegionRepository(db, storage.New[*entity.Region]())
+	require.NoError(t, err)
+
+	loaded, err := reopened.Get(code)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, loaded)
+}
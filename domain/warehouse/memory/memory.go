@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+type WarehouseRepository struct {
+	storage types.Storage[*entity.Warehouse]
+	mu      sync.Mutex
+}
+
+func NewWarehouseRepository(storage types.Storage[*entity.Warehouse]) *WarehouseRepository {
+	return &WarehouseRepository{storage: storage}
+}
+
+func (r *WarehouseRepository) Create(newWarehouse *entity.Warehouse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.storage.Get(newWarehouse.Code.Value())
+	if ok {
+		return warehouse.ErrAlreadyExist
+	}
+
+	r.storage.Set(newWarehouse.Code.Value(), newWarehouse)
+	return nil
+}
+
+func (r *WarehouseRepository) Get(code valueobject.Code) (*entity.Warehouse, error) {
+	result, ok := r.storage.Get(code.Value())
+	if !ok {
+		return nil, warehouse.ErrNotFound
+	}
+
+	return result, nil
+}
+
+func (r *WarehouseRepository) GetAll() []*entity.Warehouse {
+	var result []*entity.Warehouse
+	for _, item := range r.storage.Values() {
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code.Value() < result[j].Code.Value()
+	})
+
+	return result
+}
+
+func (r *WarehouseRepository) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.storage.Keys() {
+		r.storage.Delete(key)
+	}
+}
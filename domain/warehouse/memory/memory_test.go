@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/domain/warehouse/warehousetest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+)
+
+func TestMemoryWarehouseRepository(t *testing.T) {
+	warehousetest.RunRepositorySuite(t, func(t *testing.T) warehouse.WarehouseRepository {
+		return NewWarehouseRepository(storage.New[*entity.Warehouse]())
+	})
+}
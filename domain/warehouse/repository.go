@@ -0,0 +1,22 @@
+package warehouse
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+var (
+	ErrNotFound     = errors.New("Warehouse not found")
+	ErrAlreadyExist = errors.New("Warehouse already exist")
+)
+
+//go:generate mockgen -destination=../../mock/repository/warehouse/warehouse.go -package=repository github.com/aaydin-tr/e-commerce/domain/warehouse WarehouseRepository
+type WarehouseRepository interface {
+	Create(warehouse *entity.Warehouse) error
+	Get(code valueobject.Code) (*entity.Warehouse, error)
+	// GetAll returns every warehouse ordered by code.
+	GetAll() []*entity.Warehouse
+	Clear()
+}
@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	"github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var migrations = []string{
+	`CREATE TABLE warehouses (
+		id TEXT NOT NULL,
+		code TEXT PRIMARY KEY,
+		x REAL NOT NULL,
+		y REAL NOT NULL,
+		version INTEGER NOT NULL
+	)`,
+}
+
+// WarehouseRepository keeps warehouses in memory and writes them to the
+// database on Flush.
+type WarehouseRepository struct {
+	*memory.WarehouseRepository
+	db        *sql.DB
+	storage   types.Storage[*entity.Warehouse]
+	persisted map[string]row
+}
+
+type row struct {
+	ID      string
+	Code    string
+	X       float64
+	Y       float64
+	Version int
+}
+
+func NewWarehouseRepository(db *sql.DB, storage types.Storage[*entity.Warehouse]) (*WarehouseRepository, error) {
+	err := database.Migrate(db, "warehouses", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &WarehouseRepository{
+		WarehouseRepository: memory.NewWarehouseRepository(storage),
+		db:                  db,
+		storage:             storage,
+		persisted:           make(map[string]row),
+	}
+
+	err = r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *WarehouseRepository) load() error {
+	rows, err := r.db.Query(`SELECT id, code, x, y, version FROM warehouses`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item row
+		err = rows.Scan(&item.ID, &item.Code, &item.X, &item.Y, &item.Version)
+		if err != nil {
+			return err
+		}
+
+		warehouse, err := item.toWarehouse()
+		if err != nil {
+			return err
+		}
+
+		r.storage.Set(item.Code, warehouse)
+		r.persisted[item.Code] = item
+	}
+
+	return rows.Err()
+}
+
+func (r *WarehouseRepository) Flush(tx *sql.Tx) (func(), error) {
+	current := make(map[string]row)
+	for _, warehouse := range r.storage.Values() {
+		current[warehouse.Code.Value()] = newRow(warehouse)
+	}
+
+	upserts, deletes := database.Changes(r.persisted, current)
+	for _, item := range upserts {
+		_, err := tx.Exec(`INSERT INTO warehouses (id, code, x, y, version) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (code) DO UPDATE SET id = excluded.id, x = excluded.x, y = excluded.y, version = excluded.version`,
+			item.ID, item.Code, item.X, item.Y, item.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, code := range deletes {
+		_, err := tx.Exec(`DELETE FROM warehouses WHERE code = ?`, code)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { r.persisted = current }, nil
+}
+
+func newRow(warehouse *entity.Warehouse) row {
+	return row{
+		ID:      warehouse.ID.String(),
+		Code:    warehouse.Code.Value(),
+		X:       warehouse.Location.X(),
+		Y:       warehouse.Location.Y(),
+		Version: warehouse.Version,
+	}
+}
+
+func (item row) toWarehouse() (*entity.Warehouse, error) {
+	id, err := uuid.Parse(item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(item.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := valueobject.NewLocation(item.X, item.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Warehouse{
+		ID:       id,
+		Code:     code,
+		Location: location,
+		Version:  item.Version,
+	}, nil
+}
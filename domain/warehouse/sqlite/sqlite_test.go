@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/domain/warehouse/warehousetest"
+	"github.com/aaydin-tr/e-commerce/entity"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteWarehouseRepository(t *testing.T) {
+	warehousetest.RunRepositorySuite(t, func(t *testing.T) warehouse.WarehouseRepository {
+		db, err := database.Open(filepath.Join(t.TempDir(), "store.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		repository, err := NewWarehouseRepository(db, storage.New[*entity.Warehouse]())
+		require.NoError(t, err)
+		return repository
+	})
+}
+
+func TestWarehouseRepositoryFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := database.Open(path)
+	require.NoError(t, err)
+
+	repository, err := NewWarehouseRepository(db, storage.New[*entity.Warehouse]())
+	require.NoError(t, err)
+
+	code, _ := valueobject.NewCode("W1")
+	location, _ := valueobject.NewLocation(3, 4.5)
+	stored := &entity.Warehouse{ID: uuid.New(), Code: code, Location: location}
+	require.NoError(t, repository.Create(stored))
+	require.NoError(t, database.Flush(db, repository))
+	require.NoError(t, db.Close())
+
+	db, err = database.Open(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	reopened, err := NewWarehouseRepository(db, storage.New[*entity.Warehouse]())
+	require.NoError(t, err)
+
+	loaded, err := reopened.Get(code)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, loaded)
+}
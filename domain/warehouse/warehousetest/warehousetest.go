@@ -0,0 +1,102 @@
+// Package warehousetest provides the contract every
+// warehouse.WarehouseRepository implementation must satisfy.
+package warehousetest
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// RunRepositorySuite runs the contract against repositories built by
+// newRepository, which must return an empty repository on every call.
+func RunRepositorySuite(t *testing.T, newRepository func(t *testing.T) warehouse.WarehouseRepository) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, newRepository(t)) })
+	t.Run("Get", func(t *testing.T) { testGet(t, newRepository(t)) })
+	t.Run("GetAll", func(t *testing.T) { testGetAll(t, newRepository(t)) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newRepository(t)) })
+}
+
+func newWarehouse(code string) *entity.Warehouse {
+	warehouseCode, _ := valueobject.NewCode(code)
+	location, _ := valueobject.NewLocation(1, 2)
+	return &entity.Warehouse{ID: uuid.New(), Code: warehouseCode, Location: location}
+}
+
+func testCreate(t *testing.T, repository warehouse.WarehouseRepository) {
+	stored := newWarehouse("W1")
+
+	t.Run("Create warehouse", func(t *testing.T) {
+		err := repository.Create(stored)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Create warehouse which already exist", func(t *testing.T) {
+		err := repository.Create(newWarehouse("W1"))
+		assert.ErrorIs(t, err, warehouse.ErrAlreadyExist)
+
+		result, err := repository.Get(stored.Code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func testGet(t *testing.T, repository warehouse.WarehouseRepository) {
+	stored := newWarehouse("W1")
+	repository.Create(stored)
+
+	t.Run("Get warehouse", func(t *testing.T) {
+		code, _ := valueobject.NewCode("W1")
+
+		result, err := repository.Get(code)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+
+	t.Run("Get warehouse which not exist", func(t *testing.T) {
+		code, _ := valueobject.NewCode("W2")
+
+		result, err := repository.Get(code)
+		assert.ErrorIs(t, err, warehouse.ErrNotFound)
+		assert.Nil(t, result)
+	})
+}
+
+func testGetAll(t *testing.T, repository warehouse.WarehouseRepository) {
+	t.Run("Get all warehouses of empty repository", func(t *testing.T) {
+		assert.Len(t, repository.GetAll(), 0)
+	})
+
+	t.Run("Get all warehouses ordered by code", func(t *testing.T) {
+		for _, code := range []string{"W3", "W1", "W2"} {
+			repository.Create(newWarehouse(code))
+		}
+
+		var codes []string
+		for _, item := range repository.GetAll() {
+			codes = append(codes, item.Code.Value())
+		}
+		assert.Equal(t, []string{"W1", "W2", "W3"}, codes)
+	})
+}
+
+func testClear(t *testing.T, repository warehouse.WarehouseRepository) {
+	repository.Create(newWarehouse("W1"))
+	repository.Create(newWarehouse("W2"))
+
+	repository.Clear()
+	assert.Len(t, repository.GetAll(), 0)
+
+	code, _ := valueobject.NewCode("W1")
+	_, err := repository.Get(code)
+	assert.ErrorIs(t, err, warehouse.ErrNotFound)
+
+	t.Run("Create warehouse after clear", func(t *testing.T) {
+		err := repository.Create(newWarehouse("W1"))
+		assert.NoError(t, err)
+	})
+}
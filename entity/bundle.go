@@ -0,0 +1,52 @@
+package entity
+
+import "github.com/aaydin-tr/e-commerce/valueobject"
+
+// BundleComponent is a product that makes up a bundle and how many units of
+// it one bundle holds.
+type BundleComponent struct {
+	Product  valueobject.Code
+	Quantity int
+}
+
+// IsBundle reports whether the product is sold as a bundle of other products.
+func (p *Product) IsBundle() bool {
+	return len(p.Components) > 0
+}
+
+// Assemble returns how many bundles the stock of components, keyed by code,
+// makes up.
+func (p *Product) Assemble(components map[string]*Product) int {
+	assembled := -1
+	for _, component := range p.Components {
+		item, ok := components[component.Product.Value()]
+		if !ok {
+			return 0
+		}
+
+		count := item.Stock.Value() / component.Quantity
+		if assembled == -1 || count < assembled {
+			assembled = count
+		}
+	}
+
+	if assembled < 0 {
+		return 0
+	}
+
+	return assembled
+}
+
+// SyncStock sets the stock of the bundle to what its components make up. Like
+// a restock it moves the initial stock too, so components sold on their own
+// do not count as sales of the bundle when pricing.
+func (p *Product) SyncStock(components map[string]*Product) error {
+	return p.IncreaseStock(p.Assemble(components) - p.Stock.Value())
+}
+
+// ReturnComponentStock puts the units of component the bundle order took from
+// stock back into the warehouses they came from and returns how many there
+// were.
+func (p *Product) ReturnComponentStock(order *Order, component BundleComponent) (int, error) {
+	return p.returnStock(order, (order.Quantity.Value()-order.Backordered.Value())*component.Quantity)
+}
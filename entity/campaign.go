@@ -1,20 +1,68 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 )
 
 type Campaign struct {
-	ID                     uuid.UUID
-	Name                   valueobject.Name
-	Product                *Product
+	ID      uuid.UUID
+	Name    valueobject.Name
+	Product *Product
+	// Category is set instead of Product on a category campaign. Its price
+	// manipulation applies to each of its Members, ordered by code, and its
+	// target counts the sales of them all. The members are the products of
+	// the category and its subcategories when the campaign was created.
+	Category               valueobject.Code
+	Members                []*Product
 	Duration               valueobject.Duration
 	PriceManipulationLimit valueobject.PriceManipulationLimit
 	TargetSalesCount       valueobject.TargetSalesCount
 	Status                 valueobject.Status
 	TotalSales             valueobject.Quantity
 	AverageItemPrice       valueobject.Price
+	// Tax is the tax paid on the units counted towards the campaign, which
+	// AverageItemPrice leaves out.
+	Tax float64
+	// StockPolicy decides what happens when the stock of the product can no
+	// longer cover the remaining target. A zero policy ends the campaign.
+	StockPolicy valueobject.StockPolicy
+	// BackorderPolicy decides whether backordered units count towards the
+	// target when they are ordered or when they are taken from stock. A zero
+	// policy counts them when they are ordered.
+	BackorderPolicy valueobject.BackorderPolicy
+	// EndReason, EndedAt and FinalPrice describe how the campaign ended and
+	// hold zero values while it is active. FinalPrice is the product price
+	// before it was reset.
+	EndReason  valueobject.EndReason
+	EndedAt    time.Time
+	FinalPrice valueobject.Price
+	// Amendments lists the parameter changes made after creation, oldest
+	// first.
+	Amendments []CampaignAmendment
+	Version    int
+}
+
+// CampaignAmendment records a change of campaign parameters. A zero field
+// means the parameter was left unchanged.
+type CampaignAmendment struct {
+	Time time.Time
+	// DurationExtension is the number of hours added to the duration.
+	DurationExtension      int
+	PriceManipulationLimit int
+	TargetSalesCount       int
+}
+
+// Acquire takes the campaign's aggregate lock. It must be taken after the lock
+// of the campaign's product.
+func (c *Campaign) Acquire() {
+	aggregateLocks.Lock(c)
+}
+
+func (c *Campaign) Release() {
+	aggregateLocks.Unlock(c)
 }
 
 func (c *Campaign) IncreaseTotalSales(amount int) error {
@@ -27,20 +75,101 @@ func (c *Campaign) IncreaseTotalSales(amount int) error {
 	return nil
 }
 
-func (c *Campaign) Close() error {
-	closeStatus, err := valueobject.NewStatus(valueobject.Ended)
+// End closes the campaign for reason at the given time and records the
+// price of its product. A campaign that has already ended keeps its first
+// ending.
+func (c *Campaign) End(reason string, at time.Time) error {
+	if c.IsEnded() {
+		return nil
+	}
+
+	endReason, err := valueobject.NewEndReason(reason)
+	if err != nil {
+		return err
+	}
+
+	err = c.setStatus(valueobject.Ended)
 	if err != nil {
 		return err
 	}
 
-	c.Status = closeStatus
+	c.EndReason = endReason
+	c.EndedAt = at
+	if c.Product != nil {
+		c.FinalPrice = c.Product.Price
+	}
+
 	return nil
 }
 
+// IsCategory reports whether the campaign targets a category rather than a
+// single product.
+func (c *Campaign) IsCategory() bool {
+	return c.Category.Value() != ""
+}
+
+// CountsOnFulfillment reports whether backordered units count towards the
+// target only once they are taken from stock.
+func (c *Campaign) CountsOnFulfillment() bool {
+	return c.BackorderPolicy.Value() == valueobject.CountOnFulfillment
+}
+
 func (c *Campaign) IsActive() bool {
 	return c.Status.Value() == valueobject.Active
 }
 
+func (c *Campaign) IsPaused() bool {
+	return c.Status.Value() == valueobject.Paused
+}
+
+func (c *Campaign) IsEnded() bool {
+	return c.Status.Value() == valueobject.Ended
+}
+
+// StockExhausted reports whether the stock of the product can no longer
+// cover the sales still needed to reach the target.
+func (c *Campaign) StockExhausted() bool {
+	return c.Product != nil && c.Product.Stock.Value() < c.TargetSalesCount.Value()-c.TotalSales.Value()
+}
+
+// ApplyStockPolicy ends or pauses an active campaign whose stock is exhausted,
+// as its stock policy says. It reports whether the campaign stopped running.
+// The stock of a category campaign is only checked as it advances.
+func (c *Campaign) ApplyStockPolicy(now time.Time) (bool, error) {
+	return c.applyStockPolicy(now, c.StockExhausted())
+}
+
+func (c *Campaign) applyStockPolicy(now time.Time, exhausted bool) (bool, error) {
+	if !c.IsActive() || !exhausted {
+		return false, nil
+	}
+
+	switch c.StockPolicy.Value() {
+	case valueobject.KeepOnStockOut:
+		return false, nil
+	case valueobject.PauseOnStockOut:
+		return true, c.setStatus(valueobject.Paused)
+	default:
+		err := c.End(valueobject.StockExhausted, now)
+		if err != nil {
+			return false, err
+		}
+
+		c.detach()
+		return true, nil
+	}
+}
+
+func (c *Campaign) setStatus(value string) error {
+	status, err := valueobject.NewStatus(value)
+	if err != nil {
+		return err
+	}
+
+	c.Status = status
+	return nil
+}
+
 func (c *Campaign) DecreaseDuration(duration int) error {
 	decreaseDuration := c.Duration.Value() - duration
 	if decreaseDuration < 0 {
@@ -77,3 +206,132 @@ func (c *Campaign) UpdateAverageItemPrice(orderPrice float64, orderQuantity int)
 	c.AverageItemPrice = newAverageItemPrice
 	return nil
 }
+
+// Refund takes quantity units sold at price, taxed at unitTax each, back off
+// the campaign when the order they were sold in is cancelled.
+func (c *Campaign) Refund(quantity int, price float64, unitTax float64) error {
+	remaining := c.TotalSales.Value() - quantity
+	if remaining <= 0 {
+		c.TotalSales = valueobject.Quantity{}
+		c.AverageItemPrice = valueobject.Price{}
+		c.Tax = 0
+		return nil
+	}
+
+	totalSales, err := valueobject.NewQuantity(remaining)
+	if err != nil {
+		return err
+	}
+
+	var averageItemPrice valueobject.Price
+	revenue := c.Turnover() - price*float64(quantity)
+	if revenue > 0 {
+		averageItemPrice, err = valueobject.NewPrice(revenue / float64(remaining))
+		if err != nil {
+			return err
+		}
+	}
+
+	c.TotalSales = totalSales
+	c.AverageItemPrice = averageItemPrice
+	c.Tax -= unitTax * float64(quantity)
+	return nil
+}
+
+// AddTax counts the tax of quantity units taxed at unitTax each towards the
+// campaign.
+func (c *Campaign) AddTax(unitTax float64, quantity int) {
+	c.Tax += unitTax * float64(quantity)
+}
+
+// Turnover returns the net amount paid for the units counted towards the
+// campaign.
+func (c *Campaign) Turnover() float64 {
+	return float64(c.TotalSales.Value()) * c.AverageItemPrice.Value()
+}
+
+// GrossTurnover returns the amount paid for the units counted towards the
+// campaign including tax.
+func (c *Campaign) GrossTurnover() float64 {
+	return c.Turnover() + c.Tax
+}
+
+// Advance moves the campaign forward by one hour, ending at now. The campaign
+// ends when its target is reached or its duration runs out, and its stock
+// policy applies when the stock is exhausted. Otherwise the product price is
+// adjusted to the demand. A paused campaign keeps its duration and resumes
+// once the stock covers the remaining target again. A campaign that ended
+// between ticks is removed from its product. Both the product and the
+// campaign lock must be held.
+func (c *Campaign) Advance(now time.Time) {
+	if c.advance(now, c.StockExhausted()) {
+		c.Product.Discount(c.PriceManipulationLimit)
+	}
+}
+
+// AdvanceCategory moves a category campaign forward by one hour like Advance,
+// given the total stock of its members. It reports whether the campaign is
+// still running, in which case the caller adjusts the price of every member.
+// The campaign lock must be held.
+func (c *Campaign) AdvanceCategory(now time.Time, stock int) bool {
+	return c.advance(now, stock < c.TargetSalesCount.Value()-c.TotalSales.Value())
+}
+
+func (c *Campaign) advance(now time.Time, exhausted bool) bool {
+	if c.IsPaused() {
+		if exhausted {
+			return false
+		}
+
+		c.setStatus(valueobject.Active)
+	}
+
+	if !c.IsActive() {
+		c.detach()
+		return false
+	}
+
+	c.DecreaseDuration(1)
+
+	if c.TotalSales.Value() == c.TargetSalesCount.Value() {
+		c.End(valueobject.TargetReached, now)
+		c.detach()
+		return false
+	}
+
+	if c.Duration.Value() == 0 {
+		c.End(valueobject.Expired, now)
+		c.detach()
+		return false
+	}
+
+	stopped, _ := c.applyStockPolicy(now, exhausted)
+	return !stopped
+}
+
+// Cancel ends the campaign at the given time and resets the price of its
+// product. Both the product and the campaign lock must be held.
+func (c *Campaign) Cancel(at time.Time) error {
+	err := c.End(valueobject.Cancelled, at)
+	if err != nil {
+		return err
+	}
+
+	c.detach()
+	return nil
+}
+
+// detach removes the campaign from its product unless the product has moved
+// on to another campaign.
+func (c *Campaign) detach() {
+	c.Detach(c.Product)
+}
+
+// Detach removes the campaign from product, which may be a member of a
+// category campaign, unless the product has moved on to another campaign.
+// The product lock must be held.
+func (c *Campaign) Detach(product *Product) {
+	if product != nil && product.Campaign == c {
+		product.RemoveCampaign()
+	}
+}
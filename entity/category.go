@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+// Category groups products for merchandising. Categories form a taxonomy
+// through Parent, which is empty for a top-level category.
+type Category struct {
+	ID      uuid.UUID
+	Code    valueobject.Code
+	Parent  valueobject.Code
+	Version int
+}
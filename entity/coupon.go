@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+// Coupon takes a discount off the orders it is redeemed on.
+type Coupon struct {
+	ID       uuid.UUID
+	Code     valueobject.Code
+	Discount valueobject.Discount
+	// UsageLimit is how many orders may redeem the coupon, zero when there is
+	// no limit. Uses counts the orders that redeemed it and were not
+	// cancelled.
+	UsageLimit int
+	Uses       int
+	// ExpiresAt is zero for a coupon that never expires.
+	ExpiresAt time.Time
+	// Products and Categories restrict the coupon to the listed products and
+	// the products in the listed categories or their subcategories. A coupon
+	// with neither applies to every product.
+	Products   []valueobject.Code
+	Categories []valueobject.Code
+	Version    int
+}
+
+// Acquire takes the coupon's aggregate lock. It must be taken after the locks
+// of the product ordered and its campaigns.
+func (c *Coupon) Acquire() {
+	aggregateLocks.Lock(c)
+}
+
+func (c *Coupon) Release() {
+	aggregateLocks.Unlock(c)
+}
+
+// IsExpired reports whether the coupon can no longer be redeemed at now.
+func (c *Coupon) IsExpired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// IsUsedUp reports whether the coupon has been redeemed as often as its usage
+// limit allows.
+func (c *Coupon) IsUsedUp() bool {
+	return c.UsageLimit > 0 && c.Uses >= c.UsageLimit
+}
+
+// AppliesTo reports whether the coupon may be redeemed on a product known by
+// products, its code and the code of its parent, that is in categories, its
+// category and every category above it.
+func (c *Coupon) AppliesTo(products []valueobject.Code, categories []valueobject.Code) bool {
+	if len(c.Products) == 0 && len(c.Categories) == 0 {
+		return true
+	}
+
+	return containsCode(c.Products, products) || containsCode(c.Categories, categories)
+}
+
+// Redeem counts a use of the coupon.
+func (c *Coupon) Redeem() {
+	c.Uses++
+}
+
+// Return gives back the use of a cancelled order.
+func (c *Coupon) Return() {
+	if c.Uses > 0 {
+		c.Uses--
+	}
+}
+
+func containsCode(values []valueobject.Code, codes []valueobject.Code) bool {
+	for _, value := range values {
+		for _, code := range codes {
+			if value.Equals(code) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
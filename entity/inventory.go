@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"math"
+	"sort"
+
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+// WarehouseStock is the part of a product's stock held by a warehouse.
+type WarehouseStock struct {
+	Warehouse valueobject.Code
+	Stock     valueobject.Stock
+}
+
+// Allocation records how many units of an order were taken from a warehouse.
+// Product names the component the units are of for bundle orders and is empty
+// otherwise.
+type Allocation struct {
+	Warehouse valueobject.Code
+	Quantity  int
+	Product   valueobject.Code
+}
+
+// Unassigned returns the units of stock no warehouse holds.
+func (p *Product) Unassigned() int {
+	unassigned := p.Stock.Value()
+	for _, item := range p.Inventory {
+		unassigned -= item.Stock.Value()
+	}
+
+	return unassigned
+}
+
+// AssignStock moves amount units of unassigned stock to warehouse. The total
+// stock does not change. The caller makes sure enough stock is unassigned.
+func (p *Product) AssignStock(warehouse valueobject.Code, amount int) error {
+	inventory := make([]WarehouseStock, 0, len(p.Inventory)+1)
+	found := false
+	for _, item := range p.Inventory {
+		if item.Warehouse.Equals(warehouse) {
+			stock, err := valueobject.NewStock(item.Stock.Value() + amount)
+			if err != nil {
+				return err
+			}
+
+			item.Stock = stock
+			found = true
+		}
+
+		inventory = append(inventory, item)
+	}
+
+	if !found {
+		stock, err := valueobject.NewStock(amount)
+		if err != nil {
+			return err
+		}
+
+		inventory = append(inventory, WarehouseStock{Warehouse: warehouse, Stock: stock})
+	}
+
+	p.Inventory = inventory
+	return nil
+}
+
+// Take removes amount units from the stock and returns the warehouses they
+// came from. The nearest and most-stock strategies take every unit from the
+// nearest warehouse or the one with the most stock if it holds enough, and
+// otherwise split the order in the same order of preference. The split
+// strategy, which the zero strategy stands for, takes from the nearest
+// warehouses first. Unassigned stock is taken last. warehouses maps codes to
+// warehouses for their locations; unknown warehouses count as farthest.
+func (p *Product) Take(amount int, strategy valueobject.AllocationStrategy, location valueobject.Location, warehouses map[string]*Warehouse) ([]Allocation, error) {
+	sources := p.allocationOrder(strategy, location, warehouses)
+	if strategy.Value() == valueobject.NearestWarehouse || strategy.Value() == valueobject.MostStockWarehouse {
+		for _, index := range sources {
+			if p.Inventory[index].Stock.Value() >= amount {
+				sources = []int{index}
+				break
+			}
+		}
+	}
+
+	inventory := make([]WarehouseStock, len(p.Inventory))
+	copy(inventory, p.Inventory)
+
+	var allocations []Allocation
+	remaining := amount
+	for _, index := range sources {
+		taken := inventory[index].Stock.Value()
+		if remaining < taken {
+			taken = remaining
+		}
+		if taken == 0 {
+			continue
+		}
+
+		stock, err := valueobject.NewStock(inventory[index].Stock.Value() - taken)
+		if err != nil {
+			return nil, err
+		}
+
+		inventory[index].Stock = stock
+		allocations = append(allocations, Allocation{Warehouse: inventory[index].Warehouse, Quantity: taken})
+		remaining -= taken
+	}
+
+	err := p.DecreaseStock(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Inventory = inventory
+	return allocations, nil
+}
+
+// allocationOrder returns the indexes of the inventory in the order strategy
+// prefers the warehouses.
+func (p *Product) allocationOrder(strategy valueobject.AllocationStrategy, location valueobject.Location, warehouses map[string]*Warehouse) []int {
+	distance := func(index int) float64 {
+		warehouse, ok := warehouses[p.Inventory[index].Warehouse.Value()]
+		if !ok {
+			return math.Inf(1)
+		}
+
+		return warehouse.Location.Distance(location)
+	}
+
+	order := make([]int, len(p.Inventory))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if strategy.Value() == valueobject.MostStockWarehouse {
+			if p.Inventory[a].Stock.Value() != p.Inventory[b].Stock.Value() {
+				return p.Inventory[a].Stock.Value() > p.Inventory[b].Stock.Value()
+			}
+		} else if distance(a) != distance(b) {
+			return distance(a) < distance(b)
+		}
+
+		return p.Inventory[a].Warehouse.Value() < p.Inventory[b].Warehouse.Value()
+	})
+
+	return order
+}
@@ -0,0 +1,7 @@
+package entity
+
+import "github.com/aaydin-tr/e-commerce/pkg/lock"
+
+// aggregateLocks guards aggregates that are shared between concurrent callers.
+// Locks are keyed by the aggregate pointer so copies never share a lock.
+var aggregateLocks = lock.New()
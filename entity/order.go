@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 )
@@ -9,4 +11,102 @@ type Order struct {
 	ID        uuid.UUID
 	ProductID uuid.UUID
 	Quantity  valueobject.Quantity
+	// Price is the unit price paid for the product. When a volume tier of the
+	// product applied, ListPrice is the unit price before the tier took
+	// TierPercent off it; both are zero otherwise.
+	Price       valueobject.Price
+	ListPrice   valueobject.Price
+	TierPercent float64
+	// Campaign is the name of the campaign the order counted towards, empty
+	// when no campaign was active.
+	Campaign valueobject.Name
+	// Coupon is the code of the coupon redeemed on the order, empty when none
+	// was, and Discount the amount it took off the order.
+	Coupon   valueobject.Code
+	Discount valueobject.Price
+	// Region is the code of the region the order was taxed in, empty when it
+	// was not. TaxRate is the percent of the region for the tax class of the
+	// product, and Tax the amount it added to the net total.
+	Region  valueobject.Code
+	TaxRate float64
+	Tax     float64
+	// Backordered is how many of the units are still to be taken from stock.
+	Backordered valueobject.Stock
+	// Allocations lists the warehouses the units were taken from. Units taken
+	// from unassigned stock are not listed.
+	Allocations []Allocation
+	Status      valueobject.OrderStatus
+	CreatedAt   time.Time
+	Version     int
+}
+
+// ApplyTier takes tier off the unit price of the order and records the price
+// it was taken off.
+func (o *Order) ApplyTier(tier PriceTier) error {
+	price, err := valueobject.NewPrice(o.Price.Value() * (100 - tier.Percent) / 100)
+	if err != nil {
+		return err
+	}
+
+	o.ListPrice = o.Price
+	o.TierPercent = tier.Percent
+	o.Price = price
+	return nil
+}
+
+// Subtotal returns the amount of the order before its discount.
+func (o *Order) Subtotal() float64 {
+	return float64(o.Quantity.Value()) * o.Price.Value()
+}
+
+// Total returns the net amount paid for the order, before tax.
+func (o *Order) Total() float64 {
+	return o.Subtotal() - o.Discount.Value()
+}
+
+// Gross returns the amount paid for the order including tax.
+func (o *Order) Gross() float64 {
+	return o.Total() + o.Tax
+}
+
+// ApplyTax taxes the net total of the order at rate percent of region.
+func (o *Order) ApplyTax(region valueobject.Code, rate float64) {
+	o.Region = region
+	o.TaxRate = rate
+	o.Tax = o.Total() * rate / 100
+}
+
+// UnitTax returns the tax paid on each unit of the order.
+func (o *Order) UnitTax() float64 {
+	if o.Quantity.Value() == 0 {
+		return 0
+	}
+
+	return o.Tax / float64(o.Quantity.Value())
+}
+
+// NetPrice returns the unit price paid once the discount is spread over the
+// units.
+func (o *Order) NetPrice() float64 {
+	if o.Quantity.Value() == 0 {
+		return 0
+	}
+
+	return o.Total() / float64(o.Quantity.Value())
+}
+
+// ChangeStatus moves the order to status if the lifecycle allows it.
+func (o *Order) ChangeStatus(status string) error {
+	next, err := valueobject.NewOrderStatus(status)
+	if err != nil {
+		return err
+	}
+
+	next, err = o.Status.Transition(next)
+	if err != nil {
+		return err
+	}
+
+	o.Status = next
+	return nil
 }
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 )
@@ -11,12 +13,63 @@ type Product struct {
 	Price    valueobject.Price
 	Stock    valueobject.Stock
 	Campaign *Campaign
+	Version  int
 
 	InititalStock    valueobject.Stock
 	InititalPrice    valueobject.Price
 	TotalDemandCount valueobject.Demand
+
+	// BackorderLimit is how many units may be ordered beyond the stock.
+	// Backordered counts the units ordered but not yet taken from stock,
+	// including pre-orders, and BackorderQueue lists their orders oldest
+	// first.
+	BackorderLimit valueobject.Stock
+	Backordered    valueobject.Stock
+	BackorderQueue []uuid.UUID
+	// Inventory splits the stock between warehouses. Stock stays the total,
+	// and the units no warehouse holds are unassigned.
+	Inventory []WarehouseStock
+	// LaunchAt is when the stock goes on sale. Orders before it are
+	// pre-orders. A zero LaunchAt means the product is on sale from the start.
+	LaunchAt time.Time
+	// Components lists the products a bundle is made of, ordered by code. A
+	// bundle holds no stock of its own; its stock is how many bundles the
+	// components make up.
+	Components []BundleComponent
+	// Variants lists the variants of a parent product, ordered by code. Like a
+	// bundle a parent holds no stock of its own; its stock is the total of its
+	// variants. A variant names its Parent and the Attributes it is described
+	// by, ordered by name. PriceOverride is set when a variant has a price of
+	// its own rather than the price of its parent.
+	Variants      []valueobject.Code
+	Parent        valueobject.Code
+	Attributes    []VariantAttribute
+	PriceOverride bool
+	// Category is the code of the category the product is assigned to, or
+	// empty when it has none.
+	Category valueobject.Code
+	// PriceTiers lists the volume discounts of the product, ordered by
+	// minimum quantity.
+	PriceTiers []PriceTier
+	// TaxClass is the code of the tax class the product is taxed in, or empty
+	// when it has none of its own.
+	TaxClass valueobject.Code
+}
+
+// Acquire takes the product's aggregate lock. When its campaign has to be
+// locked too, the product is always locked first. Bundles are locked before
+// parents and parents before the products holding stock, each in code order,
+// which LockStock follows.
+func (p *Product) Acquire() {
+	aggregateLocks.Lock(p)
+}
+
+func (p *Product) Release() {
+	aggregateLocks.Unlock(p)
 }
 
+// DecreaseStock removes amount units from the total stock. Units held by
+// warehouses are removed with Take.
 func (p *Product) DecreaseStock(amount int) error {
 	newStock, err := valueobject.NewStock(p.Stock.Value() - amount)
 	if err != nil {
@@ -27,6 +80,164 @@ func (p *Product) DecreaseStock(amount int) error {
 	return nil
 }
 
+// IncreaseStock adds restocked units. The initial stock grows by the same
+// amount, so restocking does not count as sales when pricing.
+func (p *Product) IncreaseStock(amount int) error {
+	newStock, err := valueobject.NewStock(p.Stock.Value() + amount)
+	if err != nil {
+		return err
+	}
+
+	newInitialStock, err := valueobject.NewStock(p.InititalStock.Value() + amount)
+	if err != nil {
+		return err
+	}
+
+	p.Stock = newStock
+	p.InititalStock = newInitialStock
+	return nil
+}
+
+// AdjustStock corrects the stock by amount, which is negative when units are
+// written off. Like a restock it moves the initial stock too, so corrections
+// do not count as sales when pricing.
+func (p *Product) AdjustStock(amount int) error {
+	return p.IncreaseStock(amount)
+}
+
+func (p *Product) IsLaunched(now time.Time) bool {
+	return p.LaunchAt.IsZero() || !now.Before(p.LaunchAt)
+}
+
+// OnHand returns the units that can be taken from stock at now.
+func (p *Product) OnHand(now time.Time) int {
+	if !p.IsLaunched(now) {
+		return 0
+	}
+
+	return p.Stock.Value()
+}
+
+// BackorderCapacity returns how many more units can be backordered at now.
+// Before launch the whole stock can be pre-ordered as well.
+func (p *Product) BackorderCapacity(now time.Time) int {
+	capacity := p.BackorderLimit.Value() - p.Backordered.Value()
+	if !p.IsLaunched(now) {
+		capacity += p.Stock.Value()
+	}
+
+	return capacity
+}
+
+// Backorder queues amount units of order behind the earlier backorders.
+func (p *Product) Backorder(order *Order, amount int) error {
+	backordered, err := valueobject.NewStock(p.Backordered.Value() + amount)
+	if err != nil {
+		return err
+	}
+
+	owed, err := valueobject.NewStock(order.Backordered.Value() + amount)
+	if err != nil {
+		return err
+	}
+
+	p.Backordered = backordered
+	p.BackorderQueue = append(p.BackorderQueue, order.ID)
+	order.Backordered = owed
+	return nil
+}
+
+// Fulfill takes as many of the units order is owed from stock as there are
+// and returns how many it took. The order leaves the queue once it is owed
+// nothing.
+func (p *Product) Fulfill(order *Order) (int, error) {
+	taken := p.Stock.Value()
+	if order.Backordered.Value() < taken {
+		taken = order.Backordered.Value()
+	}
+
+	allocations, err := p.Take(taken, valueobject.AllocationStrategy{}, valueobject.Location{}, nil)
+	if err != nil {
+		return 0, err
+	}
+	order.Allocations = append(order.Allocations, allocations...)
+
+	err = p.release(order, taken)
+	if err != nil {
+		return 0, err
+	}
+
+	return taken, nil
+}
+
+// CancelBackorder removes order from the queue and frees the units it was
+// still owed.
+func (p *Product) CancelBackorder(order *Order) error {
+	return p.release(order, order.Backordered.Value())
+}
+
+// ReturnStock puts the units order took from stock back into the warehouses
+// they came from and returns how many there were. Unlike a restock it leaves
+// the initial stock alone, so the units no longer count as sold when pricing.
+func (p *Product) ReturnStock(order *Order) (int, error) {
+	return p.returnStock(order, order.Quantity.Value()-order.Backordered.Value())
+}
+
+// returnStock adds returned units to the stock and puts the ones order took
+// from warehouses of the product back. Allocations of bundle orders name the
+// component they were taken from.
+func (p *Product) returnStock(order *Order, returned int) (int, error) {
+	stock, err := valueobject.NewStock(p.Stock.Value() + returned)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, allocation := range order.Allocations {
+		if allocation.Product.Value() != "" && !allocation.Product.Equals(p.Code) {
+			continue
+		}
+
+		err = p.AssignStock(allocation.Warehouse, allocation.Quantity)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	p.Stock = stock
+	return returned, nil
+}
+
+func (p *Product) release(order *Order, amount int) error {
+	backordered, err := valueobject.NewStock(p.Backordered.Value() - amount)
+	if err != nil {
+		return err
+	}
+
+	owed, err := valueobject.NewStock(order.Backordered.Value() - amount)
+	if err != nil {
+		return err
+	}
+
+	p.Backordered = backordered
+	order.Backordered = owed
+	if owed.Value() == 0 {
+		p.dequeue(order.ID)
+	}
+
+	return nil
+}
+
+func (p *Product) dequeue(orderID uuid.UUID) {
+	queue := make([]uuid.UUID, 0, len(p.BackorderQueue))
+	for _, id := range p.BackorderQueue {
+		if id != orderID {
+			queue = append(queue, id)
+		}
+	}
+
+	p.BackorderQueue = queue
+}
+
 func (p *Product) UpdatePrice(price float64) error {
 	newPrice, err := valueobject.NewPrice(price)
 	if err != nil {
@@ -47,6 +258,11 @@ func (p *Product) IncreaseDemand(amount int) error {
 	return nil
 }
 
+// DecreaseDemand takes the units of a cancelled order back off the demand.
+func (p *Product) DecreaseDemand(amount int) error {
+	return p.IncreaseDemand(-amount)
+}
+
 func (p *Product) RemoveCampaign() {
 	p.Campaign = nil
 	p.UpdatePrice(p.InititalPrice.Value())
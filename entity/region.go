@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"sort"
+
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+// StandardTaxClass is the tax class of products without one of their own.
+const StandardTaxClass = "STANDARD"
+
+// Region taxes the orders placed in it at the rate of the tax class of the
+// product ordered.
+type Region struct {
+	ID   uuid.UUID
+	Code valueobject.Code
+	// Rates lists the tax rates of the region, ordered by tax class.
+	Rates   []TaxRate
+	Version int
+}
+
+// TaxRate is the percent a region adds to the net amount of orders of
+// products in Class.
+type TaxRate struct {
+	Class   valueobject.Code
+	Percent float64
+}
+
+// Acquire takes the region's aggregate lock. It must be taken after the locks
+// of the product ordered, its campaigns and the coupon redeemed.
+func (r *Region) Acquire() {
+	aggregateLocks.Lock(r)
+}
+
+func (r *Region) Release() {
+	aggregateLocks.Unlock(r)
+}
+
+// RateFor returns the rate of the region for the tax class class.
+func (r *Region) RateFor(class valueobject.Code) (float64, bool) {
+	for _, item := range r.Rates {
+		if item.Class.Equals(class) {
+			return item.Percent, true
+		}
+	}
+
+	return 0, false
+}
+
+// SetRate sets the rate of the tax class class to percent, keeping the rates
+// ordered by class. The rates are copied, so a snapshot of the region keeps
+// its previous rates.
+func (r *Region) SetRate(class valueobject.Code, percent float64) {
+	rates := make([]TaxRate, 0, len(r.Rates)+1)
+	for _, item := range r.Rates {
+		if !item.Class.Equals(class) {
+			rates = append(rates, item)
+		}
+	}
+	rates = append(rates, TaxRate{Class: class, Percent: percent})
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Class.Value() < rates[j].Class.Value()
+	})
+	r.Rates = rates
+}
+
+// TaxClassFor returns the tax class the product is taxed in. A variant without a
+// class of its own is taxed in the class of its parent, which is nil for other
+// products, and a product without either in the standard class.
+func (p *Product) TaxClassFor(parent *Product) valueobject.Code {
+	if p.TaxClass.Value() != "" {
+		return p.TaxClass
+	}
+
+	if parent != nil && parent.TaxClass.Value() != "" {
+		return parent.TaxClass
+	}
+
+	standard, _ := valueobject.NewCode(StandardTaxClass)
+	return standard
+}
@@ -0,0 +1,252 @@
+package entity
+
+import (
+	"sort"
+
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+// StockGroup holds the products a change to the stock of some products
+// touches, all locked: the products themselves, the bundles made of them and
+// their parents, and the components and variants the stock of those bundles
+// and parents is derived from.
+type StockGroup struct {
+	products map[string]*Product
+	derived  []*Product
+	locked   []*Product
+}
+
+// shape is the part of a product that decides which group it belongs to,
+// read under its lock.
+type shape struct {
+	product    *Product
+	parent     string
+	components []string
+	variants   []string
+}
+
+// LockStock finds among the products all returns those touched by a change to
+// the stock of changed and locks them. A change to a bundle changes the stock
+// of its components. Products are locked in lock order: bundles, then parents,
+// then the products holding stock, each in code order. No product lock may be
+// held.
+func LockStock(all func() []*Product, changed ...*Product) *StockGroup {
+	for {
+		group := newStockGroup(shapes(all()), changed)
+		group.lock()
+		if group.complete(all()) {
+			return group
+		}
+
+		// A variant was created while the group was being looked up, so look
+		// it up again.
+		group.Release()
+	}
+}
+
+func shapes(all []*Product) map[string]shape {
+	result := make(map[string]shape, len(all))
+	for _, item := range all {
+		item.Acquire()
+		itemShape := shape{product: item, parent: item.Parent.Value()}
+		for _, component := range item.Components {
+			itemShape.components = append(itemShape.components, component.Product.Value())
+		}
+		for _, code := range item.Variants {
+			itemShape.variants = append(itemShape.variants, code.Value())
+		}
+		result[item.Code.Value()] = itemShape
+		item.Release()
+	}
+
+	return result
+}
+
+func newStockGroup(shapes map[string]shape, changed []*Product) *StockGroup {
+	group := &StockGroup{products: make(map[string]*Product)}
+	add := func(code string) {
+		if itemShape, ok := shapes[code]; ok {
+			group.products[code] = itemShape.product
+		}
+	}
+
+	holders := make(map[string]bool)
+	for _, item := range changed {
+		code := item.Code.Value()
+		group.products[code] = item
+		if itemShape, ok := shapes[code]; ok && len(itemShape.components) > 0 {
+			for _, component := range itemShape.components {
+				holders[component] = true
+			}
+			continue
+		}
+		holders[code] = true
+	}
+
+	derived := make(map[string]bool)
+	for code, itemShape := range shapes {
+		for _, component := range itemShape.components {
+			if holders[component] {
+				derived[code] = true
+			}
+		}
+	}
+	for code := range holders {
+		add(code)
+		if parent := shapes[code].parent; parent != "" {
+			derived[parent] = true
+		}
+	}
+
+	for code := range derived {
+		add(code)
+		for _, component := range shapes[code].components {
+			add(component)
+		}
+		for _, variant := range shapes[code].variants {
+			add(variant)
+		}
+
+		if item, ok := group.products[code]; ok {
+			group.derived = append(group.derived, item)
+		}
+	}
+	sortByLockOrder(group.derived)
+
+	return group
+}
+
+// lockLevel orders bundles before parents and parents before the products
+// holding stock.
+func lockLevel(item *Product) int {
+	if item.IsBundle() {
+		return 0
+	}
+
+	if item.IsParent() {
+		return 1
+	}
+
+	return 2
+}
+
+func sortByLockOrder(products []*Product) {
+	levels := make(map[*Product]int, len(products))
+	for _, item := range products {
+		item.Acquire()
+		levels[item] = lockLevel(item)
+		item.Release()
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		if levels[products[i]] != levels[products[j]] {
+			return levels[products[i]] < levels[products[j]]
+		}
+		return products[i].Code.Value() < products[j].Code.Value()
+	})
+}
+
+func (g *StockGroup) lock() {
+	g.locked = make([]*Product, 0, len(g.products))
+	for _, item := range g.products {
+		g.locked = append(g.locked, item)
+	}
+	sortByLockOrder(g.locked)
+
+	for _, item := range g.locked {
+		item.Acquire()
+	}
+}
+
+// complete reports whether the group holds every component and variant among
+// all of the bundles and parents it derives.
+func (g *StockGroup) complete(all []*Product) bool {
+	codes := make(map[string]bool, len(all))
+	for _, item := range all {
+		codes[item.Code.Value()] = true
+	}
+
+	missing := func(code valueobject.Code) bool {
+		return codes[code.Value()] && g.products[code.Value()] == nil
+	}
+
+	for _, item := range g.derived {
+		for _, component := range item.Components {
+			if missing(component.Product) {
+				return false
+			}
+		}
+
+		for _, code := range item.Variants {
+			if missing(code) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Release unlocks every product of the group.
+func (g *StockGroup) Release() {
+	for i := len(g.locked) - 1; i >= 0; i-- {
+		g.locked[i].Release()
+	}
+}
+
+// Get returns the product of the group with code, or nil when it is not part
+// of the group.
+func (g *StockGroup) Get(code valueobject.Code) *Product {
+	return g.products[code.Value()]
+}
+
+// Derived returns the bundles and parents of the group whose stock is derived
+// from the products that changed, in lock order.
+func (g *StockGroup) Derived() []*Product {
+	return g.derived
+}
+
+// Components returns the components of bundle in the group, keyed by code.
+func (g *StockGroup) Components(bundle *Product) map[string]*Product {
+	result := make(map[string]*Product, len(bundle.Components))
+	for _, component := range bundle.Components {
+		if item, ok := g.products[component.Product.Value()]; ok {
+			result[component.Product.Value()] = item
+		}
+	}
+
+	return result
+}
+
+// Variants returns the variants of parent in the group, keyed by code.
+func (g *StockGroup) Variants(parent *Product) map[string]*Product {
+	result := make(map[string]*Product, len(parent.Variants))
+	for _, code := range parent.Variants {
+		if item, ok := g.products[code.Value()]; ok {
+			result[code.Value()] = item
+		}
+	}
+
+	return result
+}
+
+// InSync reports whether the stock of a bundle or parent of the group matches
+// the products it is made of.
+func (g *StockGroup) InSync(item *Product) bool {
+	if item.IsBundle() {
+		return item.Assemble(g.Components(item)) == item.Stock.Value()
+	}
+
+	stock, initialStock := totals(g.Variants(item))
+	return item.Stock.Value() == stock && item.InititalStock.Value() == initialStock
+}
+
+// Sync brings the stock of a bundle or parent of the group up to date with the
+// products it is made of.
+func (g *StockGroup) Sync(item *Product) error {
+	if item.IsBundle() {
+		return item.SyncStock(g.Components(item))
+	}
+
+	return item.SyncVariants(g.Variants(item))
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+// StockMovement records a change to the stock of a product. Quantity is
+// positive when units were added and negative when they were taken, so the
+// movements of a product add up to its stock.
+type StockMovement struct {
+	ID uuid.UUID
+	// Sequence orders the movements in the ledger and is set when the
+	// movement is appended.
+	Sequence  int
+	ProductID uuid.UUID
+	Type      valueobject.MovementType
+	Quantity  int
+	// Reference is the order the units moved for, or the product itself for
+	// its initial stock, restocks and adjustments.
+	Reference uuid.UUID
+	Time      time.Time
+}
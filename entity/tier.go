@@ -0,0 +1,29 @@
+package entity
+
+// PriceTier takes Percent off the unit price of orders of at least
+// MinQuantity units.
+type PriceTier struct {
+	MinQuantity int
+	Percent     float64
+}
+
+// TierFor returns the tier with the highest minimum quantity an order of
+// quantity units reaches. A variant without tiers of its own is priced by the
+// tiers of its parent, which is nil for other products.
+func (p *Product) TierFor(quantity int, parent *Product) (PriceTier, bool) {
+	tiers := p.PriceTiers
+	if len(tiers) == 0 && parent != nil {
+		tiers = parent.PriceTiers
+	}
+
+	var tier PriceTier
+	found := false
+	for _, item := range tiers {
+		if quantity >= item.MinQuantity && item.MinQuantity > tier.MinQuantity {
+			tier = item
+			found = true
+		}
+	}
+
+	return tier, found
+}
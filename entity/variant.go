@@ -0,0 +1,90 @@
+package entity
+
+import "github.com/aaydin-tr/e-commerce/valueobject"
+
+// VariantAttribute describes one way a variant differs from the other variants
+// of its parent, like its size or colour.
+type VariantAttribute struct {
+	Name  string
+	Value string
+}
+
+// IsParent reports whether the product groups variants.
+func (p *Product) IsParent() bool {
+	return len(p.Variants) > 0
+}
+
+// IsVariant reports whether the product is a variant of a parent product.
+func (p *Product) IsVariant() bool {
+	return p.Parent.Value() != ""
+}
+
+// HoldsStock reports whether the product has stock of its own. Bundles and
+// parent products derive theirs from other products.
+func (p *Product) HoldsStock() bool {
+	return !p.IsBundle() && !p.IsParent()
+}
+
+// FollowsParentPrice reports whether the variant is sold at the price of its
+// parent, which it is unless it has a price or a campaign of its own.
+func (p *Product) FollowsParentPrice() bool {
+	return p.IsVariant() && !p.PriceOverride && p.Campaign == nil
+}
+
+// HasAttributes reports whether the variant is described by exactly
+// attributes, which are ordered by name.
+func (p *Product) HasAttributes(attributes []VariantAttribute) bool {
+	if len(p.Attributes) != len(attributes) {
+		return false
+	}
+
+	for i, attribute := range attributes {
+		if p.Attributes[i] != attribute {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SyncVariants sets the stock and initial stock of the parent to the totals of
+// its variants, keyed by code, so units sold of any variant count as sales of
+// the parent when pricing.
+func (p *Product) SyncVariants(variants map[string]*Product) error {
+	stock, initialStock := totals(variants)
+
+	newStock, err := valueobject.NewStock(stock)
+	if err != nil {
+		return err
+	}
+
+	newInitialStock, err := valueobject.NewStock(initialStock)
+	if err != nil {
+		return err
+	}
+
+	p.Stock = newStock
+	p.InititalStock = newInitialStock
+	return nil
+}
+
+// FollowParent moves the variant to the price of parent when it follows it and
+// reports whether the price changed.
+func (p *Product) FollowParent(parent *Product) bool {
+	if !p.FollowsParentPrice() || p.Price.Equals(parent.Price) {
+		return false
+	}
+
+	p.Price = parent.Price
+	return true
+}
+
+func totals(variants map[string]*Product) (int, int) {
+	var stock, initialStock int
+	for _, variant := range variants {
+		stock += variant.Stock.Value()
+		initialStock += variant.InititalStock.Value()
+	}
+
+	return stock, initialStock
+}
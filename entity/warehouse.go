@@ -0,0 +1,13 @@
+package entity
+
+import (
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+type Warehouse struct {
+	ID       uuid.UUID
+	Code     valueobject.Code
+	Location valueobject.Location
+	Version  int
+}
@@ -35,6 +35,18 @@ func (m *MockCampaignRepository) EXPECT() *MockCampaignRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Clear mocks base method.
+func (m *MockCampaignRepository) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockCampaignRepositoryMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockCampaignRepository)(nil).Clear))
+}
+
 // Create mocks base method.
 func (m *MockCampaignRepository) Create(arg0 *entity.Campaign) error {
 	m.ctrl.T.Helper()
@@ -91,3 +103,17 @@ func (mr *MockCampaignRepositoryMockRecorder) GetAll() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockCampaignRepository)(nil).GetAll))
 }
+
+// Update mocks base method.
+func (m *MockCampaignRepository) Update(arg0 *entity.Campaign, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockCampaignRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockCampaignRepository)(nil).Update), arg0, arg1)
+}
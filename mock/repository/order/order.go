@@ -6,8 +6,10 @@ package repository
 
 import (
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/aaydin-tr/e-commerce/entity"
+	uuid "github.com/google/uuid"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -34,6 +36,18 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Clear mocks base method.
+func (m *MockOrderRepository) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockOrderRepositoryMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockOrderRepository)(nil).Clear))
+}
+
 // Create mocks base method.
 func (m *MockOrderRepository) Create(arg0 *entity.Order) error {
 	m.ctrl.T.Helper()
@@ -47,3 +61,102 @@ func (mr *MockOrderRepositoryMockRecorder) Create(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepository)(nil).Create), arg0)
 }
+
+// Get mocks base method.
+func (m *MockOrderRepository) Get(arg0 uuid.UUID) (*entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrderRepositoryMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderRepository)(nil).Get), arg0)
+}
+
+// GetAll mocks base method.
+func (m *MockOrderRepository) GetAll() []*entity.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]*entity.Order)
+	return ret0
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockOrderRepositoryMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockOrderRepository)(nil).GetAll))
+}
+
+// ListByProduct mocks base method.
+func (m *MockOrderRepository) ListByProduct(arg0 uuid.UUID) []*entity.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByProduct", arg0)
+	ret0, _ := ret[0].([]*entity.Order)
+	return ret0
+}
+
+// ListByProduct indicates an expected call of ListByProduct.
+func (mr *MockOrderRepositoryMockRecorder) ListByProduct(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByProduct", reflect.TypeOf((*MockOrderRepository)(nil).ListByProduct), arg0)
+}
+
+// ListByTimeRange mocks base method.
+func (m *MockOrderRepository) ListByTimeRange(arg0, arg1 time.Time) []*entity.Order {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByTimeRange", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Order)
+	return ret0
+}
+
+// ListByTimeRange indicates an expected call of ListByTimeRange.
+func (mr *MockOrderRepositoryMockRecorder) ListByTimeRange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByTimeRange", reflect.TypeOf((*MockOrderRepository)(nil).ListByTimeRange), arg0, arg1)
+}
+
+// Revenue mocks base method.
+func (m *MockOrderRepository) Revenue(arg0 uuid.UUID) float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revenue", arg0)
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+// Revenue indicates an expected call of Revenue.
+func (mr *MockOrderRepositoryMockRecorder) Revenue(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revenue", reflect.TypeOf((*MockOrderRepository)(nil).Revenue), arg0)
+}
+
+// UnitsSold mocks base method.
+func (m *MockOrderRepository) UnitsSold(arg0 uuid.UUID) int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnitsSold", arg0)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// UnitsSold indicates an expected call of UnitsSold.
+func (mr *MockOrderRepositoryMockRecorder) UnitsSold(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnitsSold", reflect.TypeOf((*MockOrderRepository)(nil).UnitsSold), arg0)
+}
+
+// Update mocks base method.
+func (m *MockOrderRepository) Update(arg0 *entity.Order, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOrderRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOrderRepository)(nil).Update), arg0, arg1)
+}
@@ -0,0 +1,105 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aaydin-tr/e-commerce/domain/region (interfaces: RegionRepository)
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	entity "github.com/aaydin-tr/e-commerce/entity"
+	valueobject "github.com/aaydin-tr/e-commerce/valueobject"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRegionRepository is a mock of RegionRepository interface.
+type MockRegionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegionRepositoryMockRecorder
+}
+
+// MockRegionRepositoryMockRecorder is the mock recorder for MockRegionRepository.
+type MockRegionRepositoryMockRecorder struct {
+	mock *MockRegionRepository
+}
+
+// NewMockRegionRepository creates a new mock instance.
+func NewMockRegionRepository(ctrl *gomock.Controller) *MockRegionRepository {
+	mock := &MockRegionRepository{ctrl: ctrl}
+	mock.recorder = &MockRegionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRegionRepository) EXPECT() *MockRegionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Clear mocks base method.
+func (m *MockRegionRepository) Clear() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Clear")
+}
+
+// Clear indicates an expected call of Clear.
+func (mr *MockRegionRepositoryMockRecorder) Clear() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockRegionRepository)(nil).Clear))
+}
+
+// Create mocks base method.
+func (m *MockRegionRepository) Create(arg0 *entity.Region) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRegionRepositoryMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRegionRepository)(nil).Create), arg0)
+}
+
+// Get mocks base method.
+func (m *MockRegionRepository) Get(arg0 valueobject.Code) (*entity.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(*entity.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRegionRepositoryMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRegionRepository)(nil).Get), arg0)
+}
+
+// GetAll mocks base method.
+func (m *MockRegionRepository) GetAll() []*entity.Region {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll")
+	ret0, _ := ret[0].([]*entity.Region)
+	return ret0
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockRegionRepositoryMockRecorder) GetAll() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRegionRepository)(nil).GetAll))
+}
+
+// Update mocks base method.
+func (m *MockRegionRepository) Update(arg0 *entity.Region, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRegionRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRegionRepository)(nil).Update), arg0, arg1)
+}
@@ -0,0 +1,40 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Start is the time every simulation starts from.
+var Start = time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC)
+
+// Clock is the simulated time shared by the application and the services
+// that record when things happen.
+type Clock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+func New(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d and returns the new time.
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
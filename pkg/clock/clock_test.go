@@ -0,0 +1,21 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(start)
+	assert.Equal(t, start, c.Now())
+
+	now := c.Advance(2 * time.Hour)
+	assert.Equal(t, start.Add(2*time.Hour), now)
+	assert.Equal(t, now, c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
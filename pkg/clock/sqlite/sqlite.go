@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+)
+
+var migrations = []string{
+	`CREATE TABLE clock (
+		id INTEGER PRIMARY KEY CHECK (id = 0),
+		hours INTEGER NOT NULL
+	)`,
+}
+
+// ClockStore keeps the hours elapsed on the clock since clock.Start, so a
+// reopened database continues from the time it was left at.
+type ClockStore struct {
+	clock     *clock.Clock
+	persisted int
+}
+
+// NewClockStore sets systemClock to the time stored in the database.
+func NewClockStore(db *sql.DB, systemClock *clock.Clock) (*ClockStore, error) {
+	err := database.Migrate(db, "clock", migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ClockStore{clock: systemClock}
+	err = db.QueryRow(`SELECT hours FROM clock WHERE id = 0`).Scan(&s.persisted)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	systemClock.Set(clock.Start.Add(time.Duration(s.persisted) * time.Hour))
+	return s, nil
+}
+
+func (s *ClockStore) Flush(tx *sql.Tx) (func(), error) {
+	hours := int(s.clock.Now().Sub(clock.Start) / time.Hour)
+	if hours == s.persisted {
+		return func() {}, nil
+	}
+
+	_, err := tx.Exec(`INSERT INTO clock (id, hours) VALUES (0, ?) ON CONFLICT (id) DO UPDATE SET hours = excluded.hours`, hours)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		s.persisted = hours
+	}, nil
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	database "github.com/aaydin-tr/e-commerce/pkg/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	db, err := database.Open(path)
+	require.NoError(t, err)
+
+	systemClock := clock.New(clock.Start)
+	store, err := NewClockStore(db, systemClock)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Start, systemClock.Now())
+
+	systemClock.Advance(5 * time.Hour)
+	err = database.Flush(db, store)
+	assert.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	t.Run("reopened store restores the time", func(t *testing.T) {
+		db, err := database.Open(path)
+		require.NoError(t, err)
+		defer db.Close()
+
+		reopened := clock.New(clock.Start)
+		_, err = NewClockStore(db, reopened)
+		assert.NoError(t, err)
+		assert.Equal(t, clock.Start.Add(5*time.Hour), reopened.Now())
+	})
+}
@@ -0,0 +1,49 @@
+package lock
+
+import "sync"
+
+// Keyed hands out one mutex per key. A key's mutex only exists while it is
+// held or waited on, so keys can be created freely without leaking memory.
+type Keyed struct {
+	mu    sync.Mutex
+	locks map[any]*entry
+}
+
+type entry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func New() *Keyed {
+	return &Keyed{
+		locks: make(map[any]*entry),
+	}
+}
+
+func (k *Keyed) Lock(key any) {
+	k.mu.Lock()
+	e, ok := k.locks[key]
+	if !ok {
+		e = &entry{}
+		k.locks[key] = e
+	}
+	e.refs++
+	k.mu.Unlock()
+
+	e.mu.Lock()
+}
+
+func (k *Keyed) Unlock(key any) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.locks[key]
+	if !ok {
+		panic("lock: unlock of unlocked key")
+	}
+
+	e.refs--
+	if e.refs == 0 {
+		delete(k.locks, key)
+	}
+	e.mu.Unlock()
+}
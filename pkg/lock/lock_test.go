@@ -0,0 +1,46 @@
+package lock
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedLock(t *testing.T) {
+	k := New()
+	counters := map[string]int{"a": 0, "b": 0}
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		for _, key := range []string{"a", "b"} {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				k.Lock(key)
+				defer k.Unlock(key)
+
+				mu.Lock()
+				value := counters[key]
+				mu.Unlock()
+
+				mu.Lock()
+				counters[key] = value + 1
+				mu.Unlock()
+			}(key)
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, counters["a"])
+	assert.Equal(t, 100, counters["b"])
+	assert.Len(t, k.locks, 0)
+}
+
+func TestKeyedUnlockOfUnlockedKey(t *testing.T) {
+	k := New()
+	assert.Panics(t, func() {
+		k.Unlock("a")
+	})
+}
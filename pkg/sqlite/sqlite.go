@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"database/sql"
+	"sort"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the database file at path, creating it if it does not exist.
+// A single connection is used so writes never wait on each other.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Migrate applies the migrations of component that have not been applied yet.
+// Migrations are never edited once released; new ones are appended instead.
+func Migrate(db *sql.DB, component string, migrations []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (component TEXT PRIMARY KEY, version INTEGER NOT NULL)`)
+	if err != nil {
+		return err
+	}
+
+	version := 0
+	err = tx.QueryRow(`SELECT version FROM schema_migrations WHERE component = ?`, component).Scan(&version)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		_, err = tx.Exec(migrations[version])
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO schema_migrations (component, version) VALUES (?, ?) ON CONFLICT (component) DO UPDATE SET version = excluded.version`, component, version)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Flusher writes the changes made to a repository since its last flush.
+type Flusher interface {
+	// Flush writes the changes within tx and returns a function to call
+	// once tx is committed.
+	Flush(tx *sql.Tx) (func(), error)
+}
+
+// Flush writes the changes of every flusher in a single transaction.
+func Flush(db *sql.DB, flushers ...Flusher) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var committed []func()
+	for _, flusher := range flushers {
+		done, err := flusher.Flush(tx)
+		if err != nil {
+			return err
+		}
+		committed = append(committed, done)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for _, done := range committed {
+		done()
+	}
+
+	return nil
+}
+
+// Changes compares the rows a repository holds with the rows last persisted
+// and returns the rows to write and the keys to delete, ordered by key.
+func Changes[R comparable](persisted map[string]R, current map[string]R) ([]R, []string) {
+	var upserts []R
+	var deletes []string
+
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		row := current[key]
+		if previous, ok := persisted[key]; !ok || previous != row {
+			upserts = append(upserts, row)
+		}
+	}
+
+	for key := range persisted {
+		if _, ok := current[key]; !ok {
+			deletes = append(deletes, key)
+		}
+	}
+	sort.Strings(deletes)
+
+	return upserts, deletes
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations := []string{`CREATE TABLE items (name TEXT PRIMARY KEY)`}
+	err = Migrate(db, "items", migrations)
+	assert.NoError(t, err)
+
+	t.Run("applied migrations are skipped", func(t *testing.T) {
+		err := Migrate(db, "items", migrations)
+		assert.NoError(t, err)
+	})
+
+	t.Run("new migrations are applied", func(t *testing.T) {
+		migrations = append(migrations, `ALTER TABLE items ADD COLUMN price REAL NOT NULL DEFAULT 0`)
+		err := Migrate(db, "items", migrations)
+		assert.NoError(t, err)
+
+		_, err = db.Exec(`INSERT INTO items (name, price) VALUES ('P1', 10)`)
+		assert.NoError(t, err)
+
+		var version int
+		err = db.QueryRow(`SELECT version FROM schema_migrations WHERE component = 'items'`).Scan(&version)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+	})
+
+	t.Run("failed migration is not recorded", func(t *testing.T) {
+		err := Migrate(db, "items", append(migrations, `INVALID`))
+		assert.Error(t, err)
+
+		var version int
+		err = db.QueryRow(`SELECT version FROM schema_migrations WHERE component = 'items'`).Scan(&version)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+	})
+}
+
+func TestChanges(t *testing.T) {
+	persisted := map[string]int{"a": 1, "b": 2, "c": 3}
+	current := map[string]int{"a": 1, "b": 5, "d": 4}
+
+	upserts, deletes := Changes(persisted, current)
+	assert.Equal(t, []int{5, 4}, upserts)
+	assert.Equal(t, []string{"c"}, deletes)
+}
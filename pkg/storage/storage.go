@@ -1,22 +1,82 @@
 package storage
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type Storage[T any] struct {
-	datas map[string]T
-	mu    sync.RWMutex
+	datas   map[string]T
+	indexes map[string]*index[T]
+	mu      sync.RWMutex
+}
+
+// index maps the value returned by extract to the keys of the items holding
+// it. Items whose value is empty are not indexed.
+type index[T any] struct {
+	extract func(T) string
+	keys    map[string]map[string]struct{}
+	values  map[string]string
 }
 
 func New[T any]() *Storage[T] {
 	return &Storage[T]{
-		datas: make(map[string]T),
+		datas:   make(map[string]T),
+		indexes: make(map[string]*index[T]),
+	}
+}
+
+// AddIndex declares a secondary index named name over the value extract
+// returns for each item. Values are read when an item is set, so an item must
+// be set again after a change to its indexed value.
+func (s *Storage[T]) AddIndex(name string, extract func(T) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := &index[T]{
+		extract: extract,
+		keys:    make(map[string]map[string]struct{}),
+		values:  make(map[string]string),
+	}
+	for key, value := range s.datas {
+		idx.add(key, value)
+	}
+
+	s.indexes[name] = idx
+}
+
+// GetBy returns the items whose indexed value equals value, ordered by key.
+// It panics if no index named name was added.
+func (s *Storage[T]) GetBy(name string, value string) []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx, ok := s.indexes[name]
+	if !ok {
+		panic("storage: unknown index " + name)
+	}
+
+	keys := make([]string, 0, len(idx.keys[value]))
+	for key := range idx.keys[value] {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+
+	result := make([]T, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, s.datas[key])
+	}
+	return result
 }
 
 func (s *Storage[T]) Set(key string, value T) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.datas[key] = value
+	for _, idx := range s.indexes {
+		idx.remove(key)
+		idx.add(key, value)
+	}
 }
 
 func (s *Storage[T]) Get(key string) (T, bool) {
@@ -30,6 +90,9 @@ func (s *Storage[T]) Delete(key string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.datas, key)
+	for _, idx := range s.indexes {
+		idx.remove(key)
+	}
 }
 
 func (s *Storage[T]) Len() int {
@@ -62,4 +125,34 @@ func (s *Storage[T]) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.datas = make(map[string]T)
+	for _, idx := range s.indexes {
+		idx.keys = make(map[string]map[string]struct{})
+		idx.values = make(map[string]string)
+	}
+}
+
+func (idx *index[T]) add(key string, item T) {
+	value := idx.extract(item)
+	if value == "" {
+		return
+	}
+
+	if idx.keys[value] == nil {
+		idx.keys[value] = make(map[string]struct{})
+	}
+	idx.keys[value][key] = struct{}{}
+	idx.values[key] = value
+}
+
+func (idx *index[T]) remove(key string) {
+	value, ok := idx.values[key]
+	if !ok {
+		return
+	}
+
+	delete(idx.keys[value], key)
+	if len(idx.keys[value]) == 0 {
+		delete(idx.keys, value)
+	}
+	delete(idx.values, key)
 }
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	name  string
+	group string
+}
+
+func TestStorageIndex(t *testing.T) {
+	s := New[*item]()
+	s.Set("a", &item{name: "a", group: "g1"})
+	s.AddIndex("group", func(value *item) string {
+		return value.group
+	})
+
+	t.Run("existing items are indexed", func(t *testing.T) {
+		result := s.GetBy("group", "g1")
+		assert.Len(t, result, 1)
+		assert.Equal(t, "a", result[0].name)
+	})
+
+	t.Run("set indexes items ordered by key", func(t *testing.T) {
+		s.Set("c", &item{name: "c", group: "g1"})
+		s.Set("b", &item{name: "b", group: "g1"})
+
+		var names []string
+		for _, value := range s.GetBy("group", "g1") {
+			names = append(names, value.name)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, names)
+	})
+
+	t.Run("set moves items between values", func(t *testing.T) {
+		s.Set("b", &item{name: "b", group: "g2"})
+
+		assert.Len(t, s.GetBy("group", "g1"), 2)
+		assert.Len(t, s.GetBy("group", "g2"), 1)
+	})
+
+	t.Run("empty values are not indexed", func(t *testing.T) {
+		s.Set("d", &item{name: "d"})
+		assert.Len(t, s.GetBy("group", ""), 0)
+	})
+
+	t.Run("delete removes items", func(t *testing.T) {
+		s.Delete("a")
+		assert.Len(t, s.GetBy("group", "g1"), 1)
+	})
+
+	t.Run("clear removes every item", func(t *testing.T) {
+		s.Clear()
+		assert.Len(t, s.GetBy("group", "g1"), 0)
+		assert.Len(t, s.GetBy("group", "g2"), 0)
+	})
+
+	t.Run("unknown index", func(t *testing.T) {
+		assert.Panics(t, func() { s.GetBy("missing", "g1") })
+	})
+}
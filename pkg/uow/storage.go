@@ -0,0 +1,63 @@
+package uow
+
+import "github.com/aaydin-tr/e-commerce/types"
+
+// Storage wraps a types.Storage and records the previous value of every key it
+// writes, so writes made inside a transaction are undone on rollback.
+type Storage[T any] struct {
+	uow     types.UnitOfWork
+	storage types.Storage[T]
+}
+
+func NewStorage[T any](uow types.UnitOfWork, storage types.Storage[T]) *Storage[T] {
+	return &Storage[T]{
+		uow:     uow,
+		storage: storage,
+	}
+}
+
+func (s *Storage[T]) Set(key string, value T) {
+	s.record(key)
+	s.storage.Set(key, value)
+}
+
+func (s *Storage[T]) Get(key string) (T, bool) {
+	return s.storage.Get(key)
+}
+
+func (s *Storage[T]) Delete(key string) {
+	s.record(key)
+	s.storage.Delete(key)
+}
+
+func (s *Storage[T]) Len() int {
+	return s.storage.Len()
+}
+
+func (s *Storage[T]) Keys() []string {
+	return s.storage.Keys()
+}
+
+func (s *Storage[T]) Values() []T {
+	return s.storage.Values()
+}
+
+func (s *Storage[T]) AddIndex(name string, extract func(T) string) {
+	s.storage.AddIndex(name, extract)
+}
+
+func (s *Storage[T]) GetBy(name string, value string) []T {
+	return s.storage.GetBy(name, value)
+}
+
+func (s *Storage[T]) record(key string) {
+	previous, ok := s.storage.Get(key)
+	s.uow.OnRollback(func() {
+		if ok {
+			s.storage.Set(key, previous)
+			return
+		}
+
+		s.storage.Delete(key)
+	})
+}
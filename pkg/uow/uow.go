@@ -0,0 +1,91 @@
+package uow
+
+import (
+	"sync"
+
+	"github.com/aaydin-tr/e-commerce/types"
+)
+
+// UnitOfWork groups changes made to storages and entities so they are either
+// all kept or all undone. Transactions run one at a time and must not be nested.
+type UnitOfWork struct {
+	txMu    sync.Mutex
+	mu      sync.Mutex
+	active  bool
+	journal []func()
+}
+
+func New() *UnitOfWork {
+	return &UnitOfWork{}
+}
+
+// Do runs fn as a single transaction. If fn returns an error or panics every
+// change recorded with OnRollback is undone in reverse order.
+func (u *UnitOfWork) Do(fn func() error) (err error) {
+	u.txMu.Lock()
+	defer u.txMu.Unlock()
+
+	u.begin()
+	defer func() {
+		if r := recover(); r != nil {
+			u.rollback()
+			panic(r)
+		}
+
+		if err != nil {
+			u.rollback()
+			return
+		}
+
+		u.commit()
+	}()
+
+	return fn()
+}
+
+// OnRollback records fn to be called if the running transaction fails.
+// Outside of a transaction it does nothing.
+func (u *UnitOfWork) OnRollback(fn func()) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !u.active {
+		return
+	}
+
+	u.journal = append(u.journal, fn)
+}
+
+func (u *UnitOfWork) begin() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.active = true
+	u.journal = nil
+}
+
+func (u *UnitOfWork) commit() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.active = false
+	u.journal = nil
+}
+
+func (u *UnitOfWork) rollback() {
+	u.mu.Lock()
+	journal := u.journal
+	u.active = false
+	u.journal = nil
+	u.mu.Unlock()
+
+	for i := len(journal) - 1; i >= 0; i-- {
+		journal[i]()
+	}
+}
+
+// Track snapshots the current value of entity so it is restored when the
+// running transaction is rolled back.
+func Track[T any](u types.UnitOfWork, entity *T) {
+	snapshot := *entity
+	u.OnRollback(func() {
+		*entity = snapshot
+	})
+}
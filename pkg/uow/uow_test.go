@@ -0,0 +1,115 @@
+package uow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	value int
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	unitOfWork := New()
+	s := NewStorage[*item](unitOfWork, storage.New[*item]())
+	tracked := &item{value: 1}
+
+	err := unitOfWork.Do(func() error {
+		Track(unitOfWork, tracked)
+		tracked.value = 2
+		s.Set("k1", tracked)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tracked.value)
+	value, ok := s.Get("k1")
+	assert.True(t, ok)
+	assert.Same(t, tracked, value)
+}
+
+func TestUnitOfWorkRollback(t *testing.T) {
+	unitOfWork := New()
+	s := NewStorage[*item](unitOfWork, storage.New[*item]())
+	existing := &item{value: 1}
+	removed := &item{value: 2}
+	s.Set("existing", existing)
+	s.Set("removed", removed)
+
+	returnErr := errors.New("error")
+	err := unitOfWork.Do(func() error {
+		Track(unitOfWork, existing)
+		existing.value = 10
+		s.Set("existing", &item{value: 11})
+		s.Set("created", &item{value: 12})
+		s.Delete("removed")
+		return returnErr
+	})
+
+	assert.ErrorIs(t, err, returnErr)
+	assert.Equal(t, 1, existing.value)
+	assert.Equal(t, 2, s.Len())
+
+	value, ok := s.Get("existing")
+	assert.True(t, ok)
+	assert.Same(t, existing, value)
+
+	value, ok = s.Get("removed")
+	assert.True(t, ok)
+	assert.Same(t, removed, value)
+
+	_, ok = s.Get("created")
+	assert.False(t, ok)
+}
+
+func TestUnitOfWorkRollbackIndex(t *testing.T) {
+	unitOfWork := New()
+	s := NewStorage[*item](unitOfWork, storage.New[*item]())
+	s.AddIndex("parity", func(value *item) string {
+		if value.value%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	})
+	s.Set("k1", &item{value: 1})
+
+	err := unitOfWork.Do(func() error {
+		s.Set("k1", &item{value: 2})
+		s.Set("k2", &item{value: 4})
+		return errors.New("error")
+	})
+
+	assert.Error(t, err)
+	assert.Len(t, s.GetBy("parity", "odd"), 1)
+	assert.Len(t, s.GetBy("parity", "even"), 0)
+}
+
+func TestUnitOfWorkRollbackOnPanic(t *testing.T) {
+	unitOfWork := New()
+	s := NewStorage[*item](unitOfWork, storage.New[*item]())
+
+	assert.Panics(t, func() {
+		unitOfWork.Do(func() error {
+			s.Set("k1", &item{value: 1})
+			panic("boom")
+		})
+	})
+
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestUnitOfWorkOutsideTransaction(t *testing.T) {
+	unitOfWork := New()
+	s := NewStorage[*item](unitOfWork, storage.New[*item]())
+	s.Set("k1", &item{value: 1})
+
+	err := unitOfWork.Do(func() error {
+		return errors.New("error")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, s.Len())
+}
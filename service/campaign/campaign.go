@@ -4,7 +4,10 @@ import (
 	"errors"
 
 	"github.com/aaydin-tr/e-commerce/domain/campaign"
+	productDomain "github.com/aaydin-tr/e-commerce/domain/product"
 	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 )
@@ -12,78 +15,193 @@ import (
 var (
 	ErrNoCampaign                          = errors.New("No campaign found")
 	ErrTargetSalesCountMustBeLessThanStock = errors.New("Target sales count must be less than stock")
+	ErrNothingToAmend                      = errors.New("Amendment must change at least one parameter")
+	ErrDurationExtensionMustBePositive     = errors.New("Duration extension must be positive")
+	ErrCampaignEnded                       = errors.New("Ended campaign cannot be amended")
+	ErrCampaignAlreadyEnded                = errors.New("Campaign has already ended")
+	ErrTargetSalesCountMustExceedSales     = errors.New("Target sales count must be greater than total sales")
+	ErrParentCampaignCountsOnOrder         = errors.New("Campaign on a parent product must count backorders on order")
+	ErrCategoryHasNoProducts               = errors.New("Category has no products")
+	ErrMemberHasCampaign                   = errors.New("Product of the category already has a running campaign")
+	ErrProductHasCampaign                  = errors.New("Product already has a running campaign")
 )
 
 type CampaignServiceInterface interface {
-	Create(campaignName string, product *entity.Product, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int) error
+	Create(campaignName string, product *entity.Product, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int, campaignStockPolicy string, campaignBackorderPolicy string) error
+	CreateForCategory(campaignName string, category *entity.Category, members []*entity.Product, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int, campaignStockPolicy string, campaignBackorderPolicy string) (*entity.Campaign, error)
 	Get(campaignName string) (*entity.Campaign, error)
 	GetAll() ([]*entity.Campaign, error)
+	Amend(campaignName string, durationExtension int, priceManipulationLimit int, targetSalesCount int) (*entity.Campaign, error)
+	Cancel(campaignName string) (*entity.Campaign, error)
 }
 
 type CampaignService struct {
 	campaignRepository campaign.CampaignRepository
+	productRepository  productDomain.ProductRepository
+	unitOfWork         types.UnitOfWork
+	clock              types.Clock
 }
 
-func NewCampaignService(campaignRepository campaign.CampaignRepository) *CampaignService {
+func NewCampaignService(campaignRepository campaign.CampaignRepository, productRepository productDomain.ProductRepository, unitOfWork types.UnitOfWork, clock types.Clock) *CampaignService {
 	return &CampaignService{
 		campaignRepository: campaignRepository,
+		productRepository:  productRepository,
+		unitOfWork:         unitOfWork,
+		clock:              clock,
 	}
 }
 
-func (c *CampaignService) Create(campaignName string, product *entity.Product, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int) error {
-	name, err := valueobject.NewName(campaignName)
+// Create starts a campaign on product. A product already running a campaign,
+// of its own or of its category, cannot start another one.
+func (c *CampaignService) Create(campaignName string, product *entity.Product, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int, campaignStockPolicy string, campaignBackorderPolicy string) error {
+	newCampaign, err := c.newCampaign(campaignName, campaignDuration, campaignPriceManipulationLimit, campaignTargetSalesCount, campaignStockPolicy, campaignBackorderPolicy)
+	if err != nil {
+		return err
+	}
+
+	product.Acquire()
+	defer product.Release()
+
+	if product.Campaign != nil {
+		product.Campaign.Acquire()
+		running := !product.Campaign.IsEnded()
+		product.Campaign.Release()
+
+		if running {
+			return ErrProductHasCampaign
+		}
+	}
+
+	// Backorders of a variant are fulfilled without its parent, so a campaign
+	// on the parent counts them when they are ordered.
+	if product.IsParent() && newCampaign.CountsOnFulfillment() {
+		return ErrParentCampaignCountsOnOrder
+	}
+
+	if product.Stock.Value() < newCampaign.TargetSalesCount.Value() {
+		return ErrTargetSalesCountMustBeLessThanStock
+	}
+
+	newCampaign.Product = product
+	err = c.campaignRepository.Create(newCampaign)
+
 	if err != nil {
 		return err
 	}
 
+	product.Campaign = newCampaign
+
+	return nil
+
+}
+
+// CreateForCategory starts a campaign on category whose price manipulation
+// applies to each of members, the products of the category and its
+// subcategories, and whose target counts the sales of them all. Members
+// already running a campaign of their own cannot join, and together the
+// members must hold the stock for the target.
+func (c *CampaignService) CreateForCategory(campaignName string, category *entity.Category, members []*entity.Product, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int, campaignStockPolicy string, campaignBackorderPolicy string) (*entity.Campaign, error) {
+	newCampaign, err := c.newCampaign(campaignName, campaignDuration, campaignPriceManipulationLimit, campaignTargetSalesCount, campaignStockPolicy, campaignBackorderPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, ErrCategoryHasNoProducts
+	}
+
+	for _, member := range members {
+		member.Acquire()
+		running := false
+		if member.Campaign != nil {
+			member.Campaign.Acquire()
+			running = !member.Campaign.IsEnded()
+			member.Campaign.Release()
+		}
+		parent := member.IsParent()
+		member.Release()
+
+		if running {
+			return nil, ErrMemberHasCampaign
+		}
+
+		if parent && newCampaign.CountsOnFulfillment() {
+			return nil, ErrParentCampaignCountsOnOrder
+		}
+	}
+
+	if memberStock(members) < newCampaign.TargetSalesCount.Value() {
+		return nil, ErrTargetSalesCountMustBeLessThanStock
+	}
+
+	newCampaign.Category = category.Code
+	newCampaign.Members = members
+	err = c.campaignRepository.Create(newCampaign)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		member.Acquire()
+		member.Campaign = newCampaign
+		member.Release()
+	}
+
+	return newCampaign, nil
+}
+
+// newCampaign validates the parameters shared by product and category
+// campaigns and returns an active campaign without a target.
+func (c *CampaignService) newCampaign(campaignName string, campaignDuration int, campaignPriceManipulationLimit int, campaignTargetSalesCount int, campaignStockPolicy string, campaignBackorderPolicy string) (*entity.Campaign, error) {
+	name, err := valueobject.NewName(campaignName)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.campaignRepository.Exist(name) {
-		return campaign.ErrCampaignAlreadyExist
+		return nil, campaign.ErrCampaignAlreadyExist
 	}
 
 	duration, err := valueobject.NewDuration(campaignDuration)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	priceManipulationLimit, err := valueobject.NewPriceManipulationLimit(campaignPriceManipulationLimit)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	targetSalesCount, err := valueobject.NewTargetSalesCount(campaignTargetSalesCount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if product.Stock.Value() < targetSalesCount.Value() {
-		return ErrTargetSalesCountMustBeLessThanStock
+	stockPolicy, err := valueobject.NewStockPolicy(campaignStockPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	backorderPolicy, err := valueobject.NewBackorderPolicy(campaignBackorderPolicy)
+	if err != nil {
+		return nil, err
 	}
 
 	status, err := valueobject.NewStatus(valueobject.Active)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	newCampaign := &entity.Campaign{
+	return &entity.Campaign{
 		ID:                     uuid.New(),
 		Name:                   name,
-		Product:                product,
 		Duration:               duration,
 		PriceManipulationLimit: priceManipulationLimit,
 		TargetSalesCount:       targetSalesCount,
+		StockPolicy:            stockPolicy,
+		BackorderPolicy:        backorderPolicy,
 		Status:                 status,
-	}
-
-	err = c.campaignRepository.Create(newCampaign)
-
-	if err != nil {
-		return err
-	}
-
-	product.Campaign = newCampaign
-
-	return nil
-
+	}, nil
 }
 
 func (c *CampaignService) Get(campaignName string) (*entity.Campaign, error) {
@@ -108,3 +226,188 @@ func (c *CampaignService) GetAll() ([]*entity.Campaign, error) {
 
 	return campaigns, nil
 }
+
+// Amend changes the parameters of a running campaign and records the change
+// in its amendment log. A zero argument leaves the parameter unchanged. The
+// units still to sell for the new target must be in stock.
+func (c *CampaignService) Amend(campaignName string, durationExtension int, priceManipulationLimit int, targetSalesCount int) (*entity.Campaign, error) {
+	if durationExtension == 0 && priceManipulationLimit == 0 && targetSalesCount == 0 {
+		return nil, ErrNothingToAmend
+	}
+
+	if durationExtension < 0 {
+		return nil, ErrDurationExtensionMustBePositive
+	}
+
+	existingCampaign, err := c.Get(campaignName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The members of a category campaign are locked one at a time before
+	// the campaign, so their stock is read first.
+	var stock int
+	members := categoryMembers(existingCampaign)
+	if len(members) > 0 {
+		stock = memberStock(members)
+	}
+
+	product := existingCampaign.Product
+	if product != nil {
+		product.Acquire()
+		defer product.Release()
+		stock = product.Stock.Value()
+	}
+	existingCampaign.Acquire()
+	defer existingCampaign.Release()
+
+	if existingCampaign.IsEnded() {
+		return nil, ErrCampaignEnded
+	}
+
+	duration, err := valueobject.NewDuration(existingCampaign.Duration.Value() + durationExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := existingCampaign.PriceManipulationLimit
+	if priceManipulationLimit != 0 {
+		limit, err = valueobject.NewPriceManipulationLimit(priceManipulationLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	target := existingCampaign.TargetSalesCount
+	if targetSalesCount != 0 {
+		target, err = valueobject.NewTargetSalesCount(targetSalesCount)
+		if err != nil {
+			return nil, err
+		}
+
+		if target.Value() <= existingCampaign.TotalSales.Value() {
+			return nil, ErrTargetSalesCountMustExceedSales
+		}
+
+		if (product != nil || len(members) > 0) && stock < target.Value()-existingCampaign.TotalSales.Value() {
+			return nil, ErrTargetSalesCountMustBeLessThanStock
+		}
+	}
+
+	err = c.unitOfWork.Do(func() error {
+		campaignVersion := existingCampaign.Version
+		uow.Track(c.unitOfWork, existingCampaign)
+
+		existingCampaign.Duration = duration
+		existingCampaign.PriceManipulationLimit = limit
+		existingCampaign.TargetSalesCount = target
+		existingCampaign.Amendments = append(existingCampaign.Amendments, entity.CampaignAmendment{
+			Time:                   c.clock.Now(),
+			DurationExtension:      durationExtension,
+			PriceManipulationLimit: priceManipulationLimit,
+			TargetSalesCount:       targetSalesCount,
+		})
+
+		return c.campaignRepository.Update(existingCampaign, campaignVersion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return existingCampaign, nil
+}
+
+// Cancel ends a running campaign before its duration runs out and resets the
+// price of its product, or of every member of a category campaign. The
+// variants following the price of a parent move with it.
+func (c *CampaignService) Cancel(campaignName string) (*entity.Campaign, error) {
+	existingCampaign, err := c.Get(campaignName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.cancel(existingCampaign)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range categoryMembers(existingCampaign) {
+		err = c.detach(existingCampaign, member)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return existingCampaign, nil
+}
+
+func (c *CampaignService) cancel(existingCampaign *entity.Campaign) error {
+	product := existingCampaign.Product
+	var variants []*entity.Product
+	if product != nil {
+		product.Acquire()
+		defer product.Release()
+
+		var release func()
+		variants, release = lockVariants(c.productRepository, product)
+		defer release()
+	}
+	existingCampaign.Acquire()
+	defer existingCampaign.Release()
+
+	if existingCampaign.IsEnded() {
+		return ErrCampaignAlreadyEnded
+	}
+
+	return c.unitOfWork.Do(func() error {
+		campaignVersion := existingCampaign.Version
+		uow.Track(c.unitOfWork, existingCampaign)
+		if product != nil {
+			uow.Track(c.unitOfWork, product)
+		}
+
+		err := existingCampaign.Cancel(c.clock.Now())
+		if err != nil {
+			return err
+		}
+
+		err = c.followParent(product, variants)
+		if err != nil {
+			return err
+		}
+
+		return c.campaignRepository.Update(existingCampaign, campaignVersion)
+	})
+}
+
+// detach resets the price of member once the category campaign has ended.
+func (c *CampaignService) detach(existingCampaign *entity.Campaign, member *entity.Product) error {
+	member.Acquire()
+	defer member.Release()
+	variants, release := lockVariants(c.productRepository, member)
+	defer release()
+
+	return c.unitOfWork.Do(func() error {
+		uow.Track(c.unitOfWork, member)
+		existingCampaign.Detach(member)
+		return c.followParent(member, variants)
+	})
+}
+
+// followParent moves the variants of parent that follow its price to it. The
+// variants must be locked.
+func (c *CampaignService) followParent(parent *entity.Product, variants []*entity.Product) error {
+	for _, variant := range variants {
+		version := variant.Version
+		uow.Track(c.unitOfWork, variant)
+
+		if variant.FollowParent(parent) {
+			err := c.productRepository.Update(variant, version)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
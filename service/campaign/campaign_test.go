@@ -3,29 +3,39 @@ package campaign
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aaydin-tr/e-commerce/domain/campaign"
 	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 
 	mockCampaign "github.com/aaydin-tr/e-commerce/mock/repository/campaign"
+	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+// amendTime is the clock value campaigns are amended at in tests.
+var amendTime = time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC)
+
 var mockCampaignRepo *mockCampaign.MockCampaignRepository
+var mockProductRepo *mockProduct.MockProductRepository
 
 func setup(t *testing.T) (*CampaignService, func()) {
 	ct := gomock.NewController(t)
 
 	mockCampaignRepo = mockCampaign.NewMockCampaignRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
 
-	campaignService := NewCampaignService(mockCampaignRepo)
+	campaignService := NewCampaignService(mockCampaignRepo, mockProductRepo, uow.New(), clock.New(amendTime))
 
 	return campaignService, func() {
 		ct.Finish()
 		mockCampaignRepo = nil
+		mockProductRepo = nil
 	}
 }
 
@@ -33,10 +43,16 @@ func TestNewCampaignService(t *testing.T) {
 	ct := gomock.NewController(t)
 
 	mockCampaignRepo = mockCampaign.NewMockCampaignRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
 
-	campaignService := NewCampaignService(mockCampaignRepo)
+	unitOfWork := uow.New()
+	campaignClock := clock.New(amendTime)
+	campaignService := NewCampaignService(mockCampaignRepo, mockProductRepo, unitOfWork, campaignClock)
 
 	assert.Equal(t, campaignService.campaignRepository, mockCampaignRepo)
+	assert.Equal(t, campaignService.productRepository, mockProductRepo)
+	assert.Equal(t, campaignService.unitOfWork, unitOfWork)
+	assert.Equal(t, campaignService.clock, campaignClock)
 
 	ct.Finish()
 }
@@ -52,42 +68,65 @@ func TestCampaignServiceCreateCampaign(t *testing.T) {
 	mockProduct := &entity.Product{Code: code, Stock: stokc, Price: price}
 
 	t.Run("should return error when campaign name is invalid", func(t *testing.T) {
-		err := campaignService.Create("", mockProduct, 10, 20, 100)
+		err := campaignService.Create("", mockProduct, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.NotNil(t, err)
 	})
 
 	t.Run("should return error when campaign name is already exist", func(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(true)
 
-		err := campaignService.Create("C1", mockProduct, 10, 20, 100)
+		err := campaignService.Create("C1", mockProduct, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.ErrorIs(t, err, campaign.ErrCampaignAlreadyExist)
 	})
 
 	t.Run("should return error when campaign duration is invalid", func(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
 
-		err := campaignService.Create("C1", mockProduct, -1, 20, 100)
+		err := campaignService.Create("C1", mockProduct, -1, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.NotNil(t, err)
 	})
 
 	t.Run("should return error when campaign price manipulation limit is invalid", func(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
 
-		err := campaignService.Create("C1", mockProduct, 10, 0, 100)
+		err := campaignService.Create("C1", mockProduct, 10, 0, 100, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.NotNil(t, err)
 	})
 
 	t.Run("should return error when campaign target sales count is invalid", func(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
 
-		err := campaignService.Create("C1", mockProduct, 10, 20, 0)
+		err := campaignService.Create("C1", mockProduct, 10, 20, 0, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.NotNil(t, err)
 	})
 
+	t.Run("should return error when campaign stock policy is invalid", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		err := campaignService.Create("C1", mockProduct, 10, 20, 50, "Stop", valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, valueobject.ErrStockPolicyMustBeOneOf)
+	})
+
+	t.Run("should return error when campaign backorder policy is invalid", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		err := campaignService.Create("C1", mockProduct, 10, 20, 50, valueobject.EndOnStockOut, "Never")
+		assert.ErrorIs(t, err, valueobject.ErrBackorderPolicyMustBeOneOf)
+	})
+
+	t.Run("should return error when campaign on a parent counts backorders on fulfillment", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+		variant, _ := valueobject.NewCode("V1")
+		parent := &entity.Product{Code: code, Stock: stokc, Price: price, Variants: []valueobject.Code{variant}}
+
+		err := campaignService.Create("C1", parent, 10, 20, 100, valueobject.EndOnStockOut, valueobject.CountOnFulfillment)
+		assert.ErrorIs(t, err, ErrParentCampaignCountsOnOrder)
+	})
+
 	t.Run("should return error when campaign target sales count is greater than product stock", func(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
 
-		err := campaignService.Create("C1", mockProduct, 10, 20, 200)
+		err := campaignService.Create("C1", mockProduct, 10, 20, 200, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.ErrorIs(t, err, ErrTargetSalesCountMustBeLessThanStock)
 	})
 
@@ -96,7 +135,7 @@ func TestCampaignServiceCreateCampaign(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
 		mockCampaignRepo.EXPECT().Create(gomock.Any()).Return(returnErr)
 
-		err := campaignService.Create("C1", mockProduct, 10, 20, 50)
+		err := campaignService.Create("C1", mockProduct, 10, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
 		assert.ErrorIs(t, err, returnErr)
 
 	})
@@ -105,10 +144,30 @@ func TestCampaignServiceCreateCampaign(t *testing.T) {
 		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
 		mockCampaignRepo.EXPECT().Create(gomock.Any()).Return(nil)
 
-		err := campaignService.Create("C1", mockProduct, 10, 20, 50)
+		err := campaignService.Create("C1", mockProduct, 10, 20, 50, valueobject.PauseOnStockOut, valueobject.CountOnFulfillment)
 		assert.Nil(t, err)
+		assert.Equal(t, valueobject.PauseOnStockOut, mockProduct.Campaign.StockPolicy.Value())
+		assert.Equal(t, valueobject.CountOnFulfillment, mockProduct.Campaign.BackorderPolicy.Value())
 	})
 
+	t.Run("should return error when product already has a running campaign", func(t *testing.T) {
+		running := mockProduct.Campaign
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		err := campaignService.Create("C2", mockProduct, 10, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, ErrProductHasCampaign)
+		assert.Same(t, running, mockProduct.Campaign)
+	})
+
+	t.Run("should start a campaign once the previous one has ended", func(t *testing.T) {
+		mockProduct.Campaign.End(valueobject.Expired, amendTime)
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+		mockCampaignRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		err := campaignService.Create("C2", mockProduct, 10, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.NoError(t, err)
+		assert.Equal(t, "C2", mockProduct.Campaign.Name.Value())
+	})
 }
 
 func TestCampaignServiceGetCampaignInfo(t *testing.T) {
@@ -202,3 +261,277 @@ func TestCampaignServiceGetAllCampaigns(t *testing.T) {
 		assert.Equal(t, c[0].TargetSalesCount, targetSalesCount)
 	})
 }
+
+func TestCampaignServiceAmend(t *testing.T) {
+	campaignService, teardown := setup(t)
+	defer teardown()
+
+	newCampaign := func(status string) *entity.Campaign {
+		name, _ := valueobject.NewName("C1")
+		code, _ := valueobject.NewCode("P1")
+		stock, _ := valueobject.NewStock(40)
+		duration, _ := valueobject.NewDuration(5)
+		priceManipulationLimit, _ := valueobject.NewPriceManipulationLimit(20)
+		targetSalesCount, _ := valueobject.NewTargetSalesCount(50)
+		totalSales, _ := valueobject.NewQuantity(20)
+		campaignStatus, _ := valueobject.NewStatus(status)
+		product := &entity.Product{Code: code, Stock: stock}
+		return &entity.Campaign{
+			Name:                   name,
+			Product:                product,
+			Duration:               duration,
+			PriceManipulationLimit: priceManipulationLimit,
+			TargetSalesCount:       targetSalesCount,
+			TotalSales:             totalSales,
+			Status:                 campaignStatus,
+		}
+	}
+
+	t.Run("should return error when nothing changes", func(t *testing.T) {
+		_, err := campaignService.Amend("C1", 0, 0, 0)
+		assert.ErrorIs(t, err, ErrNothingToAmend)
+	})
+
+	t.Run("should return error when duration extension is negative", func(t *testing.T) {
+		_, err := campaignService.Amend("C1", -1, 0, 0)
+		assert.ErrorIs(t, err, ErrDurationExtensionMustBePositive)
+	})
+
+	t.Run("should return error when campaign is not found", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(nil, campaign.ErrCampaignNotFound)
+
+		_, err := campaignService.Amend("C1", 3, 0, 0)
+		assert.ErrorIs(t, err, campaign.ErrCampaignNotFound)
+	})
+
+	t.Run("should return error when campaign has ended", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(newCampaign(valueobject.Ended), nil)
+
+		_, err := campaignService.Amend("C1", 3, 0, 0)
+		assert.ErrorIs(t, err, ErrCampaignEnded)
+	})
+
+	t.Run("should return error when limit is invalid", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(newCampaign(valueobject.Active), nil)
+
+		_, err := campaignService.Amend("C1", 0, -5, 0)
+		assert.ErrorIs(t, err, valueobject.ErrPriceManipulationLimitLessThanZero)
+	})
+
+	t.Run("should return error when target is not above total sales", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(newCampaign(valueobject.Active), nil)
+
+		_, err := campaignService.Amend("C1", 0, 0, 20)
+		assert.ErrorIs(t, err, ErrTargetSalesCountMustExceedSales)
+	})
+
+	t.Run("should return error when remaining target exceeds stock", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(newCampaign(valueobject.Active), nil)
+
+		_, err := campaignService.Amend("C1", 0, 0, 61)
+		assert.ErrorIs(t, err, ErrTargetSalesCountMustBeLessThanStock)
+	})
+
+	t.Run("should keep campaign when update fails", func(t *testing.T) {
+		existing := newCampaign(valueobject.Active)
+		returnErr := errors.New("error")
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(existing, nil)
+		mockCampaignRepo.EXPECT().Update(existing, 0).Return(returnErr)
+
+		_, err := campaignService.Amend("C1", 3, 30, 60)
+		assert.ErrorIs(t, err, returnErr)
+		assert.Equal(t, 5, existing.Duration.Value())
+		assert.Equal(t, 20, existing.PriceManipulationLimit.Value())
+		assert.Equal(t, 50, existing.TargetSalesCount.Value())
+		assert.Len(t, existing.Amendments, 0)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := newCampaign(valueobject.Active)
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(existing, nil)
+		mockCampaignRepo.EXPECT().Update(existing, 0).Return(nil)
+
+		result, err := campaignService.Amend("C1", 3, 0, 60)
+		assert.NoError(t, err)
+		assert.Same(t, existing, result)
+		assert.Equal(t, 8, result.Duration.Value())
+		assert.Equal(t, 20, result.PriceManipulationLimit.Value())
+		assert.Equal(t, 60, result.TargetSalesCount.Value())
+		assert.Equal(t, []entity.CampaignAmendment{{Time: amendTime, DurationExtension: 3, TargetSalesCount: 60}}, result.Amendments)
+	})
+}
+
+func TestCampaignServiceCancel(t *testing.T) {
+	campaignService, teardown := setup(t)
+	defer teardown()
+
+	newCampaign := func() (*entity.Campaign, *entity.Product) {
+		name, _ := valueobject.NewName("C1")
+		status, _ := valueobject.NewStatus(valueobject.Active)
+		initialPrice, _ := valueobject.NewPrice(100)
+		price, _ := valueobject.NewPrice(85)
+		product := &entity.Product{Price: price, InititalPrice: initialPrice}
+		existing := &entity.Campaign{Name: name, Product: product, Status: status}
+		product.Campaign = existing
+		return existing, product
+	}
+
+	t.Run("should return error when campaign is not found", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(nil, campaign.ErrCampaignNotFound)
+
+		_, err := campaignService.Cancel("C1")
+		assert.ErrorIs(t, err, campaign.ErrCampaignNotFound)
+	})
+
+	t.Run("should return error when campaign has already ended", func(t *testing.T) {
+		existing, _ := newCampaign()
+		existing.End(valueobject.Expired, amendTime)
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(existing, nil)
+
+		_, err := campaignService.Cancel("C1")
+		assert.ErrorIs(t, err, ErrCampaignAlreadyEnded)
+		assert.Equal(t, valueobject.Expired, existing.EndReason.Value())
+	})
+
+	t.Run("should keep campaign and product when update fails", func(t *testing.T) {
+		existing, product := newCampaign()
+		returnErr := errors.New("error")
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(existing, nil)
+		mockCampaignRepo.EXPECT().Update(existing, 0).Return(returnErr)
+
+		_, err := campaignService.Cancel("C1")
+		assert.ErrorIs(t, err, returnErr)
+		assert.True(t, existing.IsActive())
+		assert.Same(t, existing, product.Campaign)
+		assert.Equal(t, 85.0, product.Price.Value())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing, product := newCampaign()
+		mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(existing, nil)
+		mockCampaignRepo.EXPECT().Update(existing, 0).Return(nil)
+
+		result, err := campaignService.Cancel("C1")
+		assert.NoError(t, err)
+		assert.Same(t, existing, result)
+		assert.Equal(t, valueobject.Ended, result.Status.Value())
+		assert.Equal(t, valueobject.Cancelled, result.EndReason.Value())
+		assert.Equal(t, amendTime, result.EndedAt)
+		assert.Equal(t, 85.0, result.FinalPrice.Value())
+		assert.Nil(t, product.Campaign)
+		assert.Equal(t, 100.0, product.Price.Value())
+	})
+}
+
+func TestCampaignServiceCreateForCategory(t *testing.T) {
+	campaignService, teardown := setup(t)
+	defer teardown()
+
+	categoryCode, _ := valueobject.NewCode("C1")
+	category := &entity.Category{Code: categoryCode}
+	newMember := func(productCode string, stockCount int) *entity.Product {
+		code, _ := valueobject.NewCode(productCode)
+		stock, _ := valueobject.NewStock(stockCount)
+		return &entity.Product{Code: code, Stock: stock}
+	}
+
+	t.Run("should return error when campaign name is already exist", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(true)
+
+		_, err := campaignService.CreateForCategory("S1", category, []*entity.Product{newMember("P1", 50)}, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, campaign.ErrCampaignAlreadyExist)
+	})
+
+	t.Run("should return error when category has no products", func(t *testing.T) {
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		_, err := campaignService.CreateForCategory("S1", category, nil, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, ErrCategoryHasNoProducts)
+	})
+
+	t.Run("should return error when a member runs a campaign", func(t *testing.T) {
+		busy := newMember("P2", 50)
+		status, _ := valueobject.NewStatus(valueobject.Active)
+		busy.Campaign = &entity.Campaign{Product: busy, Status: status}
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		_, err := campaignService.CreateForCategory("S1", category, []*entity.Product{newMember("P1", 50), busy}, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, ErrMemberHasCampaign)
+	})
+
+	t.Run("should return error when a parent member counts backorders on fulfillment", func(t *testing.T) {
+		parent := newMember("T1", 50)
+		parent.Variants = []valueobject.Code{newMember("V1", 0).Code}
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		_, err := campaignService.CreateForCategory("S1", category, []*entity.Product{parent}, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnFulfillment)
+		assert.ErrorIs(t, err, ErrParentCampaignCountsOnOrder)
+	})
+
+	t.Run("should return error when members do not hold the target", func(t *testing.T) {
+		parent := newMember("T1", 20)
+		variant := newMember("V1", 20)
+		variant.Parent = parent.Code
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+
+		_, err := campaignService.CreateForCategory("S1", category, []*entity.Product{parent, variant}, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, ErrTargetSalesCountMustBeLessThanStock)
+		assert.Nil(t, parent.Campaign)
+	})
+
+	t.Run("should keep members when campaign repo create returns error", func(t *testing.T) {
+		member := newMember("P1", 50)
+		returnErr := errors.New("error")
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+		mockCampaignRepo.EXPECT().Create(gomock.Any()).Return(returnErr)
+
+		_, err := campaignService.CreateForCategory("S1", category, []*entity.Product{member}, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.ErrorIs(t, err, returnErr)
+		assert.Nil(t, member.Campaign)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		p1, p2 := newMember("P1", 20), newMember("P2", 10)
+		ended, _ := valueobject.NewStatus(valueobject.Ended)
+		p2.Campaign = &entity.Campaign{Product: p2, Status: ended}
+		mockCampaignRepo.EXPECT().Exist(gomock.Any()).Return(false)
+		mockCampaignRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		result, err := campaignService.CreateForCategory("S1", category, []*entity.Product{p1, p2}, 10, 20, 30, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		assert.NoError(t, err)
+		assert.True(t, result.IsCategory())
+		assert.Nil(t, result.Product)
+		assert.Equal(t, categoryCode, result.Category)
+		assert.Equal(t, []*entity.Product{p1, p2}, result.Members)
+		assert.Same(t, result, p1.Campaign)
+		assert.Same(t, result, p2.Campaign)
+	})
+}
+
+func TestCampaignServiceCancelCategory(t *testing.T) {
+	campaignService, teardown := setup(t)
+	defer teardown()
+
+	name, _ := valueobject.NewName("S1")
+	categoryCode, _ := valueobject.NewCode("C1")
+	status, _ := valueobject.NewStatus(valueobject.Active)
+	initialPrice, _ := valueobject.NewPrice(100)
+	price, _ := valueobject.NewPrice(85)
+	member := &entity.Product{Price: price, InititalPrice: initialPrice}
+	other := &entity.Product{Price: price, InititalPrice: initialPrice}
+	existing := &entity.Campaign{Name: name, Category: categoryCode, Members: []*entity.Product{member, other}, Status: status}
+	member.Campaign = existing
+	other.Campaign = &entity.Campaign{Product: other, Status: status}
+
+	mockCampaignRepo.EXPECT().Get(gomock.Any()).Return(existing, nil)
+	mockCampaignRepo.EXPECT().Update(existing, 0).Return(nil)
+
+	result, err := campaignService.Cancel("S1")
+	assert.NoError(t, err)
+	assert.Equal(t, valueobject.Cancelled, result.EndReason.Value())
+	assert.Equal(t, 0.0, result.FinalPrice.Value())
+	assert.Nil(t, member.Campaign)
+	assert.Equal(t, 100.0, member.Price.Value())
+	assert.NotNil(t, other.Campaign)
+	assert.Equal(t, 85.0, other.Price.Value())
+}
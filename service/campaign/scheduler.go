@@ -0,0 +1,179 @@
+package campaign
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/campaign"
+	productDomain "github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+)
+
+var (
+	ErrCampaignDoesNotHaveProduct = errors.New("Campaign does not have product")
+)
+
+type SchedulerInterface interface {
+	Tick(now time.Time) error
+	Reset(now time.Time)
+}
+
+// Scheduler moves campaigns forward as the simulation clock advances. Every
+// elapsed hour is a separate step, so each hour adjusts prices once. The
+// variants following the price of a parent move with it.
+type Scheduler struct {
+	campaignRepository campaign.CampaignRepository
+	productRepository  productDomain.ProductRepository
+	mu                 sync.Mutex
+	last               time.Time
+}
+
+// NewScheduler returns a scheduler whose first tick counts hours from start.
+func NewScheduler(campaignRepository campaign.CampaignRepository, productRepository productDomain.ProductRepository, start time.Time) *Scheduler {
+	return &Scheduler{
+		campaignRepository: campaignRepository,
+		productRepository:  productRepository,
+		last:               start,
+	}
+}
+
+// Tick advances every campaign one hour at a time for each full hour between
+// the previous tick and now. Having no campaigns is not an error.
+func (s *Scheduler) Tick(now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.last.Add(time.Hour).After(now) {
+		hour := s.last.Add(time.Hour)
+		for _, item := range s.campaignRepository.GetAll() {
+			if item.IsCategory() {
+				s.advanceCategory(item, hour)
+				continue
+			}
+
+			if item.Product == nil {
+				return ErrCampaignDoesNotHaveProduct
+			}
+
+			s.advance(item, hour)
+		}
+
+		s.last = hour
+	}
+
+	return nil
+}
+
+// Reset moves the scheduler to now without advancing any campaign, for when
+// the clock is set to a saved time.
+func (s *Scheduler) Reset(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.last = now
+}
+
+func (s *Scheduler) advance(item *entity.Campaign, now time.Time) {
+	product := item.Product
+	product.Acquire()
+	defer product.Release()
+	variants, release := lockVariants(s.productRepository, product)
+	defer release()
+	item.Acquire()
+	defer item.Release()
+
+	item.Advance(now)
+	for _, variant := range variants {
+		variant.FollowParent(product)
+	}
+}
+
+// advanceCategory advances a category campaign and then adjusts the price of
+// every member still in it, or removes the campaign from its members once it
+// has ended. Members are locked one at a time, each with its variants before
+// the campaign.
+func (s *Scheduler) advanceCategory(item *entity.Campaign, now time.Time) {
+	members := categoryMembers(item)
+	stock := memberStock(members)
+
+	item.Acquire()
+	item.AdvanceCategory(now, stock)
+	item.Release()
+
+	for _, member := range members {
+		member.Acquire()
+		variants, release := lockVariants(s.productRepository, member)
+		item.Acquire()
+		if item.IsEnded() {
+			item.Detach(member)
+		} else if item.IsActive() && member.Campaign == item {
+			member.Discount(item.PriceManipulationLimit)
+		}
+		for _, variant := range variants {
+			variant.FollowParent(member)
+		}
+		item.Release()
+		release()
+		member.Release()
+	}
+}
+
+// lockVariants looks up and locks the variants of parent in code order. Other
+// products have none. The parent lock must be held and no campaign lock. The
+// returned function releases them.
+func lockVariants(productRepository productDomain.ProductRepository, parent *entity.Product) ([]*entity.Product, func()) {
+	variants := make([]*entity.Product, 0, len(parent.Variants))
+	for _, code := range parent.Variants {
+		variant, err := productRepository.Get(code)
+		if err != nil {
+			continue
+		}
+
+		variants = append(variants, variant)
+	}
+
+	for _, variant := range variants {
+		variant.Acquire()
+	}
+
+	return variants, func() {
+		for _, variant := range variants {
+			variant.Release()
+		}
+	}
+}
+
+// categoryMembers returns the members of a category campaign, read under its
+// lock.
+func categoryMembers(item *entity.Campaign) []*entity.Product {
+	item.Acquire()
+	defer item.Release()
+
+	return item.Members
+}
+
+// memberStock returns the total stock of the members of a category campaign.
+// A variant whose parent is a member too is counted through its parent. The
+// members are locked one at a time.
+func memberStock(members []*entity.Product) int {
+	codes := make(map[string]bool, len(members))
+	parents := make([]string, len(members))
+	stocks := make([]int, len(members))
+	for i, member := range members {
+		member.Acquire()
+		codes[member.Code.Value()] = true
+		parents[i] = member.Parent.Value()
+		stocks[i] = member.Stock.Value()
+		member.Release()
+	}
+
+	var total int
+	for i := range members {
+		if !codes[parents[i]] {
+			total += stocks[i]
+		}
+	}
+
+	return total
+}
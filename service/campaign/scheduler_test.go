@@ -0,0 +1,175 @@
+package campaign
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/stretchr/testify/assert"
+)
+
+// tickStart is the time schedulers count hours from in tests.
+var tickStart = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func setupScheduler(t *testing.T, duration int) (*Scheduler, *entity.Campaign) {
+	campaignRepository := memory.NewCampaignRepository(storage.New[*entity.Campaign]())
+	campaignService := NewCampaignService(campaignRepository, nil, nil, nil)
+
+	code, _ := valueobject.NewCode("P1")
+	stock, _ := valueobject.NewStock(100)
+	price, _ := valueobject.NewPrice(100)
+	product := &entity.Product{Code: code, Stock: stock, InititalStock: stock, Price: price, InititalPrice: price}
+
+	err := campaignService.Create("C1", product, duration, 20, 50, valueobject.PauseOnStockOut, valueobject.CountOnOrder)
+	assert.NoError(t, err)
+
+	campaign, _ := campaignService.Get("C1")
+	return NewScheduler(campaignRepository, productRepo.NewProductRepository(storage.New[*entity.Product]()), tickStart), campaign
+}
+
+func TestSchedulerTick(t *testing.T) {
+	t.Run("should not return error when there is no campaign", func(t *testing.T) {
+		scheduler := NewScheduler(memory.NewCampaignRepository(storage.New[*entity.Campaign]()), productRepo.NewProductRepository(storage.New[*entity.Product]()), tickStart)
+
+		err := scheduler.Tick(tickStart.Add(3 * time.Hour))
+		assert.NoError(t, err)
+	})
+
+	t.Run("should advance campaigns once per elapsed hour", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 10)
+
+		err := scheduler.Tick(tickStart.Add(5 * time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 5, campaign.Duration.Value())
+	})
+
+	t.Run("should carry partial hours over to the next tick", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 10)
+
+		scheduler.Tick(tickStart.Add(90 * time.Minute))
+		assert.Equal(t, 9, campaign.Duration.Value())
+
+		scheduler.Tick(tickStart.Add(2 * time.Hour))
+		assert.Equal(t, 8, campaign.Duration.Value())
+	})
+
+	t.Run("should end campaign at the hour its duration runs out", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 2)
+
+		scheduler.Tick(tickStart.Add(5 * time.Hour))
+		assert.Equal(t, valueobject.Ended, campaign.Status.Value())
+		assert.Equal(t, valueobject.Expired, campaign.EndReason.Value())
+		assert.Equal(t, tickStart.Add(2*time.Hour), campaign.EndedAt)
+	})
+
+	t.Run("should adjust the price every hour", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 10)
+		campaign.Product.IncreaseDemand(10)
+
+		scheduler.Tick(tickStart.Add(2 * time.Hour))
+		assert.Equal(t, 80.0, campaign.Product.Price.Value())
+	})
+
+	t.Run("should keep paused campaign duration until stock is back", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 10)
+		campaign.Product.DecreaseStock(60)
+
+		scheduler.Tick(tickStart.Add(3 * time.Hour))
+		assert.Equal(t, valueobject.Paused, campaign.Status.Value())
+		assert.Equal(t, 9, campaign.Duration.Value())
+
+		campaign.Product.Stock, _ = valueobject.NewStock(100)
+		scheduler.Tick(tickStart.Add(4 * time.Hour))
+		assert.Equal(t, valueobject.Active, campaign.Status.Value())
+		assert.Equal(t, 8, campaign.Duration.Value())
+	})
+
+	t.Run("should keep the remaining duration of ended campaign", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 10)
+
+		scheduler.Tick(tickStart.Add(2 * time.Hour))
+		err := campaign.Cancel(tickStart.Add(2 * time.Hour))
+		assert.NoError(t, err)
+
+		scheduler.Tick(tickStart.Add(5 * time.Hour))
+		assert.Equal(t, valueobject.Ended, campaign.Status.Value())
+		assert.Equal(t, 8, campaign.Duration.Value())
+	})
+
+	t.Run("should return error when campaign does not have product", func(t *testing.T) {
+		scheduler, campaign := setupScheduler(t, 10)
+		campaign.Product = nil
+
+		err := scheduler.Tick(tickStart.Add(time.Hour))
+		assert.ErrorIs(t, err, ErrCampaignDoesNotHaveProduct)
+	})
+}
+
+func TestSchedulerReset(t *testing.T) {
+	scheduler, campaign := setupScheduler(t, 10)
+
+	scheduler.Reset(tickStart.Add(5 * time.Hour))
+	scheduler.Tick(tickStart.Add(6 * time.Hour))
+	assert.Equal(t, 9, campaign.Duration.Value())
+}
+
+func setupCategoryScheduler(t *testing.T, duration int) (*Scheduler, *entity.Campaign) {
+	campaignRepository := memory.NewCampaignRepository(storage.New[*entity.Campaign]())
+	campaignService := NewCampaignService(campaignRepository, nil, nil, nil)
+
+	var members []*entity.Product
+	for _, productCode := range []string{"P1", "P2"} {
+		code, _ := valueobject.NewCode(productCode)
+		stock, _ := valueobject.NewStock(50)
+		price, _ := valueobject.NewPrice(100)
+		members = append(members, &entity.Product{Code: code, Stock: stock, InititalStock: stock, Price: price, InititalPrice: price})
+	}
+
+	categoryCode, _ := valueobject.NewCode("C1")
+	campaign, err := campaignService.CreateForCategory("S1", &entity.Category{Code: categoryCode}, members, duration, 20, 60, valueobject.PauseOnStockOut, valueobject.CountOnOrder)
+	assert.NoError(t, err)
+
+	return NewScheduler(campaignRepository, productRepo.NewProductRepository(storage.New[*entity.Product]()), tickStart), campaign
+}
+
+func TestSchedulerTickCategory(t *testing.T) {
+	t.Run("should adjust the price of every member", func(t *testing.T) {
+		scheduler, campaign := setupCategoryScheduler(t, 10)
+		p1, p2 := campaign.Members[0], campaign.Members[1]
+		p1.IncreaseDemand(10)
+		p2.IncreaseDemand(10)
+		p2.DecreaseStock(10)
+
+		err := scheduler.Tick(tickStart.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 9, campaign.Duration.Value())
+		assert.Equal(t, 80.0, p1.Price.Value())
+		assert.Equal(t, 120.0, p2.Price.Value())
+	})
+
+	t.Run("should pause when the members no longer hold the remaining target", func(t *testing.T) {
+		scheduler, campaign := setupCategoryScheduler(t, 10)
+		campaign.Members[0].DecreaseStock(45)
+
+		scheduler.Tick(tickStart.Add(time.Hour))
+		assert.Equal(t, valueobject.Paused, campaign.Status.Value())
+	})
+
+	t.Run("should reset members once the campaign ends", func(t *testing.T) {
+		scheduler, campaign := setupCategoryScheduler(t, 2)
+		p1 := campaign.Members[0]
+		p1.IncreaseDemand(10)
+
+		scheduler.Tick(tickStart.Add(time.Hour))
+		assert.Equal(t, 80.0, p1.Price.Value())
+
+		scheduler.Tick(tickStart.Add(2 * time.Hour))
+		assert.Equal(t, valueobject.Expired, campaign.EndReason.Value())
+		assert.Nil(t, p1.Campaign)
+		assert.Equal(t, 100.0, p1.Price.Value())
+	})
+}
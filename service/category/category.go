@@ -0,0 +1,153 @@
+package category
+
+import (
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+type CategoryServiceInterface interface {
+	Create(categoryCode string, parentCode string) error
+	Get(categoryCode string) (*entity.Category, error)
+	GetAll() []*entity.Category
+	Assign(productCode string, categoryCode string) (*entity.Product, error)
+	Members(categoryCode string) ([]*entity.Product, error)
+}
+
+type CategoryService struct {
+	categoryRepository category.CategoryRepository
+	productRepository  product.ProductRepository
+	unitOfWork         types.UnitOfWork
+}
+
+func NewCategoryService(categoryRepository category.CategoryRepository, productRepository product.ProductRepository, unitOfWork types.UnitOfWork) *CategoryService {
+	return &CategoryService{
+		categoryRepository: categoryRepository,
+		productRepository:  productRepository,
+		unitOfWork:         unitOfWork,
+	}
+}
+
+// Create adds a category below the parent category, or at the top of the
+// taxonomy when parentCode is empty. The parent must already exist.
+func (s *CategoryService) Create(categoryCode string, parentCode string) error {
+	code, err := valueobject.NewCode(categoryCode)
+	if err != nil {
+		return err
+	}
+
+	var parent valueobject.Code
+	if parentCode != "" {
+		stored, err := s.Get(parentCode)
+		if err != nil {
+			return err
+		}
+		parent = stored.Code
+	}
+
+	return s.categoryRepository.Create(&entity.Category{
+		ID:     uuid.New(),
+		Code:   code,
+		Parent: parent,
+	})
+}
+
+func (s *CategoryService) Get(categoryCode string) (*entity.Category, error) {
+	code, err := valueobject.NewCode(categoryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.categoryRepository.Get(code)
+}
+
+func (s *CategoryService) GetAll() []*entity.Category {
+	return s.categoryRepository.GetAll()
+}
+
+// Assign moves the product to the category. Campaigns already running on a
+// category keep the members they started with.
+func (s *CategoryService) Assign(productCode string, categoryCode string) (*entity.Product, error) {
+	stored, err := s.Get(categoryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.productRepository.Get(code)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	err = s.unitOfWork.Do(func() error {
+		uow.Track(s.unitOfWork, result)
+		version := result.Version
+		result.Category = stored.Code
+		return s.productRepository.Update(result, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Members returns the products assigned to the category or to any category
+// below it, ordered by code.
+func (s *CategoryService) Members(categoryCode string) ([]*entity.Product, error) {
+	stored, err := s.Get(categoryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := s.tree(stored.Code)
+
+	var result []*entity.Product
+	for _, item := range s.productRepository.GetAll() {
+		item.Acquire()
+		member := tree[item.Category.Value()]
+		item.Release()
+
+		if member {
+			result = append(result, item)
+		}
+	}
+
+	return result, nil
+}
+
+// tree returns the codes of root and every category below it.
+func (s *CategoryService) tree(root valueobject.Code) map[string]bool {
+	children := make(map[string][]string)
+	for _, item := range s.categoryRepository.GetAll() {
+		children[item.Parent.Value()] = append(children[item.Parent.Value()], item.Code.Value())
+	}
+
+	result := map[string]bool{root.Value(): true}
+	pending := []string{root.Value()}
+	for len(pending) > 0 {
+		code := pending[0]
+		pending = pending[1:]
+		for _, child := range children[code] {
+			if result[child] {
+				continue
+			}
+
+			result[child] = true
+			pending = append(pending, child)
+		}
+	}
+
+	return result
+}
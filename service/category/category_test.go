@@ -0,0 +1,178 @@
+package category
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	mockCategory "github.com/aaydin-tr/e-commerce/mock/repository/category"
+	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockCategoryRepo *mockCategory.MockCategoryRepository
+	mockProductRepo  *mockProduct.MockProductRepository
+)
+
+func setup(t *testing.T) (*CategoryService, func()) {
+	ct := gomock.NewController(t)
+
+	mockCategoryRepo = mockCategory.NewMockCategoryRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+
+	categoryService := NewCategoryService(mockCategoryRepo, mockProductRepo, uow.New())
+
+	return categoryService, func() {
+		ct.Finish()
+		mockCategoryRepo = nil
+		mockProductRepo = nil
+	}
+}
+
+func newCategory(code string, parent string) *entity.Category {
+	categoryCode, _ := valueobject.NewCode(code)
+	var parentCode valueobject.Code
+	if parent != "" {
+		parentCode, _ = valueobject.NewCode(parent)
+	}
+	return &entity.Category{ID: uuid.New(), Code: categoryCode, Parent: parentCode}
+}
+
+func newProduct(code string, category string) *entity.Product {
+	productCode, _ := valueobject.NewCode(code)
+	var categoryCode valueobject.Code
+	if category != "" {
+		categoryCode, _ = valueobject.NewCode(category)
+	}
+	return &entity.Product{ID: uuid.New(), Code: productCode, Category: categoryCode}
+}
+
+func TestNewCategoryService(t *testing.T) {
+	ct := gomock.NewController(t)
+
+	mockCategoryRepo = mockCategory.NewMockCategoryRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+
+	unitOfWork := uow.New()
+	categoryService := NewCategoryService(mockCategoryRepo, mockProductRepo, unitOfWork)
+
+	assert.Equal(t, categoryService.categoryRepository, mockCategoryRepo)
+	assert.Equal(t, categoryService.productRepository, mockProductRepo)
+	assert.Equal(t, categoryService.unitOfWork, unitOfWork)
+
+	ct.Finish()
+}
+
+func TestCategoryServiceCreate(t *testing.T) {
+	categoryService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when category code is invalid", func(t *testing.T) {
+		err := categoryService.Create("", "")
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when parent is not found", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(gomock.Any()).Return(nil, category.ErrNotFound)
+		err := categoryService.Create("C2", "C1")
+		assert.ErrorIs(t, err, category.ErrNotFound)
+	})
+
+	t.Run("should return error when category already exist", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Create(gomock.Any()).Return(category.ErrAlreadyExist)
+		err := categoryService.Create("C1", "")
+		assert.ErrorIs(t, err, category.ErrAlreadyExist)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		parent := newCategory("C1", "")
+		mockCategoryRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockCategoryRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(created *entity.Category) error {
+			assert.Equal(t, "C2", created.Code.Value())
+			assert.Equal(t, parent.Code, created.Parent)
+			return nil
+		})
+		err := categoryService.Create("C2", "C1")
+		assert.NoError(t, err)
+	})
+}
+
+func TestCategoryServiceAssign(t *testing.T) {
+	categoryService, teardown := setup(t)
+	defer teardown()
+
+	stored := newCategory("C1", "")
+
+	t.Run("should return error when category is not found", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(stored.Code).Return(nil, category.ErrNotFound)
+		_, err := categoryService.Assign("P1", "C1")
+		assert.ErrorIs(t, err, category.ErrNotFound)
+	})
+
+	t.Run("should return error when product is not found", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(stored.Code).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(gomock.Any()).Return(nil, product.ErrNotFound)
+		_, err := categoryService.Assign("P1", "C1")
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+
+	t.Run("should keep category when update fails", func(t *testing.T) {
+		existing := newProduct("P1", "")
+		mockCategoryRepo.EXPECT().Get(stored.Code).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(existing.Code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(errors.New("update failed"))
+		_, err := categoryService.Assign("P1", "C1")
+		assert.Error(t, err)
+		assert.Equal(t, "", existing.Category.Value())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := newProduct("P1", "")
+		mockCategoryRepo.EXPECT().Get(stored.Code).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(existing.Code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil)
+		result, err := categoryService.Assign("P1", "C1")
+		assert.NoError(t, err)
+		assert.Same(t, existing, result)
+		assert.Equal(t, stored.Code, existing.Category)
+	})
+}
+
+func TestCategoryServiceMembers(t *testing.T) {
+	categoryService, teardown := setup(t)
+	defer teardown()
+
+	c1, c2, c3, c4 := newCategory("C1", ""), newCategory("C2", "C1"), newCategory("C3", "C2"), newCategory("C4", "")
+	p1, p2, p3, p4 := newProduct("P1", "C1"), newProduct("P2", "C3"), newProduct("P3", "C4"), newProduct("P4", "")
+
+	t.Run("should return error when category is not found", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(gomock.Any()).Return(nil, category.ErrNotFound)
+		_, err := categoryService.Members("C1")
+		assert.ErrorIs(t, err, category.ErrNotFound)
+	})
+
+	t.Run("should include the products of subcategories", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(c1.Code).Return(c1, nil)
+		mockCategoryRepo.EXPECT().GetAll().Return([]*entity.Category{c1, c2, c3, c4})
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{p1, p2, p3, p4})
+		result, err := categoryService.Members("C1")
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.Product{p1, p2}, result)
+	})
+
+	t.Run("should leave out the products of parent categories", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(c2.Code).Return(c2, nil)
+		mockCategoryRepo.EXPECT().GetAll().Return([]*entity.Category{c1, c2, c3, c4})
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{p1, p2, p3, p4})
+		result, err := categoryService.Members("C2")
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.Product{p2}, result)
+	})
+}
@@ -0,0 +1,123 @@
+package coupon
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUsageLimitCannotBeNegative = errors.New("Usage limit cannot be negative")
+	ErrExpiryCannotBeNegative     = errors.New("Expiry cannot be negative")
+)
+
+type CouponServiceInterface interface {
+	Create(couponCode string, discountType string, amount float64, usageLimit int, expiresIn int, productCodes []string, categoryCodes []string) (*entity.Coupon, error)
+	Get(couponCode string) (*entity.Coupon, error)
+}
+
+type CouponService struct {
+	couponRepository   coupon.CouponRepository
+	productRepository  product.ProductRepository
+	categoryRepository category.CategoryRepository
+	clock              types.Clock
+}
+
+func NewCouponService(couponRepository coupon.CouponRepository, productRepository product.ProductRepository, categoryRepository category.CategoryRepository, clock types.Clock) *CouponService {
+	return &CouponService{
+		couponRepository:   couponRepository,
+		productRepository:  productRepository,
+		categoryRepository: categoryRepository,
+		clock:              clock,
+	}
+}
+
+// Create adds a coupon that expires expiresIn hours from now, or never when
+// expiresIn is zero, and may be redeemed by usageLimit orders, or any number
+// when usageLimit is zero. The products and categories it is restricted to
+// must exist.
+func (s *CouponService) Create(couponCode string, discountType string, amount float64, usageLimit int, expiresIn int, productCodes []string, categoryCodes []string) (*entity.Coupon, error) {
+	code, err := valueobject.NewCode(couponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := valueobject.NewDiscount(discountType, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if usageLimit < 0 {
+		return nil, ErrUsageLimitCannotBeNegative
+	}
+
+	if expiresIn < 0 {
+		return nil, ErrExpiryCannotBeNegative
+	}
+
+	var products []valueobject.Code
+	for _, value := range productCodes {
+		productCode, err := valueobject.NewCode(value)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = s.productRepository.Get(productCode)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, productCode)
+	}
+
+	var categories []valueobject.Code
+	for _, value := range categoryCodes {
+		categoryCode, err := valueobject.NewCode(value)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = s.categoryRepository.Get(categoryCode)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, categoryCode)
+	}
+
+	var expiresAt time.Time
+	if expiresIn > 0 {
+		expiresAt = s.clock.Now().Add(time.Duration(expiresIn) * time.Hour)
+	}
+
+	newCoupon := &entity.Coupon{
+		ID:         uuid.New(),
+		Code:       code,
+		Discount:   discount,
+		UsageLimit: usageLimit,
+		ExpiresAt:  expiresAt,
+		Products:   products,
+		Categories: categories,
+	}
+
+	err = s.couponRepository.Create(newCoupon)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCoupon, nil
+}
+
+func (s *CouponService) Get(couponCode string) (*entity.Coupon, error) {
+	code, err := valueobject.NewCode(couponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.couponRepository.Get(code)
+}
@@ -0,0 +1,156 @@
+package coupon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	mockCategory "github.com/aaydin-tr/e-commerce/mock/repository/category"
+	mockCoupon "github.com/aaydin-tr/e-commerce/mock/repository/coupon"
+	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockCouponRepo   *mockCoupon.MockCouponRepository
+	mockProductRepo  *mockProduct.MockProductRepository
+	mockCategoryRepo *mockCategory.MockCategoryRepository
+	couponTime       = time.Date(0, 0, 0, 5, 0, 0, 0, time.UTC)
+)
+
+func setup(t *testing.T) (*CouponService, func()) {
+	ct := gomock.NewController(t)
+
+	mockCouponRepo = mockCoupon.NewMockCouponRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+	mockCategoryRepo = mockCategory.NewMockCategoryRepository(ct)
+
+	couponService := NewCouponService(mockCouponRepo, mockProductRepo, mockCategoryRepo, clock.New(couponTime))
+
+	return couponService, func() {
+		ct.Finish()
+		mockCouponRepo = nil
+		mockProductRepo = nil
+		mockCategoryRepo = nil
+	}
+}
+
+func TestNewCouponService(t *testing.T) {
+	ct := gomock.NewController(t)
+
+	mockCouponRepo = mockCoupon.NewMockCouponRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+	mockCategoryRepo = mockCategory.NewMockCategoryRepository(ct)
+
+	couponClock := clock.New(couponTime)
+	couponService := NewCouponService(mockCouponRepo, mockProductRepo, mockCategoryRepo, couponClock)
+
+	assert.Equal(t, couponService.couponRepository, mockCouponRepo)
+	assert.Equal(t, couponService.productRepository, mockProductRepo)
+	assert.Equal(t, couponService.categoryRepository, mockCategoryRepo)
+	assert.Equal(t, couponService.clock, couponClock)
+
+	ct.Finish()
+}
+
+func TestCouponServiceCreate(t *testing.T) {
+	couponService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when coupon code is invalid", func(t *testing.T) {
+		_, err := couponService.Create("", valueobject.FixedDiscount, 10, 0, 0, nil, nil)
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when discount type is invalid", func(t *testing.T) {
+		_, err := couponService.Create("SAVE10", "Half", 10, 0, 0, nil, nil)
+		assert.ErrorIs(t, err, valueobject.ErrDiscountTypeMustBeOneOf)
+	})
+
+	t.Run("should return error when amount is not positive", func(t *testing.T) {
+		_, err := couponService.Create("SAVE10", valueobject.FixedDiscount, 0, 0, 0, nil, nil)
+		assert.ErrorIs(t, err, valueobject.ErrDiscountAmountMustBePositive)
+	})
+
+	t.Run("should return error when percent is more than 100", func(t *testing.T) {
+		_, err := couponService.Create("SAVE10", valueobject.PercentDiscount, 110, 0, 0, nil, nil)
+		assert.ErrorIs(t, err, valueobject.ErrDiscountPercentTooHigh)
+	})
+
+	t.Run("should return error when usage limit is negative", func(t *testing.T) {
+		_, err := couponService.Create("SAVE10", valueobject.FixedDiscount, 10, -1, 0, nil, nil)
+		assert.ErrorIs(t, err, ErrUsageLimitCannotBeNegative)
+	})
+
+	t.Run("should return error when expiry is negative", func(t *testing.T) {
+		_, err := couponService.Create("SAVE10", valueobject.FixedDiscount, 10, 0, -1, nil, nil)
+		assert.ErrorIs(t, err, ErrExpiryCannotBeNegative)
+	})
+
+	t.Run("should return error when product is not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(gomock.Any()).Return(nil, product.ErrNotFound)
+		_, err := couponService.Create("SAVE10", valueobject.FixedDiscount, 10, 0, 0, []string{"P1"}, nil)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+
+	t.Run("should return error when category is not found", func(t *testing.T) {
+		mockCategoryRepo.EXPECT().Get(gomock.Any()).Return(nil, category.ErrNotFound)
+		_, err := couponService.Create("SAVE10", valueobject.FixedDiscount, 10, 0, 0, nil, []string{"C1"})
+		assert.ErrorIs(t, err, category.ErrNotFound)
+	})
+
+	t.Run("should return error when coupon already exist", func(t *testing.T) {
+		mockCouponRepo.EXPECT().Create(gomock.Any()).Return(coupon.ErrAlreadyExist)
+		_, err := couponService.Create("SAVE10", valueobject.FixedDiscount, 10, 0, 0, nil, nil)
+		assert.ErrorIs(t, err, coupon.ErrAlreadyExist)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		productCode, _ := valueobject.NewCode("P1")
+		categoryCode, _ := valueobject.NewCode("C1")
+		mockProductRepo.EXPECT().Get(productCode).Return(&entity.Product{ID: uuid.New(), Code: productCode}, nil)
+		mockCategoryRepo.EXPECT().Get(categoryCode).Return(&entity.Category{ID: uuid.New(), Code: categoryCode}, nil)
+		mockCouponRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		result, err := couponService.Create("SAVE10", valueobject.PercentDiscount, 10, 3, 24, []string{"P1"}, []string{"C1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "SAVE10", result.Code.Value())
+		assert.Equal(t, valueobject.PercentDiscount, result.Discount.Type())
+		assert.Equal(t, 10.0, result.Discount.Amount())
+		assert.Equal(t, 3, result.UsageLimit)
+		assert.Equal(t, couponTime.Add(24*time.Hour), result.ExpiresAt)
+		assert.Equal(t, []valueobject.Code{productCode}, result.Products)
+		assert.Equal(t, []valueobject.Code{categoryCode}, result.Categories)
+	})
+
+	t.Run("should never expire without expiry", func(t *testing.T) {
+		mockCouponRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		result, err := couponService.Create("FOREVER", valueobject.FixedDiscount, 10, 0, 0, nil, nil)
+		assert.NoError(t, err)
+		assert.True(t, result.ExpiresAt.IsZero())
+	})
+}
+
+func TestCouponServiceGet(t *testing.T) {
+	couponService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when coupon code is invalid", func(t *testing.T) {
+		_, err := couponService.Get("")
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when coupon is not found", func(t *testing.T) {
+		mockCouponRepo.EXPECT().Get(gomock.Any()).Return(nil, coupon.ErrNotFound)
+		_, err := couponService.Get("SAVE10")
+		assert.ErrorIs(t, err, coupon.ErrNotFound)
+	})
+}
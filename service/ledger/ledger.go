@@ -0,0 +1,121 @@
+package ledger
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+var (
+	ErrNoLedger = errors.New("Bundles and parent products have no stock ledger; the products they are made of do")
+)
+
+type LedgerServiceInterface interface {
+	List(productCode string) ([]*entity.StockMovement, error)
+	Reconcile(productCode string) (Reconciliation, error)
+	ReconcileAll() []Reconciliation
+}
+
+// Reconciliation compares the stock of a product with the sum of its stock
+// movements.
+type Reconciliation struct {
+	Code    string
+	Balance int
+	Stock   int
+}
+
+// Balanced reports whether the ledger accounts for the whole stock.
+func (r Reconciliation) Balanced() bool {
+	return r.Balance == r.Stock
+}
+
+type LedgerService struct {
+	ledgerRepository  ledger.LedgerRepository
+	productRepository product.ProductRepository
+}
+
+func NewLedgerService(ledgerRepository ledger.LedgerRepository, productRepository product.ProductRepository) *LedgerService {
+	return &LedgerService{
+		ledgerRepository:  ledgerRepository,
+		productRepository: productRepository,
+	}
+}
+
+// List returns the stock movements of the product in ledger order.
+func (s *LedgerService) List(productCode string) ([]*entity.StockMovement, error) {
+	result, err := s.get(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ledgerRepository.ListByProduct(result.ID), nil
+}
+
+func (s *LedgerService) Reconcile(productCode string) (Reconciliation, error) {
+	result, err := s.get(productCode)
+	if err != nil {
+		return Reconciliation{}, err
+	}
+
+	return s.reconcile(result), nil
+}
+
+// ReconcileAll reconciles every product holding stock of its own, ordered by
+// code.
+func (s *LedgerService) ReconcileAll() []Reconciliation {
+	var result []Reconciliation
+	for _, item := range s.productRepository.GetAll() {
+		if !holdsStock(item) {
+			continue
+		}
+
+		result = append(result, s.reconcile(item))
+	}
+
+	return result
+}
+
+// reconcile holds the product lock, which every stock change holds while it
+// records its movement, so the stock and the ledger are read consistently.
+func (s *LedgerService) reconcile(item *entity.Product) Reconciliation {
+	item.Acquire()
+	defer item.Release()
+
+	reconciliation := Reconciliation{Code: item.Code.Value(), Stock: item.Stock.Value()}
+	for _, movement := range s.ledgerRepository.ListByProduct(item.ID) {
+		reconciliation.Balance += movement.Quantity
+	}
+
+	return reconciliation
+}
+
+func (s *LedgerService) get(productCode string) (*entity.Product, error) {
+	code, err := valueobject.NewCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.productRepository.Get(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !holdsStock(result) {
+		return nil, ErrNoLedger
+	}
+
+	return result, nil
+}
+
+// holdsStock reports whether item has stock of its own and so a ledger. A
+// product turns into a parent when its first variant is created, so the check
+// holds the product lock.
+func holdsStock(item *entity.Product) bool {
+	item.Acquire()
+	defer item.Release()
+
+	return item.HoldsStock()
+}
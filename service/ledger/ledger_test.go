@@ -0,0 +1,163 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/entity"
+	mockLedger "github.com/aaydin-tr/e-commerce/mock/repository/ledger"
+	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockLedgerRepo  *mockLedger.MockLedgerRepository
+	mockProductRepo *mockProduct.MockProductRepository
+)
+
+func setup(t *testing.T) (*LedgerService, func()) {
+	ct := gomock.NewController(t)
+
+	mockLedgerRepo = mockLedger.NewMockLedgerRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+
+	ledgerService := NewLedgerService(mockLedgerRepo, mockProductRepo)
+
+	return ledgerService, func() {
+		ct.Finish()
+		mockLedgerRepo = nil
+		mockProductRepo = nil
+	}
+}
+
+func newProduct(productCode string, stockCount int) *entity.Product {
+	code, _ := valueobject.NewCode(productCode)
+	stock, _ := valueobject.NewStock(stockCount)
+	return &entity.Product{ID: uuid.New(), Code: code, Stock: stock}
+}
+
+func newMovements(productID uuid.UUID, quantities ...int) []*entity.StockMovement {
+	var result []*entity.StockMovement
+	for i, quantity := range quantities {
+		result = append(result, &entity.StockMovement{ID: uuid.New(), Sequence: i + 1, ProductID: productID, Quantity: quantity})
+	}
+	return result
+}
+
+func TestNewLedgerService(t *testing.T) {
+	ct := gomock.NewController(t)
+
+	mockLedgerRepo = mockLedger.NewMockLedgerRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+
+	ledgerService := NewLedgerService(mockLedgerRepo, mockProductRepo)
+
+	assert.Equal(t, ledgerService.ledgerRepository, mockLedgerRepo)
+	assert.Equal(t, ledgerService.productRepository, mockProductRepo)
+
+	ct.Finish()
+}
+
+func TestLedgerServiceList(t *testing.T) {
+	ledgerService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when product code is invalid", func(t *testing.T) {
+		result, err := ledgerService.List("")
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(gomock.Any()).Return(nil, product.ErrNotFound)
+
+		result, err := ledgerService.List("P1")
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product is a bundle", func(t *testing.T) {
+		bundle := newProduct("B1", 2)
+		bundle.Components = []entity.BundleComponent{{Product: newProduct("P1", 0).Code, Quantity: 1}}
+		mockProductRepo.EXPECT().Get(bundle.Code).Return(bundle, nil)
+
+		result, err := ledgerService.List("B1")
+		assert.ErrorIs(t, err, ErrNoLedger)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product is a parent", func(t *testing.T) {
+		parent := newProduct("T1", 6)
+		parent.Variants = []valueobject.Code{newProduct("V1", 6).Code}
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+
+		result, err := ledgerService.List("T1")
+		assert.ErrorIs(t, err, ErrNoLedger)
+		assert.Nil(t, result)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		item := newProduct("P1", 7)
+		movements := newMovements(item.ID, 10, -3)
+		mockProductRepo.EXPECT().Get(item.Code).Return(item, nil)
+		mockLedgerRepo.EXPECT().ListByProduct(item.ID).Return(movements)
+
+		result, err := ledgerService.List("P1")
+		assert.NoError(t, err)
+		assert.Equal(t, movements, result)
+	})
+}
+
+func TestLedgerServiceReconcile(t *testing.T) {
+	ledgerService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when product not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(gomock.Any()).Return(nil, product.ErrNotFound)
+
+		_, err := ledgerService.Reconcile("P1")
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+
+	t.Run("should balance when the ledger accounts for the stock", func(t *testing.T) {
+		item := newProduct("P1", 7)
+		mockProductRepo.EXPECT().Get(item.Code).Return(item, nil)
+		mockLedgerRepo.EXPECT().ListByProduct(item.ID).Return(newMovements(item.ID, 10, -5, 2))
+
+		result, err := ledgerService.Reconcile("P1")
+		assert.NoError(t, err)
+		assert.Equal(t, Reconciliation{Code: "P1", Balance: 7, Stock: 7}, result)
+		assert.True(t, result.Balanced())
+	})
+
+	t.Run("should report a mismatch", func(t *testing.T) {
+		item := newProduct("P1", 7)
+		mockProductRepo.EXPECT().Get(item.Code).Return(item, nil)
+		mockLedgerRepo.EXPECT().ListByProduct(item.ID).Return(newMovements(item.ID, 10))
+
+		result, err := ledgerService.Reconcile("P1")
+		assert.NoError(t, err)
+		assert.False(t, result.Balanced())
+	})
+}
+
+func TestLedgerServiceReconcileAll(t *testing.T) {
+	ledgerService, teardown := setup(t)
+	defer teardown()
+
+	p1, p2 := newProduct("P1", 5), newProduct("P2", 0)
+	bundle := newProduct("B1", 5)
+	bundle.Components = []entity.BundleComponent{{Product: p1.Code, Quantity: 1}}
+	mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{bundle, p1, p2})
+	mockLedgerRepo.EXPECT().ListByProduct(p1.ID).Return(newMovements(p1.ID, 5))
+	mockLedgerRepo.EXPECT().ListByProduct(p2.ID).Return(newMovements(p2.ID, 3))
+
+	result := ledgerService.ReconcileAll()
+	assert.Equal(t, []Reconciliation{
+		{Code: "P1", Balance: 5, Stock: 5},
+		{Code: "P2", Balance: 3, Stock: 0},
+	}, result)
+}
@@ -2,73 +2,960 @@ package order
 
 import (
 	"errors"
+	"time"
 
+	"github.com/aaydin-tr/e-commerce/domain/campaign"
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
 	"github.com/aaydin-tr/e-commerce/domain/order"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
 	entity "github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrInsufficientStock = errors.New("Insufficient stock")
+	ErrInsufficientStock           = errors.New("Insufficient stock")
+	ErrInvalidOrderID              = errors.New("Order ID must be a valid UUID")
+	ErrRestockAmountMustBePositive = errors.New("Restock amount must be positive")
+	ErrCannotBeRestocked           = errors.New("Bundles and parent products are restocked through the products they are made of")
+	ErrParentCannotBeOrdered       = errors.New("Parent product cannot be ordered; order one of its variants")
+	ErrCouponExpired               = errors.New("Coupon has expired")
+	ErrCouponUsedUp                = errors.New("Coupon has reached its usage limit")
+	ErrCouponNotApplicable         = errors.New("Coupon does not apply to the product")
+	ErrNoTaxRate                   = errors.New("Region has no tax rate for the tax class of the product")
 )
 
 type OrderServiceInterface interface {
-	Create(product *entity.Product, orderQuantity int) error
+	Create(product *entity.Product, orderQuantity int) (*entity.Order, error)
+	CreateWithStrategy(product *entity.Product, orderQuantity int, allocationStrategy string, x float64, y float64, coupon *entity.Coupon, region *entity.Region) (*entity.Order, error)
+	Get(orderID string) (*entity.Order, error)
+	ChangeStatus(orderID string, status string) (*entity.Order, error)
+	Restock(product *entity.Product, amount int) ([]*entity.Order, error)
+	FulfillBackorders() error
 }
 
 type OrderService struct {
-	orderRepository order.OrderRepository
+	orderRepository     order.OrderRepository
+	productRepository   product.ProductRepository
+	campaignRepository  campaign.CampaignRepository
+	warehouseRepository warehouse.WarehouseRepository
+	ledgerRepository    ledger.LedgerRepository
+	couponRepository    coupon.CouponRepository
+	categoryRepository  category.CategoryRepository
+	unitOfWork          types.UnitOfWork
+	clock               types.Clock
 }
 
-func NewOrderService(orderRepository order.OrderRepository) *OrderService {
-	return &OrderService{orderRepository: orderRepository}
+func NewOrderService(orderRepository order.OrderRepository, productRepository product.ProductRepository, campaignRepository campaign.CampaignRepository, warehouseRepository warehouse.WarehouseRepository, ledgerRepository ledger.LedgerRepository, couponRepository coupon.CouponRepository, categoryRepository category.CategoryRepository, unitOfWork types.UnitOfWork, clock types.Clock) *OrderService {
+	return &OrderService{
+		orderRepository:     orderRepository,
+		productRepository:   productRepository,
+		campaignRepository:  campaignRepository,
+		warehouseRepository: warehouseRepository,
+		ledgerRepository:    ledgerRepository,
+		couponRepository:    couponRepository,
+		categoryRepository:  categoryRepository,
+		unitOfWork:          unitOfWork,
+		clock:               clock,
+	}
+}
+
+// Create places an order that takes its units from the warehouses nearest to
+// the origin, splitting it when no single warehouse holds enough.
+func (s *OrderService) Create(product *entity.Product, orderQuantity int) (*entity.Order, error) {
+	return s.CreateWithStrategy(product, orderQuantity, valueobject.SplitAcrossWarehouses, 0, 0, nil, nil)
 }
 
-func (s *OrderService) Create(product *entity.Product, orderQuantity int) error {
+// CreateWithStrategy places an order for a customer at x, y and allocates the
+// units in stock to warehouses by allocationStrategy. The order redeems
+// coupon when it is not nil, which takes its discount off the order and
+// counts a use of it. The order is taxed in region when it is not nil, and
+// untaxed otherwise.
+func (s *OrderService) CreateWithStrategy(product *entity.Product, orderQuantity int, allocationStrategy string, x float64, y float64, coupon *entity.Coupon, region *entity.Region) (*entity.Order, error) {
 	quantity, err := valueobject.NewQuantity(orderQuantity)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if product.Stock.Value() < quantity.Value() {
-		return ErrInsufficientStock
+	strategy, err := valueobject.NewAllocationStrategy(allocationStrategy)
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.orderRepository.Create(&entity.Order{
+	location, err := valueobject.NewLocation(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := s.lock(product)
+	if err != nil {
+		return nil, err
+	}
+	defer related.release()
+
+	if product.IsParent() {
+		return nil, ErrParentCannotBeOrdered
+	}
+
+	return s.create(product, related, quantity, strategy, location, coupon, region)
+}
+
+func (s *OrderService) Get(orderID string) (*entity.Order, error) {
+	id, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, ErrInvalidOrderID
+	}
+
+	return s.orderRepository.Get(id)
+}
+
+// ChangeStatus moves the order to the given status, rejecting changes the
+// order lifecycle does not allow. A cancelled order gives the units it took
+// back to the stock and its place in the backorder queue back to the product.
+// Cancelled bundle orders give the units back to the components, and the
+// stock of the bundles and parents made of the returned units is brought up to
+// date. The use of the coupon a cancelled order redeemed is given back, and
+// the units it counted towards a campaign that is still running are taken back
+// off the campaign and the demand of the product.
+func (s *OrderService) ChangeStatus(orderID string, status string) (*entity.Order, error) {
+	existingOrder, err := s.Get(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var product *entity.Product
+	var related *family
+	var counted *entity.Campaign
+	var redeemed *entity.Coupon
+	if status == valueobject.Cancelled {
+		product, err = s.productRepository.GetByID(existingOrder.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		related, err = s.lock(product)
+		if err != nil {
+			return nil, err
+		}
+		defer related.release()
+
+		counted = countedCampaign(existingOrder, product, related.parent)
+
+		if existingOrder.Coupon.Value() != "" {
+			redeemed, err = s.couponRepository.Get(existingOrder.Coupon)
+			if err != nil {
+				return nil, err
+			}
+
+			redeemed.Acquire()
+			defer redeemed.Release()
+		}
+	}
+
+	err = s.unitOfWork.Do(func() error {
+		orderVersion := existingOrder.Version
+		uow.Track(s.unitOfWork, existingOrder)
+
+		err := existingOrder.ChangeStatus(status)
+		if err != nil {
+			return err
+		}
+
+		if product != nil {
+			productVersion := product.Version
+			uow.Track(s.unitOfWork, product)
+
+			err = s.refund(counted, existingOrder)
+			if err != nil {
+				return err
+			}
+
+			err = s.cancel(product, related, existingOrder)
+			if err != nil {
+				return err
+			}
+
+			err = product.DecreaseDemand(existingOrder.Quantity.Value())
+			if err != nil {
+				return err
+			}
+
+			err = s.productRepository.Update(product, productVersion)
+			if err != nil {
+				return err
+			}
+
+			parent := related.parent
+			if parent != nil {
+				parentVersion := parent.Version
+				uow.Track(s.unitOfWork, parent)
+
+				err = parent.DecreaseDemand(existingOrder.Quantity.Value())
+				if err != nil {
+					return err
+				}
+
+				err = s.productRepository.Update(parent, parentVersion)
+				if err != nil {
+					return err
+				}
+			}
+
+			err = s.derive(related.group)
+			if err != nil {
+				return err
+			}
+		}
+
+		if redeemed != nil {
+			couponVersion := redeemed.Version
+			uow.Track(s.unitOfWork, redeemed)
+
+			redeemed.Return()
+			err = s.couponRepository.Update(redeemed, couponVersion)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.orderRepository.Update(existingOrder, orderVersion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return existingOrder, nil
+}
+
+// Restock adds amount units to the stock of product and fulfills its queued
+// backorders from it, oldest first, and brings the stock of the bundles and
+// parents made of it up to date. It returns the orders that were fully
+// fulfilled.
+func (s *OrderService) Restock(product *entity.Product, amount int) ([]*entity.Order, error) {
+	if amount <= 0 {
+		return nil, ErrRestockAmountMustBePositive
+	}
+
+	group := entity.LockStock(s.productRepository.GetAll, product)
+	defer group.Release()
+
+	if !product.HoldsStock() {
+		return nil, ErrCannotBeRestocked
+	}
+
+	var fulfilled []*entity.Order
+	err := s.unitOfWork.Do(func() error {
+		productVersion := product.Version
+		uow.Track(s.unitOfWork, product)
+
+		err := product.IncreaseStock(amount)
+		if err != nil {
+			return err
+		}
+
+		err = s.record(product, valueobject.RestockMovement, amount, product.ID)
+		if err != nil {
+			return err
+		}
+
+		fulfilled, err = s.fulfill(product)
+		if err != nil {
+			return err
+		}
+
+		err = s.productRepository.Update(product, productVersion)
+		if err != nil {
+			return err
+		}
+
+		return s.derive(group)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fulfilled, nil
+}
+
+// FulfillBackorders fulfills the queued orders of every launched product from
+// its stock, which takes the pre-orders of products that have just launched.
+func (s *OrderService) FulfillBackorders() error {
+	now := s.clock.Now()
+	for _, product := range s.productRepository.GetAll() {
+		err := s.fulfillLaunched(product, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *OrderService) fulfillLaunched(product *entity.Product, now time.Time) error {
+	product.Acquire()
+	queued := len(product.BackorderQueue) > 0 && product.OnHand(now) > 0
+	product.Release()
+	if !queued {
+		return nil
+	}
+
+	group := entity.LockStock(s.productRepository.GetAll, product)
+	defer group.Release()
+
+	if len(product.BackorderQueue) == 0 || product.OnHand(now) == 0 {
+		return nil
+	}
+
+	return s.unitOfWork.Do(func() error {
+		productVersion := product.Version
+		uow.Track(s.unitOfWork, product)
+
+		_, err := s.fulfill(product)
+		if err != nil {
+			return err
+		}
+
+		err = s.productRepository.Update(product, productVersion)
+		if err != nil {
+			return err
+		}
+
+		return s.derive(group)
+	})
+}
+
+// create places the order of quantity units of product. An order of a variant
+// counts towards the campaign of the variant and the campaign of its parent,
+// which are locked in that order. The order names the campaign of the variant
+// when both are active. A category campaign both belong to counts the order
+// once. The coupon is locked after the campaigns and the region after the
+// coupon. The volume tier the order reaches is taken off the unit price before
+// the coupon applies, and the campaigns count the units at the price paid once
+// both are taken off. The region taxes the net total. The stock of the bundles
+// and parents made of the units taken is brought up to date.
+func (s *OrderService) create(product *entity.Product, related *family, quantity valueobject.Quantity, strategy valueobject.AllocationStrategy, location valueobject.Location, coupon *entity.Coupon, region *entity.Region) (*entity.Order, error) {
+	productCampaign := product.Campaign
+	if productCampaign != nil {
+		productCampaign.Acquire()
+		defer productCampaign.Release()
+	}
+
+	parent := related.parent
+	var parentCampaign *entity.Campaign
+	if parent != nil && parent.Campaign != nil && parent.Campaign != productCampaign {
+		parentCampaign = parent.Campaign
+		parentCampaign.Acquire()
+		defer parentCampaign.Release()
+	}
+
+	if coupon != nil {
+		coupon.Acquire()
+		defer coupon.Release()
+	}
+
+	if region != nil {
+		region.Acquire()
+		defer region.Release()
+	}
+
+	now := s.clock.Now()
+	err := s.checkCoupon(coupon, product, parent, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var taxRate float64
+	if region != nil {
+		var ok bool
+		taxRate, ok = region.RateFor(product.TaxClassFor(parent))
+		if !ok {
+			return nil, ErrNoTaxRate
+		}
+	}
+
+	newOrder := &entity.Order{
 		ID:        uuid.New(),
 		ProductID: product.ID,
 		Quantity:  quantity,
+		Price:     product.Price,
+		CreatedAt: now,
+	}
+	if productCampaign != nil && productCampaign.IsActive() {
+		newOrder.Campaign = productCampaign.Name
+	} else if parentCampaign != nil && parentCampaign.IsActive() {
+		newOrder.Campaign = parentCampaign.Name
+	}
+
+	err = s.unitOfWork.Do(func() error {
+		productVersion := product.Version
+		uow.Track(s.unitOfWork, product)
+		if productCampaign != nil {
+			uow.Track(s.unitOfWork, productCampaign)
+		}
+
+		var parentVersion int
+		if parent != nil {
+			parentVersion = parent.Version
+			uow.Track(s.unitOfWork, parent)
+			if parentCampaign != nil {
+				uow.Track(s.unitOfWork, parentCampaign)
+			}
+
+			product.FollowParent(parent)
+			newOrder.Price = product.Price
+		}
+
+		if product.IsBundle() {
+			err := product.SyncStock(related.components)
+			if err != nil {
+				return err
+			}
+		}
+
+		if tier, ok := product.TierFor(quantity.Value(), parent); ok {
+			err := newOrder.ApplyTier(tier)
+			if err != nil {
+				return err
+			}
+		}
+
+		fromStock := product.OnHand(now)
+		if quantity.Value() < fromStock {
+			fromStock = quantity.Value()
+		}
+		owed := quantity.Value() - fromStock
+		if owed > product.BackorderCapacity(now) {
+			return ErrInsufficientStock
+		}
+
+		status := valueobject.Placed
+		if !product.IsLaunched(now) {
+			status = valueobject.PreOrdered
+		} else if owed > 0 {
+			status = valueobject.Backordered
+		}
+
+		orderStatus, err := valueobject.NewOrderStatus(status)
+		if err != nil {
+			return err
+		}
+		newOrder.Status = orderStatus
+
+		newOrder.Allocations, err = product.Take(fromStock, strategy, location, s.warehouses())
+		if err != nil {
+			return err
+		}
+
+		err = s.takeComponents(product, related.components, newOrder, fromStock, strategy, location)
+		if err != nil {
+			return err
+		}
+
+		err = s.record(product, valueobject.SaleMovement, -fromStock, newOrder.ID)
+		if err != nil {
+			return err
+		}
+
+		if owed > 0 {
+			err = product.Backorder(newOrder, owed)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.redeem(coupon, newOrder)
+		if err != nil {
+			return err
+		}
+
+		if region != nil {
+			newOrder.ApplyTax(region.Code, taxRate)
+		}
+
+		err = s.orderRepository.Create(newOrder)
+		if err != nil {
+			return err
+		}
+
+		counted := quantity.Value()
+		if productCampaign != nil && productCampaign.CountsOnFulfillment() {
+			counted = fromStock
+		}
+
+		err = s.updateCampaign(product, counted, newOrder.NetPrice(), newOrder.UnitTax())
+		if err != nil {
+			return err
+		}
+
+		err = product.IncreaseDemand(quantity.Value())
+		if err != nil {
+			return err
+		}
+
+		if parent != nil {
+			err = parent.SyncVariants(related.variants)
+			if err != nil {
+				return err
+			}
+
+			if parentCampaign != nil {
+				counted := quantity.Value()
+				if parentCampaign.CountsOnFulfillment() {
+					counted = fromStock
+				}
+
+				err = s.updateCampaign(parent, counted, newOrder.NetPrice(), newOrder.UnitTax())
+				if err != nil {
+					return err
+				}
+			}
+
+			err = parent.IncreaseDemand(quantity.Value())
+			if err != nil {
+				return err
+			}
+
+			err = s.productRepository.Update(parent, parentVersion)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.productRepository.Update(product, productVersion)
+		if err != nil {
+			return err
+		}
+
+		return s.derive(related.group)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newOrder, nil
+}
+
+// fulfill takes the units owed to the queued orders of product from its stock,
+// oldest order first, and returns the orders that are now fully fulfilled.
+// Under a fulfillment backorder policy the units count towards the campaign
+// the order was placed in. The product lock must be held and the product is
+// tracked by the running unit of work.
+func (s *OrderService) fulfill(product *entity.Product) ([]*entity.Order, error) {
+	productCampaign := product.Campaign
+	if productCampaign != nil {
+		productCampaign.Acquire()
+		defer productCampaign.Release()
+		uow.Track(s.unitOfWork, productCampaign)
+	}
+
+	var fulfilled []*entity.Order
+	for len(product.BackorderQueue) > 0 && product.OnHand(s.clock.Now()) > 0 {
+		queued, err := s.orderRepository.Get(product.BackorderQueue[0])
+		if err != nil {
+			return nil, err
+		}
 
+		orderVersion := queued.Version
+		uow.Track(s.unitOfWork, queued)
+
+		taken, err := product.Fulfill(queued)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.record(product, valueobject.ReservationMovement, -taken, queued.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if queued.Backordered.Value() == 0 {
+			err = queued.ChangeStatus(valueobject.Placed)
+			if err != nil {
+				return nil, err
+			}
+
+			fulfilled = append(fulfilled, queued)
+		}
+
+		if productCampaign != nil && productCampaign.CountsOnFulfillment() && queued.Campaign.Equals(productCampaign.Name) {
+			err = s.updateCampaign(product, taken, queued.NetPrice(), queued.UnitTax())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = s.orderRepository.Update(queued, orderVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fulfilled, nil
+}
+
+// cancel returns the units order took from stock to product, frees the units
+// it was still owed and hands the returned units to the queued orders. The
+// products related must be locked and the product is tracked by the running
+// unit of work.
+func (s *OrderService) cancel(product *entity.Product, related *family, order *entity.Order) error {
+	for _, component := range product.Components {
+		item := related.components[component.Product.Value()]
+		version := item.Version
+		uow.Track(s.unitOfWork, item)
+
+		returned, err := item.ReturnComponentStock(order, component)
+		if err != nil {
+			return err
+		}
+
+		err = s.record(item, valueobject.CancellationMovement, returned, order.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.fulfill(item)
+		if err != nil {
+			return err
+		}
+
+		err = s.productRepository.Update(item, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	returned, err := product.ReturnStock(order)
 	if err != nil {
 		return err
 	}
 
-	defer product.DecreaseStock(quantity.Value())
-	defer product.IncreaseDemand(quantity.Value())
+	if order.Backordered.Value() > 0 {
+		err = product.CancelBackorder(order)
+		if err != nil {
+			return err
+		}
+	}
 
-	if product.Campaign == nil || (product.Campaign != nil && !product.Campaign.IsActive()) {
+	err = s.record(product, valueobject.CancellationMovement, returned, order.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.fulfill(product)
+	return err
+}
+
+// countedCampaign returns the campaign order counted towards, the campaign of
+// product or of its parent named by the order, and nil when neither is. The
+// products must be locked.
+func countedCampaign(order *entity.Order, product *entity.Product, parent *entity.Product) *entity.Campaign {
+	if order.Campaign.Value() == "" {
 		return nil
 	}
 
-	remainingTargetSaleCount := product.Campaign.RemainingTargetSalesCount(quantity.Value())
+	candidates := []*entity.Product{product}
+	if parent != nil {
+		candidates = append(candidates, parent)
+	}
+
+	for _, item := range candidates {
+		if item.Campaign == nil {
+			continue
+		}
+
+		if item.Campaign.Name.Equals(order.Campaign) {
+			return item.Campaign
+		}
+	}
+
+	return nil
+}
+
+// refund takes the units of order that counted towards campaign back off it,
+// with the amount paid and the tax on them, while the campaign is running.
+// Under a fulfillment backorder policy only the units already taken from stock
+// counted. Having no campaign refunds nothing.
+func (s *OrderService) refund(campaign *entity.Campaign, order *entity.Order) error {
+	if campaign == nil {
+		return nil
+	}
+
+	campaign.Acquire()
+	defer campaign.Release()
+
+	if campaign.IsEnded() {
+		return nil
+	}
+
+	quantity := order.Quantity.Value()
+	if campaign.CountsOnFulfillment() {
+		quantity -= order.Backordered.Value()
+	}
+
+	if quantity == 0 {
+		return nil
+	}
+
+	campaignVersion := campaign.Version
+	uow.Track(s.unitOfWork, campaign)
+
+	err := campaign.Refund(quantity, order.NetPrice(), order.UnitTax())
+	if err != nil {
+		return err
+	}
+
+	return s.campaignRepository.Update(campaign, campaignVersion)
+}
+
+// takeComponents takes the units of every component the bundle product needs
+// for count bundles of order. Other products have no components to take.
+// The components must be locked.
+func (s *OrderService) takeComponents(product *entity.Product, components map[string]*entity.Product, order *entity.Order, count int, strategy valueobject.AllocationStrategy, location valueobject.Location) error {
+	for _, component := range product.Components {
+		item := components[component.Product.Value()]
+		version := item.Version
+		uow.Track(s.unitOfWork, item)
+
+		allocations, err := item.Take(count*component.Quantity, strategy, location, s.warehouses())
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			allocation.Product = item.Code
+			order.Allocations = append(order.Allocations, allocation)
+		}
+
+		err = s.record(item, valueobject.SaleMovement, -count*component.Quantity, order.ID)
+		if err != nil {
+			return err
+		}
+
+		err = s.productRepository.Update(item, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// family holds the products locked along with the product of an order: the
+// components of a bundle, or the parent of a variant and all its variants, the
+// ordered one included. The group holds them with every bundle and parent
+// whose stock the order changes.
+type family struct {
+	group      *entity.StockGroup
+	components map[string]*entity.Product
+	parent     *entity.Product
+	variants   map[string]*entity.Product
+	release    func()
+}
+
+// lock looks up and locks ordered with the products an order of it touches.
+func (s *OrderService) lock(ordered *entity.Product) (*family, error) {
+	group := entity.LockStock(s.productRepository.GetAll, ordered)
+	related := &family{group: group, components: group.Components(ordered), release: group.Release}
+	if len(related.components) != len(ordered.Components) {
+		group.Release()
+		return nil, product.ErrNotFound
+	}
+
+	if ordered.IsVariant() {
+		related.parent = group.Get(ordered.Parent)
+		if related.parent == nil {
+			group.Release()
+			return nil, product.ErrNotFound
+		}
+
+		related.variants = group.Variants(related.parent)
+	}
+
+	return related, nil
+}
+
+// derive brings every bundle and parent of group up to date with the stock of
+// the products it is made of, and moves the variants following the price of a
+// parent to it, which an ended parent campaign may have reset. It runs in the
+// unit of work that changed the stock.
+func (s *OrderService) derive(group *entity.StockGroup) error {
+	for _, item := range group.Derived() {
+		if !group.InSync(item) {
+			version := item.Version
+			uow.Track(s.unitOfWork, item)
+
+			err := group.Sync(item)
+			if err != nil {
+				return err
+			}
+
+			err = s.productRepository.Update(item, version)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, variant := range group.Variants(item) {
+			version := variant.Version
+			uow.Track(s.unitOfWork, variant)
+
+			if variant.FollowParent(item) {
+				err := s.productRepository.Update(variant, version)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// record appends a movement of quantity units of product for reference to the
+// ledger. Nothing is recorded when no units moved, nor for products holding no
+// stock of their own.
+func (s *OrderService) record(product *entity.Product, movementType string, quantity int, reference uuid.UUID) error {
+	if quantity == 0 || !product.HoldsStock() {
+		return nil
+	}
+
+	kind, err := valueobject.NewMovementType(movementType)
+	if err != nil {
+		return err
+	}
+
+	return s.ledgerRepository.Append(&entity.StockMovement{
+		ID:        uuid.New(),
+		ProductID: product.ID,
+		Type:      kind,
+		Quantity:  quantity,
+		Reference: reference,
+		Time:      s.clock.Now(),
+	})
+}
+
+// updateCampaign counts quantity units sold at price, taxed at tax each,
+// towards the active campaign of product, which must already hold the stock
+// left after the sale.
+func (s *OrderService) updateCampaign(product *entity.Product, quantity int, price float64, tax float64) error {
+	productCampaign := product.Campaign
+	if productCampaign == nil || !productCampaign.IsActive() || quantity == 0 {
+		return nil
+	}
+
+	campaignVersion := productCampaign.Version
+	remainingTargetSaleCount := productCampaign.RemainingTargetSalesCount(quantity)
 	var avaibleStockForCampaign int
 	if remainingTargetSaleCount <= 0 {
-		avaibleStockForCampaign = product.Campaign.TargetSalesCount.Value() - product.Campaign.TotalSales.Value()
-		product.Campaign.Close()
+		avaibleStockForCampaign = productCampaign.TargetSalesCount.Value() - productCampaign.TotalSales.Value()
+		err := productCampaign.End(valueobject.TargetReached, s.clock.Now())
+		if err != nil {
+			return err
+		}
 	} else {
-		avaibleStockForCampaign = quantity.Value()
+		avaibleStockForCampaign = quantity
 	}
 
-	err = product.Campaign.IncreaseTotalSales(avaibleStockForCampaign)
+	err := productCampaign.IncreaseTotalSales(avaibleStockForCampaign)
 	if err != nil {
 		return err
 	}
-	err = product.Campaign.UpdateAverageItemPrice(product.Price.Value(), avaibleStockForCampaign)
+	err = productCampaign.UpdateAverageItemPrice(price, avaibleStockForCampaign)
+	if err != nil {
+		return err
+	}
+	productCampaign.AddTax(tax, avaibleStockForCampaign)
+
+	_, err = productCampaign.ApplyStockPolicy(s.clock.Now())
 	if err != nil {
 		return err
 	}
 
+	return s.campaignRepository.Update(productCampaign, campaignVersion)
+}
+
+// checkCoupon returns an error when coupon cannot be redeemed at now on an
+// order of product, a variant of parent when parent is not nil. The coupon
+// applies to a variant through the code and the category of its parent too.
+// Having no coupon is not an error.
+func (s *OrderService) checkCoupon(coupon *entity.Coupon, product *entity.Product, parent *entity.Product, now time.Time) error {
+	if coupon == nil {
+		return nil
+	}
+
+	if coupon.IsExpired(now) {
+		return ErrCouponExpired
+	}
+
+	if coupon.IsUsedUp() {
+		return ErrCouponUsedUp
+	}
+
+	products := []valueobject.Code{product.Code}
+	categories := s.categories(product.Category)
+	if parent != nil {
+		products = append(products, parent.Code)
+		categories = append(categories, s.categories(parent.Category)...)
+	}
+
+	if !coupon.AppliesTo(products, categories) {
+		return ErrCouponNotApplicable
+	}
+
 	return nil
 }
+
+// redeem takes the discount of coupon off order and counts a use of the
+// coupon. The coupon lock must be held. Having no coupon redeems nothing.
+func (s *OrderService) redeem(coupon *entity.Coupon, order *entity.Order) error {
+	if coupon == nil {
+		return nil
+	}
+
+	discount, err := valueobject.NewPrice(coupon.Discount.Apply(order.Subtotal()))
+	if err != nil {
+		return err
+	}
+
+	couponVersion := coupon.Version
+	uow.Track(s.unitOfWork, coupon)
+
+	order.Coupon = coupon.Code
+	order.Discount = discount
+	coupon.Redeem()
+	return s.couponRepository.Update(coupon, couponVersion)
+}
+
+// categories returns code and the codes of every category above it, or none
+// when code is empty.
+func (s *OrderService) categories(code valueobject.Code) []valueobject.Code {
+	var result []valueobject.Code
+	visited := make(map[string]bool)
+	for code.Value() != "" && !visited[code.Value()] {
+		visited[code.Value()] = true
+		stored, err := s.categoryRepository.Get(code)
+		if err != nil {
+			break
+		}
+
+		result = append(result, stored.Code)
+		code = stored.Parent
+	}
+
+	return result
+}
+
+// warehouses returns every warehouse by code.
+func (s *OrderService) warehouses() map[string]*entity.Warehouse {
+	result := make(map[string]*entity.Warehouse)
+	for _, item := range s.warehouseRepository.GetAll() {
+		result[item.Code.Value()] = item
+	}
+
+	return result
+}
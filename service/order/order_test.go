@@ -1,38 +1,119 @@
 package order
 
 import (
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	categoryRepo "github.com/aaydin-tr/e-commerce/domain/category/memory"
+	couponRepo "github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	ledgerRepo "github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	"github.com/aaydin-tr/e-commerce/domain/order"
+	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
+	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	warehouseRepo "github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
 	"github.com/aaydin-tr/e-commerce/entity"
+	mockCampaign "github.com/aaydin-tr/e-commerce/mock/repository/campaign"
+	mockCategory "github.com/aaydin-tr/e-commerce/mock/repository/category"
+	mockCoupon "github.com/aaydin-tr/e-commerce/mock/repository/coupon"
+	mockLedger "github.com/aaydin-tr/e-commerce/mock/repository/ledger"
 	mockOrder "github.com/aaydin-tr/e-commerce/mock/repository/order"
+	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
+	mockWarehouse "github.com/aaydin-tr/e-commerce/mock/repository/warehouse"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
 	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
-var mockOrderRepo *mockOrder.MockOrderRepository
+// orderTime is the clock value orders are created at in tests.
+var orderTime = time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+
+var (
+	mockOrderRepo    *mockOrder.MockOrderRepository
+	mockProductRepo  *mockProduct.MockProductRepository
+	mockCampaignRepo *mockCampaign.MockCampaignRepository
+)
 
 func setup(t *testing.T) (*OrderService, func()) {
 	ct := gomock.NewController(t)
 
 	mockOrderRepo = mockOrder.NewMockOrderRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+	mockCampaignRepo = mockCampaign.NewMockCampaignRepository(ct)
 
-	orderService := NewOrderService(mockOrderRepo)
+	// Orders lock the products whose stock they change with every bundle and
+	// parent made of them. The products of these tests stand alone.
+	mockProductRepo.EXPECT().GetAll().Return(nil).AnyTimes()
+
+	// Orders read the warehouses for every allocation and record every stock
+	// movement, so memory repositories stand in for them.
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, mockCampaignRepo, warehouseRepo.NewWarehouseRepository(storage.New[*entity.Warehouse]()), ledgerRepo.NewLedgerRepository(storage.New[*entity.StockMovement]()), couponRepo.NewCouponRepository(storage.New[*entity.Coupon]()), categoryRepo.NewCategoryRepository(storage.New[*entity.Category]()), uow.New(), clock.New(orderTime))
 
 	return orderService, func() {
 		ct.Finish()
 		mockOrderRepo = nil
+		mockProductRepo = nil
+		mockCampaignRepo = nil
 	}
 }
 
+type memoryRepositories struct {
+	orderStorage        types.Storage[*entity.Order]
+	productRepository   *productRepo.ProductRepository
+	campaignRepository  *campaignRepo.CampaignRepository
+	warehouseRepository *warehouseRepo.WarehouseRepository
+	ledgerRepository    *ledgerRepo.LedgerRepository
+	couponRepository    *couponRepo.CouponRepository
+	categoryRepository  *categoryRepo.CategoryRepository
+}
+
+func setupMemory() (*OrderService, memoryRepositories) {
+	unitOfWork := uow.New()
+	repositories := memoryRepositories{
+		orderStorage:        uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]()),
+		productRepository:   productRepo.NewProductRepository(uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]())),
+		campaignRepository:  campaignRepo.NewCampaignRepository(uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]())),
+		warehouseRepository: warehouseRepo.NewWarehouseRepository(uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]())),
+		ledgerRepository:    ledgerRepo.NewLedgerRepository(uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]())),
+		couponRepository:    couponRepo.NewCouponRepository(uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]())),
+		categoryRepository:  categoryRepo.NewCategoryRepository(uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]())),
+	}
+
+	orderService := NewOrderService(orderRepo.NewOrderRepository(repositories.orderStorage), repositories.productRepository, repositories.campaignRepository, repositories.warehouseRepository, repositories.ledgerRepository, repositories.couponRepository, repositories.categoryRepository, unitOfWork, clock.New(orderTime))
+	return orderService, repositories
+}
+
 func TestNewOrderService(t *testing.T) {
 	ct := gomock.NewController(t)
 
 	mockOrderRepo = mockOrder.NewMockOrderRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+	mockCampaignRepo = mockCampaign.NewMockCampaignRepository(ct)
+	mockWarehouseRepo := mockWarehouse.NewMockWarehouseRepository(ct)
+	mockLedgerRepo := mockLedger.NewMockLedgerRepository(ct)
+	mockCouponRepo := mockCoupon.NewMockCouponRepository(ct)
+	mockCategoryRepo := mockCategory.NewMockCategoryRepository(ct)
 
-	orderService := NewOrderService(mockOrderRepo)
+	unitOfWork := uow.New()
+	orderClock := clock.New(orderTime)
+	orderService := NewOrderService(mockOrderRepo, mockProductRepo, mockCampaignRepo, mockWarehouseRepo, mockLedgerRepo, mockCouponRepo, mockCategoryRepo, unitOfWork, orderClock)
 
 	assert.Equal(t, orderService.orderRepository, mockOrderRepo)
+	assert.Equal(t, orderService.productRepository, mockProductRepo)
+	assert.Equal(t, orderService.campaignRepository, mockCampaignRepo)
+	assert.Equal(t, orderService.warehouseRepository, mockWarehouseRepo)
+	assert.Equal(t, orderService.ledgerRepository, mockLedgerRepo)
+	assert.Equal(t, orderService.couponRepository, mockCouponRepo)
+	assert.Equal(t, orderService.categoryRepository, mockCategoryRepo)
+	assert.Equal(t, orderService.unitOfWork, unitOfWork)
+	assert.Equal(t, orderService.clock, orderClock)
 
 	ct.Finish()
 }
@@ -47,20 +128,26 @@ func TestOrderService_Create(t *testing.T) {
 	mockProduct := &entity.Product{Code: code, Stock: stock, Price: price}
 
 	t.Run("should return error when order quantity is invalid", func(t *testing.T) {
-		err := orderService.Create(mockProduct, 0)
+		_, err := orderService.Create(mockProduct, 0)
 		assert.Error(t, err)
 	})
 
 	t.Run("should return error when product stock is insufficient", func(t *testing.T) {
-		err := orderService.Create(mockProduct, 20)
+		_, err := orderService.Create(mockProduct, 20)
 		assert.ErrorIs(t, err, ErrInsufficientStock)
 	})
 
 	t.Run("success without campaign", func(t *testing.T) {
 		mockOrderRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(mockProduct, 0).Return(nil)
 
-		err := orderService.Create(mockProduct, 1)
+		newOrder, err := orderService.Create(mockProduct, 1)
 		assert.NoError(t, err)
+		assert.Equal(t, 1, newOrder.Quantity.Value())
+		assert.Equal(t, price, newOrder.Price)
+		assert.Equal(t, orderTime, newOrder.CreatedAt)
+		assert.Equal(t, valueobject.Placed, newOrder.Status.Value())
+		assert.Equal(t, "", newOrder.Campaign.Value())
 	})
 
 	t.Run("success with campaign", func(t *testing.T) {
@@ -76,9 +163,12 @@ func TestOrderService_Create(t *testing.T) {
 		product.Campaign = campaign
 
 		mockOrderRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockCampaignRepo.EXPECT().Update(campaign, 0).Return(nil)
+		mockProductRepo.EXPECT().Update(product, 0).Return(nil)
 
-		err := orderService.Create(product, 1)
+		newOrder, err := orderService.Create(product, 1)
 		assert.NoError(t, err)
+		assert.Equal(t, campaignName, newOrder.Campaign)
 
 		assert.Equal(t, 1, campaign.TotalSales.Value())
 		assert.Equal(t, float64(10), campaign.AverageItemPrice.Value())
@@ -100,8 +190,10 @@ func TestOrderService_Create(t *testing.T) {
 		product.Campaign = campaign
 
 		mockOrderRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockCampaignRepo.EXPECT().Update(campaign, 0).Return(nil)
+		mockProductRepo.EXPECT().Update(product, 0).Return(nil)
 
-		err := orderService.Create(product, 15)
+		_, err := orderService.Create(product, 15)
 		assert.NoError(t, err)
 
 		assert.Equal(t, 10, campaign.TotalSales.Value())
@@ -109,5 +201,1242 @@ func TestOrderService_Create(t *testing.T) {
 		assert.Equal(t, 0, product.Stock.Value())
 		assert.Equal(t, 15, product.TotalDemandCount.Value())
 		assert.Equal(t, valueobject.Ended, campaign.Status.Value())
+		assert.Equal(t, valueobject.TargetReached, campaign.EndReason.Value())
+		assert.Equal(t, orderTime, campaign.EndedAt)
+		assert.Equal(t, price, campaign.FinalPrice)
+	})
+
+	t.Run("should apply stock policy when stock cannot cover the remaining target", func(t *testing.T) {
+		for _, test := range []struct {
+			policy       string
+			status       string
+			linked       bool
+			productPrice float64
+		}{
+			{policy: valueobject.EndOnStockOut, status: valueobject.Ended, linked: false, productPrice: 100},
+			{policy: valueobject.PauseOnStockOut, status: valueobject.Paused, linked: true, productPrice: 90},
+			{policy: valueobject.KeepOnStockOut, status: valueobject.Active, linked: true, productPrice: 90},
+		} {
+			code, _ := valueobject.NewCode("P1")
+			stock, _ := valueobject.NewStock(5)
+			campaignName, _ := valueobject.NewName("C1")
+			targetSalesCount, _ := valueobject.NewTargetSalesCount(10)
+			status, _ := valueobject.NewStatus(valueobject.Active)
+			stockPolicy, _ := valueobject.NewStockPolicy(test.policy)
+			initialPrice, _ := valueobject.NewPrice(100)
+			price, _ := valueobject.NewPrice(90)
+
+			product := &entity.Product{Stock: stock, Price: price, InititalPrice: initialPrice, Code: code}
+			campaign := &entity.Campaign{Name: campaignName, Product: product, TargetSalesCount: targetSalesCount, Status: status, StockPolicy: stockPolicy}
+			product.Campaign = campaign
+
+			mockOrderRepo.EXPECT().Create(gomock.Any()).Return(nil)
+			mockCampaignRepo.EXPECT().Update(campaign, 0).Return(nil)
+			mockProductRepo.EXPECT().Update(product, 0).Return(nil)
+
+			_, err := orderService.Create(product, 1)
+			assert.NoError(t, err, test.policy)
+			assert.Equal(t, 1, campaign.TotalSales.Value(), test.policy)
+			assert.Equal(t, test.status, campaign.Status.Value(), test.policy)
+			assert.Equal(t, test.linked, product.Campaign == campaign, test.policy)
+			assert.Equal(t, test.productPrice, product.Price.Value(), test.policy)
+		}
+	})
+
+	t.Run("should return error when order repo create returns error", func(t *testing.T) {
+		returnErr := errors.New("error")
+		mockOrderRepo.EXPECT().Create(gomock.Any()).Return(returnErr)
+
+		_, err := orderService.Create(mockProduct, 1)
+		assert.ErrorIs(t, err, returnErr)
+		assert.Equal(t, 9, mockProduct.Stock.Value())
+	})
+
+	t.Run("should return conflict when product version conflicts", func(t *testing.T) {
+		conflictErr := &types.VersionConflictError{Aggregate: "product", Key: "P1", Expected: 0, Actual: 1}
+		mockOrderRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(mockProduct, 0).Return(conflictErr)
+
+		_, err := orderService.Create(mockProduct, 1)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		var versionConflictErr *types.VersionConflictError
+		assert.ErrorAs(t, err, &versionConflictErr)
+		assert.Equal(t, 9, mockProduct.Stock.Value())
+	})
+}
+
+func TestOrderService_CreateRollback(t *testing.T) {
+	code, _ := valueobject.NewCode("P1")
+	stock, _ := valueobject.NewStock(10)
+	price, _ := valueobject.NewPrice(10)
+	campaignName, _ := valueobject.NewName("C1")
+	status, _ := valueobject.NewStatus(valueobject.Active)
+
+	orderService, repositories := setupMemory()
+
+	// A campaign without a target sales count fails while its total sales are
+	// being updated, after the order has already been stored.
+	product := &entity.Product{Stock: stock, Price: price, Code: code}
+	campaign := &entity.Campaign{Name: campaignName, Product: product, Status: status}
+	product.Campaign = campaign
+	repositories.productRepository.Create(product)
+	repositories.campaignRepository.Create(campaign)
+
+	_, err := orderService.Create(product, 1)
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, repositories.orderStorage.Len())
+	assert.Equal(t, 0, product.Version)
+	assert.Equal(t, 10, product.Stock.Value())
+	assert.Equal(t, 0, product.TotalDemandCount.Value())
+	assert.Equal(t, valueobject.Active, campaign.Status.Value())
+	assert.Equal(t, 0, campaign.TotalSales.Value())
+	assert.Same(t, campaign, product.Campaign)
+}
+
+func TestOrderService_CreateConcurrent(t *testing.T) {
+	code, _ := valueobject.NewCode("P1")
+	stock, _ := valueobject.NewStock(100)
+	price, _ := valueobject.NewPrice(10)
+	campaignName, _ := valueobject.NewName("C1")
+	targetSalesCount, _ := valueobject.NewTargetSalesCount(60)
+	status, _ := valueobject.NewStatus(valueobject.Active)
+
+	orderService, repositories := setupMemory()
+
+	product := &entity.Product{Stock: stock, Price: price, Code: code}
+	campaign := &entity.Campaign{Name: campaignName, Product: product, TargetSalesCount: targetSalesCount, Status: status}
+	product.Campaign = campaign
+	repositories.productRepository.Create(product)
+	repositories.campaignRepository.Create(campaign)
+
+	var (
+		wg                sync.WaitGroup
+		mu                sync.Mutex
+		succeeded         int
+		insufficientStock int
+	)
+	for i := 0; i < 150; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := orderService.Create(product, 1)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				succeeded++
+			} else if errors.Is(err, ErrInsufficientStock) {
+				insufficientStock++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, succeeded)
+	assert.Equal(t, 50, insufficientStock)
+	assert.Equal(t, 100, repositories.orderStorage.Len())
+	assert.Equal(t, 0, product.Stock.Value())
+	assert.Equal(t, 100, product.Version)
+	assert.Equal(t, 60, campaign.Version)
+	assert.Equal(t, 100, product.TotalDemandCount.Value())
+	assert.Equal(t, 60, campaign.TotalSales.Value())
+	assert.Equal(t, valueobject.Ended, campaign.Status.Value())
+}
+
+func TestOrderService_ConcurrentWriters(t *testing.T) {
+	code, _ := valueobject.NewCode("P1")
+	stock, _ := valueobject.NewStock(50)
+	price, _ := valueobject.NewPrice(10)
+
+	orderService, repositories := setupMemory()
+
+	product := &entity.Product{Stock: stock, Price: price, Code: code}
+	repositories.productRepository.Create(product)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures []error
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := orderService.Create(product, 1)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := orderService.Restock(product, 1)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, failures)
+	assert.Equal(t, 50, product.Stock.Value())
+	assert.Equal(t, 100, product.Version)
+
+	t.Run("should reject a writer holding a stale version", func(t *testing.T) {
+		stale := product.Version
+		_, err := orderService.Create(product, 1)
+		assert.NoError(t, err)
+
+		err = repositories.productRepository.Update(product, stale)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, stale+1, product.Version)
+	})
+}
+
+func TestOrderService_Get(t *testing.T) {
+	orderService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when order ID is invalid", func(t *testing.T) {
+		_, err := orderService.Get("invalid")
+		assert.ErrorIs(t, err, ErrInvalidOrderID)
+	})
+
+	t.Run("should return error when order not found", func(t *testing.T) {
+		mockOrderRepo.EXPECT().Get(gomock.Any()).Return(nil, order.ErrOrderNotFound)
+
+		_, err := orderService.Get(uuid.New().String())
+		assert.ErrorIs(t, err, order.ErrOrderNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		stored := &entity.Order{ID: uuid.New()}
+		mockOrderRepo.EXPECT().Get(stored.ID).Return(stored, nil)
+
+		result, err := orderService.Get(stored.ID.String())
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func TestOrderService_ChangeStatus(t *testing.T) {
+	orderService, teardown := setup(t)
+	defer teardown()
+
+	newOrder := func() *entity.Order {
+		placed, _ := valueobject.NewOrderStatus(valueobject.Placed)
+		return &entity.Order{ID: uuid.New(), Status: placed}
+	}
+
+	t.Run("should return error when order ID is invalid", func(t *testing.T) {
+		_, err := orderService.ChangeStatus("invalid", valueobject.Paid)
+		assert.ErrorIs(t, err, ErrInvalidOrderID)
+	})
+
+	t.Run("should return error when status is unknown", func(t *testing.T) {
+		stored := newOrder()
+		mockOrderRepo.EXPECT().Get(stored.ID).Return(stored, nil)
+
+		_, err := orderService.ChangeStatus(stored.ID.String(), "Lost")
+		assert.ErrorIs(t, err, valueobject.ErrOrderStatusMustBeOneOf)
+		assert.Equal(t, valueobject.Placed, stored.Status.Value())
+	})
+
+	t.Run("should return error when transition is illegal", func(t *testing.T) {
+		stored := newOrder()
+		mockOrderRepo.EXPECT().Get(stored.ID).Return(stored, nil)
+
+		_, err := orderService.ChangeStatus(stored.ID.String(), valueobject.Shipped)
+		assert.ErrorIs(t, err, valueobject.ErrIllegalOrderStatusTransition)
+		assert.Equal(t, valueobject.Placed, stored.Status.Value())
+	})
+
+	t.Run("should restore status when update fails", func(t *testing.T) {
+		stored := newOrder()
+		conflictErr := &types.VersionConflictError{Aggregate: "order", Key: stored.ID.String(), Expected: 0, Actual: 1}
+		mockOrderRepo.EXPECT().Get(stored.ID).Return(stored, nil)
+		mockOrderRepo.EXPECT().Update(stored, 0).Return(conflictErr)
+
+		_, err := orderService.ChangeStatus(stored.ID.String(), valueobject.Paid)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, valueobject.Placed, stored.Status.Value())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		stored := newOrder()
+		mockOrderRepo.EXPECT().Get(stored.ID).Return(stored, nil)
+		mockOrderRepo.EXPECT().Update(stored, 0).Return(nil)
+
+		result, err := orderService.ChangeStatus(stored.ID.String(), valueobject.Paid)
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+		assert.Equal(t, valueobject.Paid, result.Status.Value())
+	})
+}
+
+func TestOrderService_Backorders(t *testing.T) {
+	code, _ := valueobject.NewCode("P1")
+	price, _ := valueobject.NewPrice(10)
+
+	newProduct := func(repositories memoryRepositories, stockCount int, backorderLimit int) *entity.Product {
+		stock, _ := valueobject.NewStock(stockCount)
+		limit, _ := valueobject.NewStock(backorderLimit)
+		product := &entity.Product{ID: uuid.New(), Code: code, Stock: stock, InititalStock: stock, Price: price, InititalPrice: price, BackorderLimit: limit}
+		repositories.productRepository.Create(product)
+		return product
+	}
+
+	t.Run("should backorder the units beyond stock up to the limit", func(t *testing.T) {
+		orderService, repositories := setupMemory()
+		product := newProduct(repositories, 5, 10)
+
+		result, err := orderService.Create(product, 8)
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.Backordered, result.Status.Value())
+		assert.Equal(t, 3, result.Backordered.Value())
+		assert.Equal(t, 0, product.Stock.Value())
+		assert.Equal(t, 3, product.Backordered.Value())
+		assert.Equal(t, []uuid.UUID{result.ID}, product.BackorderQueue)
+
+		_, err = orderService.Create(product, 8)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Equal(t, 3, product.Backordered.Value())
+		assert.Len(t, product.BackorderQueue, 1)
+	})
+
+	t.Run("should fulfill backorders in order when restocked", func(t *testing.T) {
+		orderService, repositories := setupMemory()
+		product := newProduct(repositories, 0, 10)
+
+		first, _ := orderService.Create(product, 4)
+		second, _ := orderService.Create(product, 4)
+
+		fulfilled, err := orderService.Restock(product, 6)
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.Order{first}, fulfilled)
+		assert.Equal(t, valueobject.Placed, first.Status.Value())
+		assert.Equal(t, 0, first.Backordered.Value())
+		assert.Equal(t, valueobject.Backordered, second.Status.Value())
+		assert.Equal(t, 2, second.Backordered.Value())
+		assert.Equal(t, 0, product.Stock.Value())
+		assert.Equal(t, 2, product.Backordered.Value())
+		assert.Equal(t, []uuid.UUID{second.ID}, product.BackorderQueue)
+
+		fulfilled, err = orderService.Restock(product, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []*entity.Order{second}, fulfilled)
+		assert.Equal(t, 3, product.Stock.Value())
+		assert.Empty(t, product.BackorderQueue)
+	})
+
+	t.Run("should return error when restock amount is not positive", func(t *testing.T) {
+		orderService, repositories := setupMemory()
+		product := newProduct(repositories, 0, 10)
+
+		_, err := orderService.Restock(product, 0)
+		assert.ErrorIs(t, err, ErrRestockAmountMustBePositive)
+	})
+
+	t.Run("should free the backorder when the order is cancelled", func(t *testing.T) {
+		orderService, repositories := setupMemory()
+		product := newProduct(repositories, 0, 10)
+
+		first, _ := orderService.Create(product, 4)
+		second, _ := orderService.Create(product, 4)
+
+		_, err := orderService.ChangeStatus(first.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, product.Backordered.Value())
+		assert.Equal(t, []uuid.UUID{second.ID}, product.BackorderQueue)
+
+		fulfilled, _ := orderService.Restock(product, 4)
+		assert.Equal(t, []*entity.Order{second}, fulfilled)
+		assert.Equal(t, valueobject.Cancelled, first.Status.Value())
+	})
+
+	t.Run("should take pre-orders before launch and fulfill them at launch", func(t *testing.T) {
+		orderService, repositories := setupMemory()
+		product := newProduct(repositories, 5, 2)
+		product.LaunchAt = orderTime.Add(time.Hour)
+
+		result, err := orderService.Create(product, 6)
+		assert.NoError(t, err)
+		assert.Equal(t, valueobject.PreOrdered, result.Status.Value())
+		assert.Equal(t, 6, result.Backordered.Value())
+		assert.Equal(t, 5, product.Stock.Value())
+
+		_, err = orderService.Create(product, 2)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+
+		assert.NoError(t, orderService.FulfillBackorders())
+		assert.Equal(t, valueobject.PreOrdered, result.Status.Value())
+
+		product.LaunchAt = orderTime
+		assert.NoError(t, orderService.FulfillBackorders())
+		assert.Equal(t, valueobject.PreOrdered, result.Status.Value())
+		assert.Equal(t, 1, result.Backordered.Value())
+		assert.Equal(t, 0, product.Stock.Value())
+
+		orderService.Restock(product, 1)
+		assert.Equal(t, valueobject.Placed, result.Status.Value())
+	})
+
+	for _, testCase := range []struct {
+		policy              string
+		salesAfterOrder     int
+		salesAfterRestock   int
+		averageAfterRestock float64
+	}{
+		{policy: valueobject.CountOnOrder, salesAfterOrder: 8, salesAfterRestock: 8, averageAfterRestock: 10},
+		{policy: valueobject.CountOnFulfillment, salesAfterOrder: 5, salesAfterRestock: 8, averageAfterRestock: 10},
+	} {
+		t.Run("should count backorders towards the campaign on "+testCase.policy, func(t *testing.T) {
+			orderService, repositories := setupMemory()
+			product := newProduct(repositories, 5, 10)
+
+			name, _ := valueobject.NewName("C1")
+			target, _ := valueobject.NewTargetSalesCount(20)
+			status, _ := valueobject.NewStatus(valueobject.Active)
+			stockPolicy, _ := valueobject.NewStockPolicy(valueobject.KeepOnStockOut)
+			backorderPolicy, _ := valueobject.NewBackorderPolicy(testCase.policy)
+			campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status, StockPolicy: stockPolicy, BackorderPolicy: backorderPolicy}
+			product.Campaign = campaign
+			repositories.campaignRepository.Create(campaign)
+
+			_, err := orderService.Create(product, 8)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.salesAfterOrder, campaign.TotalSales.Value())
+
+			_, err = orderService.Restock(product, 3)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.salesAfterRestock, campaign.TotalSales.Value())
+			assert.Equal(t, testCase.averageAfterRestock, campaign.AverageItemPrice.Value())
+		})
+
+		t.Run("should take a cancelled order back off the campaign on "+testCase.policy, func(t *testing.T) {
+			orderService, repositories := setupMemory()
+			product := newProduct(repositories, 5, 10)
+
+			name, _ := valueobject.NewName("C1")
+			target, _ := valueobject.NewTargetSalesCount(20)
+			status, _ := valueobject.NewStatus(valueobject.Active)
+			stockPolicy, _ := valueobject.NewStockPolicy(valueobject.KeepOnStockOut)
+			backorderPolicy, _ := valueobject.NewBackorderPolicy(testCase.policy)
+			campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status, StockPolicy: stockPolicy, BackorderPolicy: backorderPolicy}
+			product.Campaign = campaign
+			repositories.campaignRepository.Create(campaign)
+
+			kept, _ := orderService.Create(product, 2)
+			cancelled, _ := orderService.Create(product, 8)
+			assert.Equal(t, valueobject.Backordered, cancelled.Status.Value())
+
+			_, err := orderService.ChangeStatus(cancelled.ID.String(), valueobject.Cancelled)
+			assert.NoError(t, err)
+			assert.Equal(t, kept.Quantity.Value(), campaign.TotalSales.Value())
+			assert.Equal(t, 20.0, campaign.Turnover())
+			assert.Equal(t, 2, product.TotalDemandCount.Value())
+		})
+	}
+}
+
+func TestOrderService_CreateWithStrategy(t *testing.T) {
+	code, _ := valueobject.NewCode("P1")
+	price, _ := valueobject.NewPrice(10)
+
+	// setupWarehouses stores W1 at the origin holding 10 units, W2 at 10, 0
+	// holding 30 units and leaves 5 units of the product unassigned.
+	setupWarehouses := func() (*OrderService, *entity.Product) {
+		orderService, repositories := setupMemory()
+		for _, warehouse := range []struct {
+			code string
+			x    float64
+		}{{"W1", 0}, {"W2", 10}} {
+			warehouseCode, _ := valueobject.NewCode(warehouse.code)
+			location, _ := valueobject.NewLocation(warehouse.x, 0)
+			repositories.warehouseRepository.Create(&entity.Warehouse{ID: uuid.New(), Code: warehouseCode, Location: location})
+		}
+
+		stock, _ := valueobject.NewStock(45)
+		product := &entity.Product{ID: uuid.New(), Code: code, Stock: stock, InititalStock: stock, Price: price, InititalPrice: price}
+		w1, _ := valueobject.NewCode("W1")
+		w2, _ := valueobject.NewCode("W2")
+		product.AssignStock(w1, 10)
+		product.AssignStock(w2, 30)
+		repositories.productRepository.Create(product)
+		return orderService, product
+	}
+
+	allocations := func(pairs ...any) []entity.Allocation {
+		var result []entity.Allocation
+		for i := 0; i < len(pairs); i += 2 {
+			warehouse, _ := valueobject.NewCode(pairs[i].(string))
+			result = append(result, entity.Allocation{Warehouse: warehouse, Quantity: pairs[i+1].(int)})
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name     string
+		strategy string
+		x        float64
+		quantity int
+		expected []entity.Allocation
+	}{
+		{name: "nearest takes from the nearest warehouse holding enough", strategy: valueobject.NearestWarehouse, x: 1, quantity: 20, expected: allocations("W2", 20)},
+		{name: "nearest prefers the nearest warehouse", strategy: valueobject.NearestWarehouse, x: 1, quantity: 5, expected: allocations("W1", 5)},
+		{name: "most stock takes from the warehouse holding the most", strategy: valueobject.MostStockWarehouse, x: 0, quantity: 5, expected: allocations("W2", 5)},
+		{name: "split takes from the nearest warehouses first", strategy: valueobject.SplitAcrossWarehouses, x: 9, quantity: 35, expected: allocations("W2", 30, "W1", 5)},
+		{name: "nearest splits when no warehouse holds enough", strategy: valueobject.NearestWarehouse, x: 0, quantity: 42, expected: allocations("W1", 10, "W2", 30)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should allocate when "+testCase.name, func(t *testing.T) {
+			orderService, product := setupWarehouses()
+
+			result, err := orderService.CreateWithStrategy(product, testCase.quantity, testCase.strategy, testCase.x, 0, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, result.Allocations)
+			assert.Equal(t, 45-testCase.quantity, product.Stock.Value())
+
+			held := 0
+			for _, item := range product.Inventory {
+				held += item.Stock.Value()
+			}
+			allocated := 0
+			for _, item := range result.Allocations {
+				allocated += item.Quantity
+			}
+			assert.Equal(t, 40-allocated, held)
+		})
+	}
+
+	t.Run("should take unassigned stock last", func(t *testing.T) {
+		orderService, product := setupWarehouses()
+
+		result, err := orderService.Create(product, 43)
+		assert.NoError(t, err)
+		assert.Equal(t, allocations("W1", 10, "W2", 30), result.Allocations)
+		assert.Equal(t, 2, product.Stock.Value())
+		assert.Equal(t, 2, product.Unassigned())
+	})
+
+	t.Run("should return error when strategy is invalid", func(t *testing.T) {
+		orderService, product := setupWarehouses()
+
+		_, err := orderService.CreateWithStrategy(product, 1, "closest", 0, 0, nil, nil)
+		assert.ErrorIs(t, err, valueobject.ErrAllocationStrategyMustBeOneOf)
+		assert.Equal(t, 45, product.Stock.Value())
+	})
+
+	t.Run("should keep inventory when order fails", func(t *testing.T) {
+		orderService, product := setupWarehouses()
+		inventory := product.Inventory
+
+		_, err := orderService.Create(product, 46)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Equal(t, inventory, product.Inventory)
+	})
+}
+
+func TestOrderService_Ledger(t *testing.T) {
+	code, _ := valueobject.NewCode("P1")
+	price, _ := valueobject.NewPrice(10)
+	w1, _ := valueobject.NewCode("W1")
+
+	type entry struct {
+		movementType string
+		quantity     int
+		reference    uuid.UUID
+	}
+
+	entries := func(repositories memoryRepositories, product *entity.Product) []entry {
+		var result []entry
+		for _, movement := range repositories.ledgerRepository.ListByProduct(product.ID) {
+			assert.Equal(t, orderTime, movement.Time)
+			result = append(result, entry{movement.Type.Value(), movement.Quantity, movement.Reference})
+		}
+		return result
+	}
+
+	orderService, repositories := setupMemory()
+	stock, _ := valueobject.NewStock(5)
+	limit, _ := valueobject.NewStock(10)
+	product := &entity.Product{ID: uuid.New(), Code: code, Stock: stock, InititalStock: stock, Price: price, InititalPrice: price, BackorderLimit: limit}
+	product.AssignStock(w1, 3)
+	repositories.productRepository.Create(product)
+
+	first, err := orderService.Create(product, 4)
+	assert.NoError(t, err)
+	second, err := orderService.Create(product, 3)
+	assert.NoError(t, err)
+	_, err = orderService.Restock(product, 4)
+	assert.NoError(t, err)
+
+	t.Run("should record sales, restocks and reservations", func(t *testing.T) {
+		assert.Equal(t, []entry{
+			{valueobject.SaleMovement, -4, first.ID},
+			{valueobject.SaleMovement, -1, second.ID},
+			{valueobject.RestockMovement, 4, product.ID},
+			{valueobject.ReservationMovement, -2, second.ID},
+		}, entries(repositories, product))
+	})
+
+	t.Run("should return cancelled units to their warehouses", func(t *testing.T) {
+		_, err := orderService.ChangeStatus(first.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+
+		assert.Equal(t, entry{valueobject.CancellationMovement, 4, first.ID}, entries(repositories, product)[4])
+		assert.Equal(t, 6, product.Stock.Value())
+		assert.Equal(t, 9, product.InititalStock.Value())
+		assert.Equal(t, 3, product.Inventory[0].Stock.Value())
+	})
+
+	t.Run("should not record anything for a cancelled backorder that took no stock", func(t *testing.T) {
+		otherCode, _ := valueobject.NewCode("P2")
+		other := &entity.Product{ID: uuid.New(), Code: otherCode, Price: price, InititalPrice: price, BackorderLimit: limit}
+		repositories.productRepository.Create(other)
+
+		result, err := orderService.Create(other, 2)
+		assert.NoError(t, err)
+		_, err = orderService.ChangeStatus(result.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+
+		assert.Empty(t, entries(repositories, other))
+		assert.Equal(t, 0, other.Backordered.Value())
+	})
+}
+
+func TestOrderService_Bundles(t *testing.T) {
+	price, _ := valueobject.NewPrice(10)
+	w1, _ := valueobject.NewCode("W1")
+
+	// setupBundle stores P1 with 10 units, 4 of them in W1, P2 with 9 units
+	// and the bundle B1 of one P1 and two P2.
+	setupBundle := func() (*OrderService, memoryRepositories, *entity.Product, *entity.Product, *entity.Product) {
+		orderService, repositories := setupMemory()
+		newProduct := func(productCode string, stockCount int) *entity.Product {
+			code, _ := valueobject.NewCode(productCode)
+			stock, _ := valueobject.NewStock(stockCount)
+			product := &entity.Product{ID: uuid.New(), Code: code, Stock: stock, InititalStock: stock, Price: price, InititalPrice: price}
+			repositories.productRepository.Create(product)
+			return product
+		}
+
+		p1, p2 := newProduct("P1", 10), newProduct("P2", 9)
+		p1.AssignStock(w1, 4)
+
+		code, _ := valueobject.NewCode("B1")
+		bundle := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Components: []entity.BundleComponent{{Product: p1.Code, Quantity: 1}, {Product: p2.Code, Quantity: 2}}}
+		repositories.productRepository.Create(bundle)
+		return orderService, repositories, bundle, p1, p2
+	}
+
+	t.Run("should take every component of the bundle", func(t *testing.T) {
+		orderService, repositories, bundle, p1, p2 := setupBundle()
+
+		result, err := orderService.Create(bundle, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.Allocation{{Warehouse: w1, Quantity: 3, Product: p1.Code}}, result.Allocations)
+		assert.Equal(t, 1, bundle.Stock.Value())
+		assert.Equal(t, 4, bundle.InititalStock.Value())
+		assert.Equal(t, 7, p1.Stock.Value())
+		assert.Equal(t, 3, p2.Stock.Value())
+		assert.Equal(t, 3, bundle.TotalDemandCount.Value())
+
+		assert.Empty(t, repositories.ledgerRepository.ListByProduct(bundle.ID))
+		sales := repositories.ledgerRepository.ListByProduct(p2.ID)
+		assert.Len(t, sales, 1)
+		assert.Equal(t, -6, sales[0].Quantity)
+		assert.Equal(t, result.ID, sales[0].Reference)
+
+		_, err = orderService.Create(bundle, 2)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Equal(t, 7, p1.Stock.Value())
+	})
+
+	t.Run("should sync the bundle with components sold on their own", func(t *testing.T) {
+		orderService, _, bundle, _, p2 := setupBundle()
+
+		_, err := orderService.Create(p2, 5)
+		assert.NoError(t, err)
+
+		_, err = orderService.Create(bundle, 3)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+
+		_, err = orderService.Create(bundle, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, bundle.Stock.Value())
+		assert.Equal(t, 0, p2.Stock.Value())
+	})
+
+	t.Run("should return the components when a bundle order is cancelled", func(t *testing.T) {
+		orderService, repositories, bundle, p1, p2 := setupBundle()
+
+		result, _ := orderService.Create(bundle, 2)
+		_, err := orderService.ChangeStatus(result.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, p1.Stock.Value())
+		assert.Equal(t, 4, p1.Inventory[0].Stock.Value())
+		assert.Equal(t, 9, p2.Stock.Value())
+		assert.Equal(t, 4, bundle.Stock.Value())
+
+		movements := repositories.ledgerRepository.ListByProduct(p2.ID)
+		assert.Equal(t, valueobject.CancellationMovement, movements[len(movements)-1].Type.Value())
+		assert.Equal(t, 4, movements[len(movements)-1].Quantity)
+	})
+
+	t.Run("should count bundle sales towards the bundle campaign", func(t *testing.T) {
+		orderService, repositories, bundle, _, _ := setupBundle()
+
+		name, _ := valueobject.NewName("C1")
+		target, _ := valueobject.NewTargetSalesCount(4)
+		status, _ := valueobject.NewStatus(valueobject.Active)
+		campaign := &entity.Campaign{Name: name, Product: bundle, TargetSalesCount: target, Status: status}
+		bundle.Campaign = campaign
+		repositories.campaignRepository.Create(campaign)
+
+		result, err := orderService.Create(bundle, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "C1", result.Campaign.Value())
+		assert.Equal(t, 2, campaign.TotalSales.Value())
+	})
+
+	t.Run("should return error when a bundle is restocked", func(t *testing.T) {
+		orderService, _, bundle, _, _ := setupBundle()
+
+		_, err := orderService.Restock(bundle, 1)
+		assert.ErrorIs(t, err, ErrCannotBeRestocked)
+	})
+}
+
+func TestOrderService_Variants(t *testing.T) {
+	price, _ := valueobject.NewPrice(20)
+	status, _ := valueobject.NewStatus(valueobject.Active)
+
+	// setupVariants stores the parent T1 with the variants V1 of 6 units and V2
+	// of 4 units, both sold at the price of T1.
+	setupVariants := func() (*OrderService, memoryRepositories, *entity.Product, *entity.Product, *entity.Product) {
+		orderService, repositories := setupMemory()
+		code, _ := valueobject.NewCode("T1")
+		stock, _ := valueobject.NewStock(10)
+		parent := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock}
+
+		newVariant := func(productCode string, stockCount int) *entity.Product {
+			code, _ := valueobject.NewCode(productCode)
+			stock, _ := valueobject.NewStock(stockCount)
+			variant := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, Parent: parent.Code}
+			parent.Variants = append(parent.Variants, code)
+			repositories.productRepository.Create(variant)
+			return variant
+		}
+
+		v1, v2 := newVariant("V1", 6), newVariant("V2", 4)
+		repositories.productRepository.Create(parent)
+		return orderService, repositories, parent, v1, v2
+	}
+
+	newCampaign := func(repositories memoryRepositories, campaignName string, product *entity.Product) *entity.Campaign {
+		name, _ := valueobject.NewName(campaignName)
+		target, _ := valueobject.NewTargetSalesCount(8)
+		campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status}
+		product.Campaign = campaign
+		repositories.campaignRepository.Create(campaign)
+		return campaign
+	}
+
+	t.Run("should return error when a parent is ordered", func(t *testing.T) {
+		orderService, _, parent, _, _ := setupVariants()
+
+		_, err := orderService.Create(parent, 1)
+		assert.ErrorIs(t, err, ErrParentCannotBeOrdered)
+	})
+
+	t.Run("should bring the parent up to date with an order of a variant", func(t *testing.T) {
+		orderService, repositories, parent, v1, _ := setupVariants()
+
+		_, err := orderService.Create(v1, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, v1.Stock.Value())
+		assert.Equal(t, 6, parent.Stock.Value())
+		assert.Equal(t, 10, parent.InititalStock.Value())
+		assert.Equal(t, 4, parent.TotalDemandCount.Value())
+		assert.Empty(t, repositories.ledgerRepository.ListByProduct(parent.ID))
+	})
+
+	t.Run("should sell a variant at the price of its parent", func(t *testing.T) {
+		orderService, _, parent, v1, v2 := setupVariants()
+		parent.Price, _ = valueobject.NewPrice(15)
+		v2.PriceOverride = true
+
+		result, err := orderService.Create(v1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 15.0, result.Price.Value())
+
+		result, err = orderService.Create(v2, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 20.0, result.Price.Value())
+	})
+
+	t.Run("should count sales of every variant towards the parent campaign", func(t *testing.T) {
+		orderService, repositories, parent, v1, v2 := setupVariants()
+		parentCampaign := newCampaign(repositories, "C1", parent)
+
+		result, err := orderService.Create(v1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "C1", result.Campaign.Value())
+
+		_, err = orderService.Create(v2, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, parentCampaign.TotalSales.Value())
+	})
+
+	t.Run("should count sales towards the variant and the parent campaign", func(t *testing.T) {
+		orderService, repositories, parent, v1, v2 := setupVariants()
+		parentCampaign := newCampaign(repositories, "C1", parent)
+		variantCampaign := newCampaign(repositories, "C2", v1)
+
+		result, err := orderService.Create(v1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "C2", result.Campaign.Value())
+		assert.Equal(t, 2, variantCampaign.TotalSales.Value())
+
+		_, err = orderService.Create(v2, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, variantCampaign.TotalSales.Value())
+		assert.Equal(t, 3, parentCampaign.TotalSales.Value())
+	})
+
+	t.Run("should count a sale once towards a category campaign of both the variant and the parent", func(t *testing.T) {
+		orderService, repositories, parent, v1, v2 := setupVariants()
+		categoryCampaign := newCampaign(repositories, "C1", parent)
+		categoryCampaign.Product = nil
+		categoryCampaign.Category, _ = valueobject.NewCode("K1")
+		categoryCampaign.Members = []*entity.Product{parent, v1}
+		v1.Campaign = categoryCampaign
+
+		result, err := orderService.Create(v1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "C1", result.Campaign.Value())
+		assert.Equal(t, 2, categoryCampaign.TotalSales.Value())
+
+		_, err = orderService.Create(v2, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, categoryCampaign.TotalSales.Value())
+	})
+
+	t.Run("should bring the parent up to date when a variant order is cancelled", func(t *testing.T) {
+		orderService, _, parent, v1, _ := setupVariants()
+
+		result, _ := orderService.Create(v1, 3)
+		_, err := orderService.ChangeStatus(result.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, v1.Stock.Value())
+		assert.Equal(t, 10, parent.Stock.Value())
+	})
+
+	t.Run("should return error when a parent is restocked", func(t *testing.T) {
+		orderService, _, parent, _, _ := setupVariants()
+
+		_, err := orderService.Restock(parent, 1)
+		assert.ErrorIs(t, err, ErrCannotBeRestocked)
+	})
+}
+
+func TestOrderService_Coupons(t *testing.T) {
+	price, _ := valueobject.NewPrice(50)
+	status, _ := valueobject.NewStatus(valueobject.Active)
+
+	// setupCoupons stores the product P1 of 10 units in the category K2 below
+	// K1.
+	setupCoupons := func() (*OrderService, memoryRepositories, *entity.Product) {
+		orderService, repositories := setupMemory()
+		parent, _ := valueobject.NewCode("K1")
+		category, _ := valueobject.NewCode("K2")
+		repositories.categoryRepository.Create(&entity.Category{ID: uuid.New(), Code: parent})
+		repositories.categoryRepository.Create(&entity.Category{ID: uuid.New(), Code: category, Parent: parent})
+
+		code, _ := valueobject.NewCode("P1")
+		stock, _ := valueobject.NewStock(10)
+		product := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, Category: category}
+		repositories.productRepository.Create(product)
+		return orderService, repositories, product
+	}
+
+	newCoupon := func(repositories memoryRepositories, discountType string, amount float64, usageLimit int) *entity.Coupon {
+		code, _ := valueobject.NewCode("SAVE")
+		discount, _ := valueobject.NewDiscount(discountType, amount)
+		coupon := &entity.Coupon{ID: uuid.New(), Code: code, Discount: discount, UsageLimit: usageLimit}
+		repositories.couponRepository.Create(coupon)
+		return coupon
+	}
+
+	codes := func(values ...string) []valueobject.Code {
+		var result []valueobject.Code
+		for _, value := range values {
+			code, _ := valueobject.NewCode(value)
+			result = append(result, code)
+		}
+		return result
+	}
+
+	t.Run("should take a fixed discount off the order", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 0)
+
+		result, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "SAVE", result.Coupon.Value())
+		assert.Equal(t, 10.0, result.Discount.Value())
+		assert.Equal(t, 90.0, result.Total())
+		assert.Equal(t, 45.0, result.NetPrice())
+		assert.Equal(t, 1, coupon.Uses)
+		assert.Equal(t, 1, coupon.Version)
+	})
+
+	t.Run("should not take more than the order total off", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 500, 0)
+
+		result, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, result.Discount.Value())
+		assert.Equal(t, 0.0, result.Total())
+	})
+
+	t.Run("should count the net price towards the campaign turnover", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.PercentDiscount, 20, 0)
+		name, _ := valueobject.NewName("C1")
+		target, _ := valueobject.NewTargetSalesCount(8)
+		campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status}
+		product.Campaign = campaign
+		repositories.campaignRepository.Create(campaign)
+
+		_, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.NoError(t, err)
+		_, err = orderService.Create(product, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, campaign.TotalSales.Value())
+		assert.Equal(t, 45.0, campaign.AverageItemPrice.Value())
+	})
+
+	t.Run("should return error when the coupon has expired", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 0)
+		coupon.ExpiresAt = orderTime
+
+		_, err := orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.ErrorIs(t, err, ErrCouponExpired)
+		assert.Equal(t, 10, product.Stock.Value())
+		assert.Equal(t, 0, coupon.Uses)
+	})
+
+	t.Run("should return error when the coupon is used up", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 1)
+
+		_, err := orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.NoError(t, err)
+
+		_, err = orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.ErrorIs(t, err, ErrCouponUsedUp)
+		assert.Equal(t, 9, product.Stock.Value())
+		assert.Equal(t, 1, coupon.Uses)
+	})
+
+	t.Run("should apply to the products and categories it is restricted to", func(t *testing.T) {
+		for _, restriction := range []struct {
+			products   []valueobject.Code
+			categories []valueobject.Code
+			err        error
+		}{
+			{products: codes("P1")},
+			{categories: codes("K2")},
+			{categories: codes("K1")},
+			{products: codes("P2"), categories: codes("K3"), err: ErrCouponNotApplicable},
+		} {
+			orderService, repositories, product := setupCoupons()
+			coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 0)
+			coupon.Products = restriction.products
+			coupon.Categories = restriction.categories
+
+			_, err := orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+			if restriction.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, restriction.err)
+			}
+		}
+	})
+
+	t.Run("should stop at a category that is its own ancestor", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		category, _ := repositories.categoryRepository.Get(product.Category)
+		parent, _ := repositories.categoryRepository.Get(category.Parent)
+		parent.Parent = category.Code
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 0)
+		coupon.Categories = codes("K3")
+
+		_, err := orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.ErrorIs(t, err, ErrCouponNotApplicable)
+	})
+
+	t.Run("should keep the coupon use when the order fails", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 0)
+
+		_, err := orderService.CreateWithStrategy(product, 11, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.ErrorIs(t, err, ErrInsufficientStock)
+		assert.Equal(t, 0, coupon.Uses)
+		assert.Equal(t, 0, coupon.Version)
+	})
+
+	t.Run("should give the coupon use back when the order is cancelled", func(t *testing.T) {
+		orderService, repositories, product := setupCoupons()
+		coupon := newCoupon(repositories, valueobject.FixedDiscount, 10, 1)
+
+		result, _ := orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		_, err := orderService.ChangeStatus(result.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, coupon.Uses)
+
+		_, err = orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.NoError(t, err)
+	})
+}
+
+func TestOrderService_PriceTiers(t *testing.T) {
+	price, _ := valueobject.NewPrice(20)
+	status, _ := valueobject.NewStatus(valueobject.Active)
+	tiers := []entity.PriceTier{{MinQuantity: 10, Percent: 5}, {MinQuantity: 100, Percent: 12}}
+
+	// setupTiers stores the product P1 of 200 units, 10+ units 5% off and 100+
+	// units 12% off.
+	setupTiers := func() (*OrderService, memoryRepositories, *entity.Product) {
+		orderService, repositories := setupMemory()
+		code, _ := valueobject.NewCode("P1")
+		stock, _ := valueobject.NewStock(200)
+		product := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, PriceTiers: tiers}
+		repositories.productRepository.Create(product)
+		return orderService, repositories, product
+	}
+
+	t.Run("should charge the list price below the first tier", func(t *testing.T) {
+		orderService, _, product := setupTiers()
+
+		result, err := orderService.Create(product, 9)
+		assert.NoError(t, err)
+		assert.Equal(t, 20.0, result.Price.Value())
+		assert.Equal(t, 0.0, result.ListPrice.Value())
+		assert.Equal(t, 0.0, result.TierPercent)
+	})
+
+	t.Run("should take the highest tier reached off the unit price", func(t *testing.T) {
+		orderService, _, product := setupTiers()
+
+		result, err := orderService.Create(product, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 19.0, result.Price.Value())
+		assert.Equal(t, 20.0, result.ListPrice.Value())
+		assert.Equal(t, 5.0, result.TierPercent)
+		assert.Equal(t, 190.0, result.Total())
+
+		result, err = orderService.Create(product, 100)
+		assert.NoError(t, err)
+		assert.InDelta(t, 17.6, result.Price.Value(), 0.001)
+		assert.Equal(t, 12.0, result.TierPercent)
+	})
+
+	t.Run("should apply the coupon after the tier", func(t *testing.T) {
+		orderService, repositories, product := setupTiers()
+		code, _ := valueobject.NewCode("SAVE")
+		discount, _ := valueobject.NewDiscount(valueobject.PercentDiscount, 10)
+		coupon := &entity.Coupon{ID: uuid.New(), Code: code, Discount: discount}
+		repositories.couponRepository.Create(coupon)
+
+		result, err := orderService.CreateWithStrategy(product, 10, valueobject.SplitAcrossWarehouses, 0, 0, coupon, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 19.0, result.Discount.Value())
+		assert.Equal(t, 171.0, result.Total())
+	})
+
+	t.Run("should count the tier price towards the campaign turnover", func(t *testing.T) {
+		orderService, repositories, product := setupTiers()
+		name, _ := valueobject.NewName("C1")
+		target, _ := valueobject.NewTargetSalesCount(50)
+		campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status}
+		product.Campaign = campaign
+		repositories.campaignRepository.Create(campaign)
+
+		_, err := orderService.Create(product, 10)
+		assert.NoError(t, err)
+		_, err = orderService.Create(product, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 15, campaign.TotalSales.Value())
+		assert.InDelta(t, 290.0/15, campaign.AverageItemPrice.Value(), 0.001)
+	})
+
+	t.Run("should price a variant by the tiers of its parent unless it has its own", func(t *testing.T) {
+		orderService, repositories := setupMemory()
+		parentCode, _ := valueobject.NewCode("T1")
+		stock, _ := valueobject.NewStock(20)
+		parent := &entity.Product{ID: uuid.New(), Code: parentCode, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, PriceTiers: tiers}
+
+		newVariant := func(productCode string, priceTiers []entity.PriceTier) *entity.Product {
+			code, _ := valueobject.NewCode(productCode)
+			stock, _ := valueobject.NewStock(10)
+			variant := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, Parent: parentCode, PriceTiers: priceTiers}
+			parent.Variants = append(parent.Variants, code)
+			repositories.productRepository.Create(variant)
+			return variant
+		}
+
+		v1 := newVariant("V1", nil)
+		v2 := newVariant("V2", []entity.PriceTier{{MinQuantity: 10, Percent: 8}})
+		repositories.productRepository.Create(parent)
+
+		result, err := orderService.Create(v1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 5.0, result.TierPercent)
+
+		result, err = orderService.Create(v2, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 8.0, result.TierPercent)
+		assert.Equal(t, 18.4, result.Price.Value())
+	})
+}
+
+func TestOrderService_Taxes(t *testing.T) {
+	price, _ := valueobject.NewPrice(50)
+	status, _ := valueobject.NewStatus(valueobject.Active)
+	standard, _ := valueobject.NewCode(entity.StandardTaxClass)
+	reduced, _ := valueobject.NewCode("REDUCED")
+	regionCode, _ := valueobject.NewCode("EU")
+
+	// setupTaxes stores the product P1 of 10 units and returns the region EU,
+	// which taxes the standard class at 20% and the reduced class at 7%.
+	setupTaxes := func() (*OrderService, memoryRepositories, *entity.Product, *entity.Region) {
+		orderService, repositories := setupMemory()
+		code, _ := valueobject.NewCode("P1")
+		stock, _ := valueobject.NewStock(10)
+		product := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock}
+		repositories.productRepository.Create(product)
+
+		region := &entity.Region{ID: uuid.New(), Code: regionCode, Rates: []entity.TaxRate{{Class: reduced, Percent: 7}, {Class: standard, Percent: 20}}}
+		return orderService, repositories, product, region
+	}
+
+	t.Run("should not tax an order without a region", func(t *testing.T) {
+		orderService, _, product, _ := setupTaxes()
+
+		result, err := orderService.Create(product, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "", result.Region.Value())
+		assert.Equal(t, 0.0, result.Tax)
+		assert.Equal(t, 100.0, result.Gross())
+	})
+
+	t.Run("should tax the net total at the rate of the tax class", func(t *testing.T) {
+		orderService, _, product, region := setupTaxes()
+
+		result, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.NoError(t, err)
+		assert.Equal(t, regionCode, result.Region)
+		assert.Equal(t, 20.0, result.TaxRate)
+		assert.Equal(t, 100.0, result.Total())
+		assert.Equal(t, 20.0, result.Tax)
+		assert.Equal(t, 120.0, result.Gross())
+
+		product.TaxClass = reduced
+		result, err = orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, result.TaxRate)
+		assert.InDelta(t, 107.0, result.Gross(), 0.001)
+	})
+
+	t.Run("should tax the total after the coupon", func(t *testing.T) {
+		orderService, repositories, product, region := setupTaxes()
+		code, _ := valueobject.NewCode("SAVE")
+		discount, _ := valueobject.NewDiscount(valueobject.FixedDiscount, 10)
+		coupon := &entity.Coupon{ID: uuid.New(), Code: code, Discount: discount}
+		repositories.couponRepository.Create(coupon)
+
+		result, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, coupon, region)
+		assert.NoError(t, err)
+		assert.Equal(t, 90.0, result.Total())
+		assert.Equal(t, 18.0, result.Tax)
+		assert.Equal(t, 108.0, result.Gross())
+	})
+
+	t.Run("should return error when the region has no rate for the tax class", func(t *testing.T) {
+		orderService, _, product, region := setupTaxes()
+		product.TaxClass, _ = valueobject.NewCode("LUXURY")
+
+		_, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.ErrorIs(t, err, ErrNoTaxRate)
+		assert.Equal(t, 10, product.Stock.Value())
+	})
+
+	t.Run("should tax a variant in the class of its parent unless it has its own", func(t *testing.T) {
+		orderService, repositories, _, region := setupTaxes()
+		parentCode, _ := valueobject.NewCode("T1")
+		stock, _ := valueobject.NewStock(10)
+		parent := &entity.Product{ID: uuid.New(), Code: parentCode, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, TaxClass: reduced}
+		variantCode, _ := valueobject.NewCode("V1")
+		variant := &entity.Product{ID: uuid.New(), Code: variantCode, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, Parent: parentCode}
+		parent.Variants = []valueobject.Code{variantCode}
+		repositories.productRepository.Create(variant)
+		repositories.productRepository.Create(parent)
+
+		result, err := orderService.CreateWithStrategy(variant, 1, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.NoError(t, err)
+		assert.Equal(t, 7.0, result.TaxRate)
+
+		variant.TaxClass = standard
+		result, err = orderService.CreateWithStrategy(variant, 1, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.NoError(t, err)
+		assert.Equal(t, 20.0, result.TaxRate)
+	})
+
+	t.Run("should count the tax towards the campaign gross turnover", func(t *testing.T) {
+		orderService, repositories, product, region := setupTaxes()
+		name, _ := valueobject.NewName("C1")
+		target, _ := valueobject.NewTargetSalesCount(8)
+		campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status}
+		product.Campaign = campaign
+		repositories.campaignRepository.Create(campaign)
+
+		_, err := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.NoError(t, err)
+		_, err = orderService.Create(product, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 200.0, campaign.Turnover())
+		assert.Equal(t, 20.0, campaign.Tax)
+		assert.Equal(t, 220.0, campaign.GrossTurnover())
+	})
+
+	t.Run("should take the tax of a cancelled order back off the campaign", func(t *testing.T) {
+		orderService, repositories, product, region := setupTaxes()
+		name, _ := valueobject.NewName("C1")
+		target, _ := valueobject.NewTargetSalesCount(8)
+		campaign := &entity.Campaign{Name: name, Product: product, TargetSalesCount: target, Status: status}
+		product.Campaign = campaign
+		repositories.campaignRepository.Create(campaign)
+
+		cancelled, _ := orderService.CreateWithStrategy(product, 2, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		_, err := orderService.CreateWithStrategy(product, 1, valueobject.SplitAcrossWarehouses, 0, 0, nil, region)
+		assert.NoError(t, err)
+
+		_, err = orderService.ChangeStatus(cancelled.ID.String(), valueobject.Cancelled)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, campaign.TotalSales.Value())
+		assert.Equal(t, 50.0, campaign.Turnover())
+		assert.Equal(t, 10.0, campaign.Tax)
 	})
 }
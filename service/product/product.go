@@ -1,63 +1,620 @@
 package product
 
 import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
 	"github.com/aaydin-tr/e-commerce/domain/product"
 	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
 	"github.com/aaydin-tr/e-commerce/valueobject"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrBackorderLimitCannotBeNegative   = errors.New("Backorder limit cannot be negative")
+	ErrAdjustmentCannotBeZero           = errors.New("Adjustment cannot be zero")
+	ErrAdjustmentExceedsUnassignedStock = errors.New("Adjustment exceeds unassigned stock")
+	ErrBundleMustHaveComponents         = errors.New("Bundle must have components")
+	ErrComponentQuantityMustBePositive  = errors.New("Component quantity must be positive")
+	ErrComponentMustHoldStock           = errors.New("Bundle component must hold stock of its own")
+	ErrStockIsDerived                   = errors.New("Stock of bundles and parent products is derived from other products")
+	ErrCannotBeBackordered              = errors.New("Bundles and parent products cannot be backordered or pre-ordered")
+	ErrVariantMustHaveAttributes        = errors.New("Variant must have attributes")
+	ErrAttributeCannotBeEmpty           = errors.New("Attribute name and value cannot be empty")
+	ErrVariantAlreadyExists             = errors.New("Parent already has a variant with these attributes")
+	ErrInvalidParent                    = errors.New("Parent cannot be a bundle or a variant")
+	ErrParentHoldsStock                 = errors.New("Parent cannot hold stock of its own")
+	ErrParentIsBundleComponent          = errors.New("Parent cannot be a component of a bundle")
+	ErrTierQuantityTooLow               = errors.New("Tier minimum quantity must be more than one")
+	ErrTierPercentOutOfRange            = errors.New("Tier discount must be more than 0 and less than 100 percent")
+	ErrTierPercentMustIncrease          = errors.New("Tier discount must grow with the minimum quantity")
+)
+
 type ProductServiceInterface interface {
 	Create(productCode string, productPrice float64, productStock int) error
+	CreateWithBackorders(productCode string, productPrice float64, productStock int, backorderLimit int, launchAt time.Time) (*entity.Product, error)
+	CreateBundle(productCode string, productPrice float64, components map[string]int) (*entity.Product, error)
+	CreateVariant(parentCode string, productCode string, productPrice float64, productStock int, attributes map[string]string) (*entity.Product, error)
 	Get(productCode string) (*entity.Product, error)
+	GetByID(id uuid.UUID) (*entity.Product, error)
+	GetAll() []*entity.Product
+	AllowBackorders(productCode string, backorderLimit int, launchAt time.Time) (*entity.Product, error)
+	AdjustStock(productCode string, amount int) (*entity.Product, error)
+	SetPriceTiers(productCode string, tiers map[int]float64) (*entity.Product, error)
+	SetTaxClass(productCode string, taxClass string) (*entity.Product, error)
 }
 
 type ProductService struct {
 	productRepository product.ProductRepository
+	ledgerRepository  ledger.LedgerRepository
+	unitOfWork        types.UnitOfWork
+	clock             types.Clock
 }
 
-func NewProductService(productRepository product.ProductRepository) *ProductService {
-	return &ProductService{productRepository: productRepository}
+func NewProductService(productRepository product.ProductRepository, ledgerRepository ledger.LedgerRepository, unitOfWork types.UnitOfWork, clock types.Clock) *ProductService {
+	return &ProductService{
+		productRepository: productRepository,
+		ledgerRepository:  ledgerRepository,
+		unitOfWork:        unitOfWork,
+		clock:             clock,
+	}
 }
 
 func (s *ProductService) Create(productCode string, productPrice float64, productStock int) error {
+	_, err := s.CreateWithBackorders(productCode, productPrice, productStock, 0, time.Time{})
+	return err
+}
+
+// CreateWithBackorders creates a product that accepts up to backorderLimit
+// units beyond its stock and puts its stock on sale at launchAt, or at once
+// when launchAt is zero. The product is stored with these settings in place.
+func (s *ProductService) CreateWithBackorders(productCode string, productPrice float64, productStock int, backorderLimit int, launchAt time.Time) (*entity.Product, error) {
 	code, err := valueobject.NewCode(productCode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	price, err := valueobject.NewPrice(productPrice)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	stock, err := valueobject.NewStock(productStock)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	limit, err := valueobject.NewStock(backorderLimit)
+	if err != nil {
+		return nil, ErrBackorderLimitCannotBeNegative
 	}
 
 	product := &entity.Product{
+		ID:             uuid.New(),
+		Code:           code,
+		Price:          price,
+		Stock:          stock,
+		InititalStock:  stock,
+		InititalPrice:  price,
+		BackorderLimit: limit,
+		LaunchAt:       launchAt,
+	}
+
+	err = s.unitOfWork.Do(func() error {
+		err := s.productRepository.Create(product)
+		if err != nil {
+			return err
+		}
+
+		return s.record(product, valueobject.InitialMovement, stock.Value())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (s *ProductService) Get(productCode string) (*entity.Product, error) {
+	code, err := valueobject.NewCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.productRepository.Get(code)
+}
+
+func (s *ProductService) GetByID(id uuid.UUID) (*entity.Product, error) {
+	return s.productRepository.GetByID(id)
+}
+
+// GetAll returns every product ordered by code.
+func (s *ProductService) GetAll() []*entity.Product {
+	return s.productRepository.GetAll()
+}
+
+// CreateBundle creates a product made of the given quantities of other
+// products, keyed by code. The bundle starts with as much stock as its
+// components make up.
+func (s *ProductService) CreateBundle(productCode string, productPrice float64, components map[string]int) (*entity.Product, error) {
+	if len(components) == 0 {
+		return nil, ErrBundleMustHaveComponents
+	}
+
+	code, err := valueobject.NewCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := valueobject.NewPrice(productPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &entity.Product{
 		ID:            uuid.New(),
 		Code:          code,
 		Price:         price,
-		Stock:         stock,
-		InititalStock: stock,
 		InititalPrice: price,
 	}
 
-	return s.productRepository.Create(product)
+	for componentCode, quantity := range components {
+		if quantity <= 0 {
+			return nil, ErrComponentQuantityMustBePositive
+		}
+
+		code, err := valueobject.NewCode(componentCode)
+		if err != nil {
+			return nil, err
+		}
+
+		bundle.Components = append(bundle.Components, entity.BundleComponent{Product: code, Quantity: quantity})
+	}
+	sort.Slice(bundle.Components, func(i, j int) bool {
+		return bundle.Components[i].Product.Value() < bundle.Components[j].Product.Value()
+	})
+
+	items, release, err := s.components(bundle)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = bundle.SyncStock(items)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.productRepository.Create(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
 }
 
-func (s *ProductService) Get(productCode string) (*entity.Product, error) {
+// CreateVariant creates a variant of the parent product described by
+// attributes, keyed by name. A productPrice of zero sells the variant at the
+// price of its parent. The first variant turns a product without stock into a
+// parent.
+func (s *ProductService) CreateVariant(parentCode string, productCode string, productPrice float64, productStock int, attributes map[string]string) (*entity.Product, error) {
+	if len(attributes) == 0 {
+		return nil, ErrVariantMustHaveAttributes
+	}
+
 	code, err := valueobject.NewCode(productCode)
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := s.productRepository.Get(code)
+	stock, err := valueobject.NewStock(productStock)
+	if err != nil {
+		return nil, err
+	}
+
+	variantAttributes := make([]entity.VariantAttribute, 0, len(attributes))
+	for name, value := range attributes {
+		if name == "" || value == "" {
+			return nil, ErrAttributeCannotBeEmpty
+		}
+
+		variantAttributes = append(variantAttributes, entity.VariantAttribute{Name: name, Value: value})
+	}
+	sort.Slice(variantAttributes, func(i, j int) bool {
+		return variantAttributes[i].Name < variantAttributes[j].Name
+	})
+
+	parentProductCode, err := valueobject.NewCode(parentCode)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := s.productRepository.Get(parentProductCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.inBundle(parent) {
+		return nil, ErrParentIsBundleComponent
+	}
+
+	parent.Acquire()
+	defer parent.Release()
+
+	if parent.IsBundle() || parent.IsVariant() {
+		return nil, ErrInvalidParent
+	}
+
+	if !parent.IsParent() && (parent.InititalStock.Value() > 0 || parent.BackorderLimit.Value() > 0) {
+		return nil, ErrParentHoldsStock
+	}
+
+	variants, release, err := s.variants(parent)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	for _, item := range variants {
+		if item.HasAttributes(variantAttributes) {
+			return nil, ErrVariantAlreadyExists
+		}
+	}
+
+	variant := &entity.Product{
+		ID:            uuid.New(),
+		Code:          code,
+		Price:         parent.Price,
+		Stock:         stock,
+		InititalStock: stock,
+		InititalPrice: parent.InititalPrice,
+		Parent:        parent.Code,
+		Attributes:    variantAttributes,
+	}
+
+	if productPrice != 0 {
+		price, err := valueobject.NewPrice(productPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		variant.Price = price
+		variant.InititalPrice = price
+		variant.PriceOverride = true
+	}
+
+	err = s.unitOfWork.Do(func() error {
+		uow.Track(s.unitOfWork, parent)
+		version := parent.Version
+
+		err := s.productRepository.Create(variant)
+		if err != nil {
+			return err
+		}
+
+		err = s.record(variant, valueobject.InitialMovement, stock.Value())
+		if err != nil {
+			return err
+		}
+
+		codes := make([]valueobject.Code, 0, len(parent.Variants)+1)
+		codes = append(codes, parent.Variants...)
+		codes = append(codes, code)
+		sort.Slice(codes, func(i, j int) bool {
+			return codes[i].Value() < codes[j].Value()
+		})
+		parent.Variants = codes
+
+		variants[code.Value()] = variant
+		err = parent.SyncVariants(variants)
+		if err != nil {
+			return err
+		}
+
+		return s.productRepository.Update(parent, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+// AllowBackorders lets the product take orders for up to backorderLimit units
+// beyond its stock and, when launchAt is not zero, pre-orders until launchAt.
+func (s *ProductService) AllowBackorders(productCode string, backorderLimit int, launchAt time.Time) (*entity.Product, error) {
+	limit, err := valueobject.NewStock(backorderLimit)
+	if err != nil {
+		return nil, ErrBackorderLimitCannotBeNegative
+	}
+
+	result, err := s.Get(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	if !result.HoldsStock() {
+		return nil, ErrCannotBeBackordered
+	}
+
+	version := result.Version
+	previousLimit, previousLaunchAt := result.BackorderLimit, result.LaunchAt
+	result.BackorderLimit = limit
+	result.LaunchAt = launchAt
+
+	err = s.productRepository.Update(result, version)
+	if err != nil {
+		result.BackorderLimit, result.LaunchAt = previousLimit, previousLaunchAt
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AdjustStock corrects the stock of the product by amount, which is negative
+// when units are written off, and brings the stock of the bundles and parents
+// made of it up to date. Only unassigned stock can be written off.
+func (s *ProductService) AdjustStock(productCode string, amount int) (*entity.Product, error) {
+	if amount == 0 {
+		return nil, ErrAdjustmentCannotBeZero
+	}
+
+	result, err := s.Get(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	group := entity.LockStock(s.productRepository.GetAll, result)
+	defer group.Release()
+
+	if !result.HoldsStock() {
+		return nil, ErrStockIsDerived
+	}
+
+	if -amount > result.Unassigned() {
+		return nil, ErrAdjustmentExceedsUnassignedStock
+	}
+
+	err = s.unitOfWork.Do(func() error {
+		uow.Track(s.unitOfWork, result)
+		version := result.Version
+
+		err := result.AdjustStock(amount)
+		if err != nil {
+			return err
+		}
+
+		err = s.record(result, valueobject.AdjustmentMovement, amount)
+		if err != nil {
+			return err
+		}
+
+		err = s.productRepository.Update(result, version)
+		if err != nil {
+			return err
+		}
+
+		return s.derive(group)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetPriceTiers replaces the volume tiers of the product with tiers, the
+// percent taken off the unit price keyed by the minimum quantity. Larger
+// orders must get larger discounts. No tiers clears them.
+func (s *ProductService) SetPriceTiers(productCode string, tiers map[int]float64) (*entity.Product, error) {
+	var priceTiers []entity.PriceTier
+	for minQuantity, percent := range tiers {
+		if minQuantity <= 1 {
+			return nil, ErrTierQuantityTooLow
+		}
+
+		if percent <= 0 || percent >= 100 || math.IsNaN(percent) {
+			return nil, ErrTierPercentOutOfRange
+		}
+
+		priceTiers = append(priceTiers, entity.PriceTier{MinQuantity: minQuantity, Percent: percent})
+	}
+
+	sort.Slice(priceTiers, func(i, j int) bool {
+		return priceTiers[i].MinQuantity < priceTiers[j].MinQuantity
+	})
+
+	for i := 1; i < len(priceTiers); i++ {
+		if priceTiers[i].Percent <= priceTiers[i-1].Percent {
+			return nil, ErrTierPercentMustIncrease
+		}
+	}
+
+	result, err := s.Get(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	version := result.Version
+	previousTiers := result.PriceTiers
+	result.PriceTiers = priceTiers
+
+	err = s.productRepository.Update(result, version)
+	if err != nil {
+		result.PriceTiers = previousTiers
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SetTaxClass puts the product in the tax class taxClass. An empty taxClass
+// clears it, so the product is taxed in the class of its parent or the
+// standard class.
+func (s *ProductService) SetTaxClass(productCode string, taxClass string) (*entity.Product, error) {
+	var class valueobject.Code
+	if taxClass != "" {
+		var err error
+		class, err = valueobject.NewCode(taxClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.Get(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	err = s.unitOfWork.Do(func() error {
+		uow.Track(s.unitOfWork, result)
+		version := result.Version
+
+		result.TaxClass = class
+		return s.productRepository.Update(result, version)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
+
+// derive brings every bundle and parent of group up to date with the stock of
+// the products it is made of. It runs in the unit of work that changed the
+// stock.
+func (s *ProductService) derive(group *entity.StockGroup) error {
+	for _, item := range group.Derived() {
+		if group.InSync(item) {
+			continue
+		}
+
+		version := item.Version
+		uow.Track(s.unitOfWork, item)
+
+		err := group.Sync(item)
+		if err != nil {
+			return err
+		}
+
+		err = s.productRepository.Update(item, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// components looks up and locks the components of bundle in code order. The
+// returned function releases them.
+func (s *ProductService) components(bundle *entity.Product) (map[string]*entity.Product, func(), error) {
+	components := make(map[string]*entity.Product, len(bundle.Components))
+	for _, component := range bundle.Components {
+		item, err := s.productRepository.Get(component.Product)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		components[component.Product.Value()] = item
+	}
+
+	release := func() {
+		for _, component := range bundle.Components {
+			components[component.Product.Value()].Release()
+		}
+	}
+
+	for _, component := range bundle.Components {
+		components[component.Product.Value()].Acquire()
+	}
+
+	for _, item := range components {
+		if !item.HoldsStock() {
+			release()
+			return nil, nil, ErrComponentMustHoldStock
+		}
+	}
+
+	return components, release, nil
+}
+
+// variants looks up and locks the variants of parent in code order. The parent
+// lock must be held. The returned function releases them.
+func (s *ProductService) variants(parent *entity.Product) (map[string]*entity.Product, func(), error) {
+	variants := make(map[string]*entity.Product, len(parent.Variants))
+	for _, code := range parent.Variants {
+		item, err := s.productRepository.Get(code)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		variants[code.Value()] = item
+	}
+
+	codes := parent.Variants
+	for _, code := range codes {
+		variants[code.Value()].Acquire()
+	}
+
+	return variants, func() {
+		for _, code := range codes {
+			variants[code.Value()].Release()
+		}
+	}, nil
+}
+
+// inBundle reports whether product is a component of any bundle. It locks one
+// product at a time, so no lock may be held.
+func (s *ProductService) inBundle(product *entity.Product) bool {
+	for _, item := range s.productRepository.GetAll() {
+		item.Acquire()
+		components := item.Components
+		item.Release()
+
+		for _, component := range components {
+			if component.Product.Equals(product.Code) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// record appends a movement of quantity units of product to the ledger. The
+// movement refers to the product itself. Nothing is recorded when no units
+// moved.
+func (s *ProductService) record(product *entity.Product, movementType string, quantity int) error {
+	if quantity == 0 {
+		return nil
+	}
+
+	kind, err := valueobject.NewMovementType(movementType)
+	if err != nil {
+		return err
+	}
+
+	return s.ledgerRepository.Append(&entity.StockMovement{
+		ID:        uuid.New(),
+		ProductID: product.ID,
+		Type:      kind,
+		Quantity:  quantity,
+		Reference: product.ID,
+		Time:      s.clock.Now(),
+	})
+}
@@ -2,27 +2,39 @@ package product
 
 import (
 	"testing"
+	"time"
 
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
 	"github.com/aaydin-tr/e-commerce/domain/product"
 	"github.com/aaydin-tr/e-commerce/entity"
+	mockLedger "github.com/aaydin-tr/e-commerce/mock/repository/ledger"
 	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
 	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
-var mockProductRepo *mockProduct.MockProductRepository
+var (
+	mockProductRepo *mockProduct.MockProductRepository
+	mockLedgerRepo  *mockLedger.MockLedgerRepository
+)
 
 func setup(t *testing.T) (*ProductService, func()) {
 	ct := gomock.NewController(t)
 
 	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+	mockLedgerRepo = mockLedger.NewMockLedgerRepository(ct)
 
-	productService := NewProductService(mockProductRepo)
+	productService := NewProductService(mockProductRepo, mockLedgerRepo, uow.New(), clock.New(clock.Start))
 
 	return productService, func() {
 		ct.Finish()
 		mockProductRepo = nil
+		mockLedgerRepo = nil
 	}
 }
 
@@ -30,10 +42,16 @@ func TestNewProductService(t *testing.T) {
 	ct := gomock.NewController(t)
 
 	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+	mockLedgerRepo = mockLedger.NewMockLedgerRepository(ct)
 
-	productService := NewProductService(mockProductRepo)
+	unitOfWork := uow.New()
+	systemClock := clock.New(clock.Start)
+	productService := NewProductService(mockProductRepo, mockLedgerRepo, unitOfWork, systemClock)
 
 	assert.Equal(t, productService.productRepository, mockProductRepo)
+	assert.Equal(t, productService.ledgerRepository, mockLedgerRepo)
+	assert.Equal(t, productService.unitOfWork, unitOfWork)
+	assert.Equal(t, productService.clock, systemClock)
 
 	ct.Finish()
 }
@@ -63,9 +81,32 @@ func TestProductServiceCreate(t *testing.T) {
 		assert.ErrorIs(t, err, product.ErrAlreadyExist)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("should return error when initial movement cannot be recorded", func(t *testing.T) {
 		mockProductRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(ledger.ErrAlreadyExist)
 		err := productService.Create("P1", 100, 100)
+		assert.ErrorIs(t, err, ledger.ErrAlreadyExist)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var created *entity.Product
+		mockProductRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(item *entity.Product) error {
+			created = item
+			return nil
+		})
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).DoAndReturn(func(movement *entity.StockMovement) error {
+			assert.Equal(t, created.ID, movement.ProductID)
+			assert.Equal(t, valueobject.InitialMovement, movement.Type.Value())
+			assert.Equal(t, 100, movement.Quantity)
+			return nil
+		})
+		err := productService.Create("P1", 100, 100)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should not record a movement when stock is zero", func(t *testing.T) {
+		mockProductRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		err := productService.Create("P2", 100, 0)
 		assert.Nil(t, err)
 	})
 }
@@ -94,3 +135,550 @@ func TestProductServiceGet(t *testing.T) {
 		assert.Equal(t, p.Code.Value(), mockProductData.Code.Value())
 	})
 }
+
+func TestProductServiceGetAll(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("P1")
+	mockProductData := []*entity.Product{{Code: code}}
+	mockProductRepo.EXPECT().GetAll().Return(mockProductData)
+
+	products := productService.GetAll()
+	assert.Equal(t, mockProductData, products)
+}
+
+func TestProductServiceGetByID(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when product is not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().GetByID(gomock.Any()).Return(nil, product.ErrNotFound)
+		_, err := productService.GetByID(uuid.New())
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockProductData := &entity.Product{ID: uuid.New()}
+		mockProductRepo.EXPECT().GetByID(mockProductData.ID).Return(mockProductData, nil)
+		p, err := productService.GetByID(mockProductData.ID)
+		assert.Nil(t, err)
+		assert.Same(t, mockProductData, p)
+	})
+}
+
+func TestProductServiceCreateWithBackorders(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	launchAt := time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC)
+
+	t.Run("should return error when backorder limit is negative", func(t *testing.T) {
+		result, err := productService.CreateWithBackorders("P1", 100, 100, -1, launchAt)
+		assert.ErrorIs(t, err, ErrBackorderLimitCannotBeNegative)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product already exist", func(t *testing.T) {
+		mockProductRepo.EXPECT().Create(gomock.Any()).Return(product.ErrAlreadyExist)
+
+		result, err := productService.CreateWithBackorders("P1", 100, 100, 10, launchAt)
+		assert.ErrorIs(t, err, product.ErrAlreadyExist)
+		assert.Nil(t, result)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockProductRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(item *entity.Product) error {
+			assert.Equal(t, 10, item.BackorderLimit.Value())
+			assert.Equal(t, launchAt, item.LaunchAt)
+			return nil
+		})
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+
+		result, err := productService.CreateWithBackorders("P1", 100, 100, 10, launchAt)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, result.BackorderLimit.Value())
+		assert.Equal(t, launchAt, result.LaunchAt)
+	})
+}
+
+func TestProductServiceAllowBackorders(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("P1")
+	launchAt := time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC)
+
+	t.Run("should return error when backorder limit is negative", func(t *testing.T) {
+		result, err := productService.AllowBackorders("P1", -1, launchAt)
+		assert.ErrorIs(t, err, ErrBackorderLimitCannotBeNegative)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(nil, product.ErrNotFound)
+
+		result, err := productService.AllowBackorders("P1", 10, launchAt)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should keep previous settings when update fails", func(t *testing.T) {
+		existing := &entity.Product{Code: code}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(&types.VersionConflictError{Aggregate: "product", Key: "P1", Expected: 0, Actual: 1})
+
+		_, err := productService.AllowBackorders("P1", 10, launchAt)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, 0, existing.BackorderLimit.Value())
+		assert.True(t, existing.LaunchAt.IsZero())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := &entity.Product{Code: code}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil)
+
+		result, err := productService.AllowBackorders("P1", 10, launchAt)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, result.BackorderLimit.Value())
+		assert.Equal(t, launchAt, result.LaunchAt)
+	})
+}
+
+func TestProductServiceSetPriceTiers(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("P1")
+
+	t.Run("should return error when minimum quantity is one", func(t *testing.T) {
+		result, err := productService.SetPriceTiers("P1", map[int]float64{1: 5})
+		assert.ErrorIs(t, err, ErrTierQuantityTooLow)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when percent is out of range", func(t *testing.T) {
+		_, err := productService.SetPriceTiers("P1", map[int]float64{10: 0})
+		assert.ErrorIs(t, err, ErrTierPercentOutOfRange)
+
+		_, err = productService.SetPriceTiers("P1", map[int]float64{10: 100})
+		assert.ErrorIs(t, err, ErrTierPercentOutOfRange)
+	})
+
+	t.Run("should return error when larger orders get smaller discounts", func(t *testing.T) {
+		result, err := productService.SetPriceTiers("P1", map[int]float64{10: 12, 100: 5})
+		assert.ErrorIs(t, err, ErrTierPercentMustIncrease)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(nil, product.ErrNotFound)
+
+		result, err := productService.SetPriceTiers("P1", map[int]float64{10: 5})
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should keep previous tiers when update fails", func(t *testing.T) {
+		previous := []entity.PriceTier{{MinQuantity: 20, Percent: 3}}
+		existing := &entity.Product{Code: code, PriceTiers: previous}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(&types.VersionConflictError{Aggregate: "product", Key: "P1", Expected: 0, Actual: 1})
+
+		_, err := productService.SetPriceTiers("P1", map[int]float64{10: 5})
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, previous, existing.PriceTiers)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := &entity.Product{Code: code}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil)
+
+		result, err := productService.SetPriceTiers("P1", map[int]float64{100: 12, 10: 5})
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.PriceTier{{MinQuantity: 10, Percent: 5}, {MinQuantity: 100, Percent: 12}}, result.PriceTiers)
+	})
+
+	t.Run("should clear tiers when none are given", func(t *testing.T) {
+		existing := &entity.Product{Code: code, PriceTiers: []entity.PriceTier{{MinQuantity: 10, Percent: 5}}}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil)
+
+		result, err := productService.SetPriceTiers("P1", nil)
+		assert.NoError(t, err)
+		assert.Empty(t, result.PriceTiers)
+	})
+}
+
+func TestProductServiceSetTaxClass(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("P1")
+	reduced, _ := valueobject.NewCode("REDUCED")
+
+	t.Run("should return error when product not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(nil, product.ErrNotFound)
+
+		result, err := productService.SetTaxClass("P1", "REDUCED")
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should keep previous class when update fails", func(t *testing.T) {
+		existing := &entity.Product{Code: code}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(&types.VersionConflictError{Aggregate: "product", Key: "P1", Expected: 0, Actual: 1})
+
+		_, err := productService.SetTaxClass("P1", "REDUCED")
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, "", existing.TaxClass.Value())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := &entity.Product{Code: code}
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil).Times(2)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil).Times(2)
+
+		result, err := productService.SetTaxClass("P1", "REDUCED")
+		assert.NoError(t, err)
+		assert.Equal(t, reduced, result.TaxClass)
+
+		result, err = productService.SetTaxClass("P1", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "", result.TaxClass.Value())
+	})
+}
+
+func TestProductServiceAdjustStock(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("P1")
+	warehouseCode, _ := valueobject.NewCode("W1")
+	newProduct := func() *entity.Product {
+		stock, _ := valueobject.NewStock(10)
+		held, _ := valueobject.NewStock(6)
+		return &entity.Product{
+			ID:            uuid.New(),
+			Code:          code,
+			Stock:         stock,
+			InititalStock: stock,
+			Inventory:     []entity.WarehouseStock{{Warehouse: warehouseCode, Stock: held}},
+		}
+	}
+
+	t.Run("should return error when amount is zero", func(t *testing.T) {
+		result, err := productService.AdjustStock("P1", 0)
+		assert.ErrorIs(t, err, ErrAdjustmentCannotBeZero)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when product not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(nil, product.ErrNotFound)
+
+		result, err := productService.AdjustStock("P1", 5)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when write-off exceeds unassigned stock", func(t *testing.T) {
+		existing := newProduct()
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{existing}).Times(2)
+
+		result, err := productService.AdjustStock("P1", -5)
+		assert.ErrorIs(t, err, ErrAdjustmentExceedsUnassignedStock)
+		assert.Nil(t, result)
+		assert.Equal(t, 10, existing.Stock.Value())
+	})
+
+	t.Run("should roll back when update fails", func(t *testing.T) {
+		existing := newProduct()
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{existing}).Times(2)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(&types.VersionConflictError{Aggregate: "product", Key: "P1", Expected: 0, Actual: 1})
+
+		_, err := productService.AdjustStock("P1", -3)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, 10, existing.Stock.Value())
+		assert.Equal(t, 10, existing.InititalStock.Value())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := newProduct()
+		mockProductRepo.EXPECT().Get(code).Return(existing, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{existing}).Times(2)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).DoAndReturn(func(movement *entity.StockMovement) error {
+			assert.Equal(t, existing.ID, movement.ProductID)
+			assert.Equal(t, valueobject.AdjustmentMovement, movement.Type.Value())
+			assert.Equal(t, -3, movement.Quantity)
+			return nil
+		})
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil)
+
+		result, err := productService.AdjustStock("P1", -3)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, result.Stock.Value())
+		assert.Equal(t, 7, result.InititalStock.Value())
+		assert.Equal(t, 1, result.Unassigned())
+	})
+}
+
+func TestProductServiceCreateBundle(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	newComponent := func(productCode string, stockCount int) *entity.Product {
+		code, _ := valueobject.NewCode(productCode)
+		stock, _ := valueobject.NewStock(stockCount)
+		return &entity.Product{ID: uuid.New(), Code: code, Stock: stock, InititalStock: stock}
+	}
+
+	t.Run("should return error when bundle has no components", func(t *testing.T) {
+		result, err := productService.CreateBundle("B1", 100, nil)
+		assert.ErrorIs(t, err, ErrBundleMustHaveComponents)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when component quantity is not positive", func(t *testing.T) {
+		result, err := productService.CreateBundle("B1", 100, map[string]int{"P1": 0})
+		assert.ErrorIs(t, err, ErrComponentQuantityMustBePositive)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when component not found", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(gomock.Any()).Return(nil, product.ErrNotFound)
+
+		result, err := productService.CreateBundle("B1", 100, map[string]int{"P1": 1})
+		assert.ErrorIs(t, err, product.ErrNotFound)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when component is a bundle", func(t *testing.T) {
+		component := newComponent("B0", 0)
+		component.Components = []entity.BundleComponent{{Product: component.Code, Quantity: 1}}
+		mockProductRepo.EXPECT().Get(component.Code).Return(component, nil)
+
+		result, err := productService.CreateBundle("B1", 100, map[string]int{"B0": 1})
+		assert.ErrorIs(t, err, ErrComponentMustHoldStock)
+		assert.Nil(t, result)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		p1, p2 := newComponent("P1", 10), newComponent("P2", 7)
+		mockProductRepo.EXPECT().Get(p1.Code).Return(p1, nil)
+		mockProductRepo.EXPECT().Get(p2.Code).Return(p2, nil)
+		mockProductRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		result, err := productService.CreateBundle("B1", 100, map[string]int{"P2": 2, "P1": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.BundleComponent{{Product: p1.Code, Quantity: 1}, {Product: p2.Code, Quantity: 2}}, result.Components)
+		assert.Equal(t, 3, result.Stock.Value())
+		assert.Equal(t, 3, result.InititalStock.Value())
+	})
+}
+
+func TestProductServiceBundleStock(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("B1")
+	componentCode, _ := valueobject.NewCode("P1")
+	stock, _ := valueobject.NewStock(5)
+	componentStock, _ := valueobject.NewStock(6)
+	bundle := &entity.Product{ID: uuid.New(), Code: code, Stock: stock, InititalStock: stock, Components: []entity.BundleComponent{{Product: componentCode, Quantity: 2}}}
+	component := &entity.Product{ID: uuid.New(), Code: componentCode, Stock: componentStock, InititalStock: componentStock}
+
+	t.Run("should sync the stock of the bundle when a component is adjusted", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(componentCode).Return(component, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{bundle, component}).Times(2)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(component, 0).Return(nil)
+		mockProductRepo.EXPECT().Update(bundle, 0).Return(nil)
+
+		_, err := productService.AdjustStock("P1", -2)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, component.Stock.Value())
+		assert.Equal(t, 2, bundle.Stock.Value())
+	})
+
+	t.Run("should not update a bundle that is in sync", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(componentCode).Return(component, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{bundle, component}).Times(2)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(component, 0).Return(nil)
+
+		_, err := productService.AdjustStock("P1", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, component.Stock.Value())
+		assert.Equal(t, 2, bundle.Stock.Value())
+	})
+
+	t.Run("should return error when stock of a bundle is adjusted", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(bundle, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{bundle, component}).Times(2)
+
+		result, err := productService.AdjustStock("B1", 1)
+		assert.ErrorIs(t, err, ErrStockIsDerived)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when a bundle allows backorders", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(bundle, nil)
+
+		result, err := productService.AllowBackorders("B1", 1, time.Time{})
+		assert.ErrorIs(t, err, ErrCannotBeBackordered)
+		assert.Nil(t, result)
+	})
+}
+
+func TestProductServiceCreateVariant(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	newParent := func(stockCount int) *entity.Product {
+		code, _ := valueobject.NewCode("T1")
+		price, _ := valueobject.NewPrice(20)
+		stock, _ := valueobject.NewStock(stockCount)
+		return &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock}
+	}
+
+	t.Run("should return error when variant has no attributes", func(t *testing.T) {
+		result, err := productService.CreateVariant("T1", "V1", 0, 10, nil)
+		assert.ErrorIs(t, err, ErrVariantMustHaveAttributes)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when attribute is empty", func(t *testing.T) {
+		result, err := productService.CreateVariant("T1", "V1", 0, 10, map[string]string{"size": ""})
+		assert.ErrorIs(t, err, ErrAttributeCannotBeEmpty)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when parent is a bundle", func(t *testing.T) {
+		parent := newParent(0)
+		parent.Components = []entity.BundleComponent{{Product: parent.Code, Quantity: 1}}
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{})
+
+		result, err := productService.CreateVariant("T1", "V1", 0, 10, map[string]string{"size": "S"})
+		assert.ErrorIs(t, err, ErrInvalidParent)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when parent is a bundle component", func(t *testing.T) {
+		parent := newParent(0)
+		bundle := &entity.Product{Components: []entity.BundleComponent{{Product: parent.Code, Quantity: 1}}}
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{bundle, parent})
+
+		result, err := productService.CreateVariant("T1", "V1", 0, 10, map[string]string{"size": "S"})
+		assert.ErrorIs(t, err, ErrParentIsBundleComponent)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when parent holds stock", func(t *testing.T) {
+		parent := newParent(5)
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{parent})
+
+		result, err := productService.CreateVariant("T1", "V1", 0, 10, map[string]string{"size": "S"})
+		assert.ErrorIs(t, err, ErrParentHoldsStock)
+		assert.Nil(t, result)
+	})
+
+	parent := newParent(0)
+	var variant *entity.Product
+
+	t.Run("should sell the variant at the price of its parent", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{parent})
+		mockProductRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(parent, 0).Return(nil)
+
+		result, err := productService.CreateVariant("T1", "V1", 0, 10, map[string]string{"size": "S", "colour": "red"})
+		assert.NoError(t, err)
+		assert.Equal(t, parent.Code, result.Parent)
+		assert.Equal(t, []entity.VariantAttribute{{Name: "colour", Value: "red"}, {Name: "size", Value: "S"}}, result.Attributes)
+		assert.Equal(t, 20.0, result.Price.Value())
+		assert.False(t, result.PriceOverride)
+		assert.Equal(t, []valueobject.Code{result.Code}, parent.Variants)
+		assert.Equal(t, 10, parent.Stock.Value())
+		assert.Equal(t, 10, parent.InititalStock.Value())
+		variant = result
+	})
+
+	t.Run("should return error when attributes are taken", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{parent, variant})
+		mockProductRepo.EXPECT().Get(variant.Code).Return(variant, nil)
+
+		result, err := productService.CreateVariant("T1", "V2", 0, 10, map[string]string{"colour": "red", "size": "S"})
+		assert.ErrorIs(t, err, ErrVariantAlreadyExists)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should keep a price override and the variants in code order", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(parent.Code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{parent, variant})
+		mockProductRepo.EXPECT().Get(variant.Code).Return(variant, nil)
+		mockProductRepo.EXPECT().Create(gomock.Any()).Return(nil)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(parent, 0).Return(nil)
+
+		result, err := productService.CreateVariant("T1", "V0", 25, 4, map[string]string{"size": "M"})
+		assert.NoError(t, err)
+		assert.Equal(t, 25.0, result.Price.Value())
+		assert.True(t, result.PriceOverride)
+		assert.Equal(t, []valueobject.Code{result.Code, variant.Code}, parent.Variants)
+		assert.Equal(t, 14, parent.Stock.Value())
+	})
+}
+
+func TestProductServiceVariantStock(t *testing.T) {
+	productService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("T1")
+	variantCode, _ := valueobject.NewCode("V1")
+	price, _ := valueobject.NewPrice(20)
+	stock, _ := valueobject.NewStock(8)
+	variantStock, _ := valueobject.NewStock(6)
+	parent := &entity.Product{ID: uuid.New(), Code: code, Price: price, InititalPrice: price, Stock: stock, InititalStock: stock, Variants: []valueobject.Code{variantCode}}
+	variant := &entity.Product{ID: uuid.New(), Code: variantCode, Price: price, InititalPrice: price, Stock: variantStock, InititalStock: stock, Parent: code}
+
+	t.Run("should sync the parent when a variant is adjusted", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(variantCode).Return(variant, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{parent, variant}).Times(2)
+		mockLedgerRepo.EXPECT().Append(gomock.Any()).Return(nil)
+		mockProductRepo.EXPECT().Update(variant, 0).Return(nil)
+		mockProductRepo.EXPECT().Update(parent, 0).Return(nil)
+
+		_, err := productService.AdjustStock("V1", -1)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, parent.Stock.Value())
+		assert.Equal(t, 7, parent.InititalStock.Value())
+	})
+
+	t.Run("should not update a parent when a variant is read", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(variantCode).Return(variant, nil)
+
+		result, err := productService.Get("V1")
+		assert.NoError(t, err)
+		assert.Same(t, variant, result)
+	})
+
+	t.Run("should return error when stock of a parent is adjusted", func(t *testing.T) {
+		mockProductRepo.EXPECT().Get(code).Return(parent, nil)
+		mockProductRepo.EXPECT().GetAll().Return([]*entity.Product{parent, variant}).Times(2)
+
+		result, err := productService.AdjustStock("T1", 1)
+		assert.ErrorIs(t, err, ErrStockIsDerived)
+		assert.Nil(t, result)
+	})
+}
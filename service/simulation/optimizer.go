@@ -0,0 +1,255 @@
+package simulation
+
+import (
+	"errors"
+	"time"
+
+	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	categoryRepo "github.com/aaydin-tr/e-commerce/domain/category/memory"
+	couponRepo "github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	ledgerRepo "github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
+	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	warehouseRepo "github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/service/campaign"
+	"github.com/aaydin-tr/e-commerce/service/order"
+	"github.com/aaydin-tr/e-commerce/service/product"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+)
+
+const (
+	Turnover    = "turnover"
+	Probability = "probability"
+)
+
+var (
+	ErrRunsMustBePositive   = errors.New("Runs must be positive")
+	ErrObjectiveMustBeOneOf = errors.New("Objective must be one of 'turnover', 'probability'")
+	ErrNoCandidate          = errors.New("No campaign settings fit the product stock")
+)
+
+// optimizedCampaignName names the campaign created in every sandbox run.
+const optimizedCampaignName = "optimize"
+
+// SearchSpace lists the campaign settings the optimizer tries. Every
+// combination of the values is evaluated.
+type SearchSpace struct {
+	Durations []int
+	Limits    []int
+	Targets   []int
+}
+
+// DefaultSearchSpace tries every duration up to maxDuration hours, limits from
+// 5 to 50 in steps of 5 and targets from 10% to 100% of stock.
+func DefaultSearchSpace(stock int, maxDuration int) SearchSpace {
+	space := SearchSpace{}
+	for duration := 1; duration <= maxDuration; duration++ {
+		space.Durations = append(space.Durations, duration)
+	}
+
+	for limit := 5; limit <= 50; limit += 5 {
+		space.Limits = append(space.Limits, limit)
+	}
+
+	for percent := 10; percent <= 100; percent += 10 {
+		target := stock * percent / 100
+		if target > 0 && (len(space.Targets) == 0 || space.Targets[len(space.Targets)-1] != target) {
+			space.Targets = append(space.Targets, target)
+		}
+	}
+
+	return space
+}
+
+// Candidate is a set of campaign settings with its simulated outcome averaged
+// over all runs.
+type Candidate struct {
+	Duration         int
+	Limit            int
+	TargetSalesCount int
+	Turnover         float64
+	HitProbability   float64
+}
+
+type Result struct {
+	Best      Candidate
+	Evaluated int
+}
+
+// Optimizer searches campaign settings for a product. Each candidate runs
+// against a fresh in-memory copy of the product, so live repositories are
+// never touched.
+type Optimizer struct {
+	model DemandModel
+	seed  int64
+	runs  int
+}
+
+func NewOptimizer(model DemandModel, seed int64, runs int) *Optimizer {
+	return &Optimizer{
+		model: model,
+		seed:  seed,
+		runs:  runs,
+	}
+}
+
+func (o *Optimizer) Optimize(liveProduct *entity.Product, space SearchSpace, objective string) (*Result, error) {
+	if objective != Turnover && objective != Probability {
+		return nil, ErrObjectiveMustBeOneOf
+	}
+
+	if o.runs <= 0 {
+		return nil, ErrRunsMustBePositive
+	}
+
+	err := o.model.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	liveProduct.Acquire()
+	snapshot := *liveProduct
+	liveProduct.Release()
+
+	var result *Result
+	for _, target := range space.Targets {
+		if target > snapshot.Stock.Value() {
+			continue
+		}
+
+		for _, duration := range space.Durations {
+			for _, limit := range space.Limits {
+				candidate, err := o.evaluate(snapshot, duration, limit, target)
+				if err != nil {
+					return nil, err
+				}
+
+				if result == nil {
+					result = &Result{Best: candidate}
+				} else if better(candidate, result.Best, objective) {
+					result.Best = candidate
+				}
+				result.Evaluated++
+			}
+		}
+	}
+
+	if result == nil {
+		return nil, ErrNoCandidate
+	}
+
+	return result, nil
+}
+
+func (o *Optimizer) evaluate(snapshot entity.Product, duration int, limit int, target int) (Candidate, error) {
+	candidate := Candidate{Duration: duration, Limit: limit, TargetSalesCount: target}
+
+	hits := 0
+	for run := 0; run < o.runs; run++ {
+		sandbox, err := newSandbox(snapshot)
+		if err != nil {
+			return candidate, err
+		}
+
+		err = sandbox.campaignService.Create(optimizedCampaignName, sandbox.product, duration, limit, target, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+		if err != nil {
+			return candidate, err
+		}
+
+		simulatedCampaign, err := sandbox.campaignService.Get(optimizedCampaignName)
+		if err != nil {
+			return candidate, err
+		}
+
+		simulator := NewSimulator(sandbox.productService, sandbox.orderService, o.model, o.seed+int64(run))
+		_, err = simulator.Run(duration, func() error {
+			return sandbox.scheduler.Tick(sandbox.clock.Advance(time.Hour))
+		})
+		if err != nil {
+			return candidate, err
+		}
+
+		candidate.Turnover += float64(simulatedCampaign.TotalSales.Value()) * simulatedCampaign.AverageItemPrice.Value()
+		if simulatedCampaign.TotalSales.Value() >= target {
+			hits++
+		}
+	}
+
+	candidate.Turnover /= float64(o.runs)
+	candidate.HitProbability = float64(hits) / float64(o.runs)
+	return candidate, nil
+}
+
+// better reports whether a beats b on the objective, using the other metric
+// to break ties. Earlier candidates win full ties.
+func better(a Candidate, b Candidate, objective string) bool {
+	if objective == Probability {
+		if a.HitProbability != b.HitProbability {
+			return a.HitProbability > b.HitProbability
+		}
+		return a.Turnover > b.Turnover
+	}
+
+	if a.Turnover != b.Turnover {
+		return a.Turnover > b.Turnover
+	}
+	return a.HitProbability > b.HitProbability
+}
+
+type sandbox struct {
+	clock           *clock.Clock
+	product         *entity.Product
+	productService  *product.ProductService
+	orderService    *order.OrderService
+	campaignService *campaign.CampaignService
+	scheduler       *campaign.Scheduler
+}
+
+// newSandbox builds isolated in-memory services holding a copy of snapshot
+// without its campaign, backorders or warehouses, on sale at its initial price.
+// Bundles, parents and variants are copied as plain products holding the stock
+// they had.
+func newSandbox(snapshot entity.Product) (*sandbox, error) {
+	unitOfWork := uow.New()
+	productRepository := productRepo.NewProductRepository(uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]()))
+	orderRepository := orderRepo.NewOrderRepository(uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]()))
+	campaignRepository := campaignRepo.NewCampaignRepository(uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]()))
+	warehouseRepository := warehouseRepo.NewWarehouseRepository(uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]()))
+	ledgerRepository := ledgerRepo.NewLedgerRepository(uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]()))
+	couponRepository := couponRepo.NewCouponRepository(uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]()))
+	categoryRepository := categoryRepo.NewCategoryRepository(uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]()))
+
+	clone := snapshot
+	clone.Campaign = nil
+	clone.Price = snapshot.InititalPrice
+	clone.BackorderLimit = valueobject.Stock{}
+	clone.Backordered = valueobject.Stock{}
+	clone.BackorderQueue = nil
+	clone.LaunchAt = time.Time{}
+	clone.Inventory = nil
+	clone.Components = nil
+	clone.Variants = nil
+	clone.Parent = valueobject.Code{}
+	clone.Attributes = nil
+	clone.PriceOverride = false
+	clone.Version = 0
+
+	err := productRepository.Create(&clone)
+	if err != nil {
+		return nil, err
+	}
+
+	sandboxClock := clock.New(time.Time{})
+	return &sandbox{
+		clock:           sandboxClock,
+		product:         &clone,
+		productService:  product.NewProductService(productRepository, ledgerRepository, unitOfWork, sandboxClock),
+		orderService:    order.NewOrderService(orderRepository, productRepository, campaignRepository, warehouseRepository, ledgerRepository, couponRepository, categoryRepository, unitOfWork, sandboxClock),
+		campaignService: campaign.NewCampaignService(campaignRepository, productRepository, unitOfWork, sandboxClock),
+		scheduler:       campaign.NewScheduler(campaignRepository, productRepository, sandboxClock.Now()),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProduct(price float64, stock int) *entity.Product {
+	code, _ := valueobject.NewCode("P1")
+	productPrice, _ := valueobject.NewPrice(price)
+	productStock, _ := valueobject.NewStock(stock)
+	return &entity.Product{Code: code, Price: productPrice, Stock: productStock, InititalPrice: productPrice, InititalStock: productStock}
+}
+
+func TestDefaultSearchSpace(t *testing.T) {
+	space := DefaultSearchSpace(15, 3)
+
+	assert.Equal(t, []int{1, 2, 3}, space.Durations)
+	assert.Equal(t, []int{5, 10, 15, 20, 25, 30, 35, 40, 45, 50}, space.Limits)
+	assert.Equal(t, []int{1, 3, 4, 6, 7, 9, 10, 12, 13, 15}, space.Targets)
+}
+
+func TestOptimizerOptimize(t *testing.T) {
+	space := SearchSpace{Durations: []int{2, 4}, Limits: []int{10, 30}, Targets: []int{5, 20}}
+
+	t.Run("should return error when objective is invalid", func(t *testing.T) {
+		result, err := NewOptimizer(DefaultDemandModel, 1, 1).Optimize(newProduct(100, 100), space, "invalid")
+		assert.ErrorIs(t, err, ErrObjectiveMustBeOneOf)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when runs is invalid", func(t *testing.T) {
+		result, err := NewOptimizer(DefaultDemandModel, 1, 0).Optimize(newProduct(100, 100), space, Turnover)
+		assert.ErrorIs(t, err, ErrRunsMustBePositive)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should return error when no target fits the stock", func(t *testing.T) {
+		result, err := NewOptimizer(DefaultDemandModel, 1, 1).Optimize(newProduct(100, 3), space, Turnover)
+		assert.ErrorIs(t, err, ErrNoCandidate)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should not mutate the live product", func(t *testing.T) {
+		live := newProduct(100, 100)
+		campaignName, _ := valueobject.NewName("C1")
+		liveCampaign := &entity.Campaign{Name: campaignName, Product: live}
+		live.Campaign = liveCampaign
+
+		result, err := NewOptimizer(DefaultDemandModel, 1, 3).Optimize(live, space, Turnover)
+		assert.NoError(t, err)
+		assert.Equal(t, 8, result.Evaluated)
+
+		assert.Equal(t, 100, live.Stock.Value())
+		assert.Equal(t, 100.0, live.Price.Value())
+		assert.Equal(t, 0, live.TotalDemandCount.Value())
+		assert.Same(t, liveCampaign, live.Campaign)
+		assert.Equal(t, 0, liveCampaign.TotalSales.Value())
+	})
+
+	t.Run("should pick the best candidate for the objective", func(t *testing.T) {
+		optimizer := NewOptimizer(DefaultDemandModel, 1, 5)
+		space := SearchSpace{Durations: []int{2, 4}, Limits: []int{10, 30}, Targets: []int{5, 90}}
+
+		byTurnover, err := optimizer.Optimize(newProduct(100, 100), space, Turnover)
+		assert.NoError(t, err)
+		byProbability, err := optimizer.Optimize(newProduct(100, 100), space, Probability)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 90, byTurnover.Best.TargetSalesCount)
+		assert.Equal(t, 5, byProbability.Best.TargetSalesCount)
+		assert.Equal(t, 1.0, byProbability.Best.HitProbability)
+		assert.GreaterOrEqual(t, byTurnover.Best.Turnover, byProbability.Best.Turnover)
+	})
+
+	t.Run("same seed produces the same result", func(t *testing.T) {
+		first, err := NewOptimizer(DefaultDemandModel, 7, 3).Optimize(newProduct(100, 100), space, Turnover)
+		assert.NoError(t, err)
+		second, err := NewOptimizer(DefaultDemandModel, 7, 3).Optimize(newProduct(100, 100), space, Turnover)
+		assert.NoError(t, err)
+
+		assert.Equal(t, first, second)
+	})
+}
@@ -0,0 +1,216 @@
+package simulation
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/service/order"
+	"github.com/aaydin-tr/e-commerce/service/product"
+)
+
+var (
+	ErrHoursMustBePositive         = errors.New("Hours must be positive")
+	ErrDemandModelMustBeFinite     = errors.New("Views, conversion rate and elasticity must be finite numbers")
+	ErrViewsCannotBeNegative       = errors.New("Views cannot be negative")
+	ErrViewsTooMany                = errors.New("Views cannot exceed 1000000 an hour")
+	ErrConversionRateMustBeBetween = errors.New("Conversion rate must be between 0 and 1")
+	ErrElasticityCannotBeNegative  = errors.New("Elasticity cannot be negative")
+	ErrMaxQuantityMustBePositive   = errors.New("Max quantity must be positive")
+)
+
+// DemandModel describes the synthetic traffic a product receives per hour.
+// The chance of a view turning into an order falls as the price rises above
+// the product's initial price, scaled by Elasticity.
+type DemandModel struct {
+	Views          float64
+	ConversionRate float64
+	Elasticity     float64
+	MaxQuantity    int
+}
+
+// MaxViews bounds the views a product receives per hour, each of which is
+// drawn on its own.
+const MaxViews = 1000000
+
+var DefaultDemandModel = DemandModel{
+	Views:          10,
+	ConversionRate: 0.2,
+	Elasticity:     2,
+	MaxQuantity:    3,
+}
+
+func (m DemandModel) Validate() error {
+	for _, value := range []float64{m.Views, m.ConversionRate, m.Elasticity} {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return ErrDemandModelMustBeFinite
+		}
+	}
+
+	if m.Views < 0 {
+		return ErrViewsCannotBeNegative
+	}
+
+	if m.Views > MaxViews {
+		return ErrViewsTooMany
+	}
+
+	if m.ConversionRate < 0 || m.ConversionRate > 1 {
+		return ErrConversionRateMustBeBetween
+	}
+
+	if m.Elasticity < 0 {
+		return ErrElasticityCannotBeNegative
+	}
+
+	if m.MaxQuantity <= 0 {
+		return ErrMaxQuantityMustBePositive
+	}
+
+	return nil
+}
+
+// ConversionProbability returns the chance that a single view of a product
+// sold at price results in an order.
+func (m DemandModel) ConversionProbability(price float64, initialPrice float64) float64 {
+	probability := m.ConversionRate * math.Pow(price/initialPrice, -m.Elasticity)
+	return math.Max(0, math.Min(1, probability))
+}
+
+type Report struct {
+	Hours  int
+	Views  int
+	Orders int
+	Units  int
+}
+
+type Simulator struct {
+	productService product.ProductServiceInterface
+	orderService   order.OrderServiceInterface
+	model          DemandModel
+	rand           *rand.Rand
+}
+
+func NewSimulator(productService product.ProductServiceInterface, orderService order.OrderServiceInterface, model DemandModel, seed int64) *Simulator {
+	return &Simulator{
+		productService: productService,
+		orderService:   orderService,
+		model:          model,
+		rand:           rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Run generates traffic for the given number of hours. After each simulated
+// hour advance is called to move the clock forward by one hour.
+func (s *Simulator) Run(hours int, advance func() error) (*Report, error) {
+	if hours <= 0 {
+		return nil, ErrHoursMustBePositive
+	}
+
+	err := s.model.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for hour := 0; hour < hours; hour++ {
+		products := s.productService.GetAll()
+		sort.Slice(products, func(i, j int) bool {
+			return products[i].Code.Value() < products[j].Code.Value()
+		})
+
+		for _, product := range products {
+			err := s.simulateProduct(product, report)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err := advance()
+		if err != nil {
+			return nil, err
+		}
+		report.Hours++
+	}
+
+	return report, nil
+}
+
+// simulateProduct generates an hour of traffic for product. A parent cannot be
+// ordered, so it gets no traffic of its own; its variants get theirs.
+func (s *Simulator) simulateProduct(product *entity.Product, report *Report) error {
+	product.Acquire()
+	parent := product.IsParent()
+	product.Release()
+	if parent {
+		return nil
+	}
+
+	views := s.poisson(s.model.Views)
+
+	product.Acquire()
+	err := product.IncreaseDemand(views)
+	probability := s.model.ConversionProbability(product.Price.Value(), product.InititalPrice.Value())
+	product.Release()
+	if err != nil {
+		return err
+	}
+	report.Views += views
+
+	for view := 0; view < views; view++ {
+		if s.rand.Float64() >= probability {
+			continue
+		}
+
+		product.Acquire()
+		stock := product.Stock.Value()
+		product.Release()
+		if stock == 0 {
+			return nil
+		}
+
+		quantity := 1 + s.rand.Intn(s.model.MaxQuantity)
+		if quantity > stock {
+			quantity = stock
+		}
+
+		_, err := s.orderService.Create(product, quantity)
+		if errors.Is(err, order.ErrInsufficientStock) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		report.Orders++
+		report.Units += quantity
+	}
+
+	return nil
+}
+
+// poissonNormalAbove is the mean above which poisson switches from Knuth's
+// method to a normal approximation. Knuth's method needs a uniform draw per
+// event and its limit underflows for a mean above about 745.
+const poissonNormalAbove = 30
+
+// poisson draws the number of events for the given mean, using Knuth's method
+// for small means and a normal approximation for larger ones.
+func (s *Simulator) poisson(mean float64) int {
+	if mean > poissonNormalAbove {
+		count := math.Round(mean + math.Sqrt(mean)*s.rand.NormFloat64())
+		return int(math.Max(0, count))
+	}
+
+	limit := math.Exp(-mean)
+	count := 0
+	p := s.rand.Float64()
+	for p > limit {
+		count++
+		p *= s.rand.Float64()
+	}
+
+	return count
+}
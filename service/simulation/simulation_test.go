@@ -0,0 +1,188 @@
+package simulation
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	categoryRepo "github.com/aaydin-tr/e-commerce/domain/category/memory"
+	couponRepo "github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	ledgerRepo "github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
+	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	warehouseRepo "github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/service/order"
+	"github.com/aaydin-tr/e-commerce/service/product"
+	"github.com/stretchr/testify/assert"
+)
+
+func setup(t *testing.T) (*product.ProductService, *order.OrderService) {
+	unitOfWork := uow.New()
+	productRepository := productRepo.NewProductRepository(uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]()))
+	orderRepository := orderRepo.NewOrderRepository(uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]()))
+	campaignRepository := campaignRepo.NewCampaignRepository(uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]()))
+	warehouseRepository := warehouseRepo.NewWarehouseRepository(uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]()))
+	ledgerRepository := ledgerRepo.NewLedgerRepository(uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]()))
+	couponRepository := couponRepo.NewCouponRepository(uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]()))
+	categoryRepository := categoryRepo.NewCategoryRepository(uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]()))
+	simulationClock := clock.New(time.Time{})
+
+	productService := product.NewProductService(productRepository, ledgerRepository, unitOfWork, simulationClock)
+	orderService := order.NewOrderService(orderRepository, productRepository, campaignRepository, warehouseRepository, ledgerRepository, couponRepository, categoryRepository, unitOfWork, simulationClock)
+	return productService, orderService
+}
+
+func TestDemandModelValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		model       DemandModel
+		expectedErr error
+	}{
+		{name: "default model", model: DefaultDemandModel},
+		{name: "negative views", model: DemandModel{Views: -1, ConversionRate: 0.1, MaxQuantity: 1}, expectedErr: ErrViewsCannotBeNegative},
+		{name: "conversion rate above one", model: DemandModel{Views: 1, ConversionRate: 2, MaxQuantity: 1}, expectedErr: ErrConversionRateMustBeBetween},
+		{name: "negative elasticity", model: DemandModel{Views: 1, ConversionRate: 0.1, Elasticity: -1, MaxQuantity: 1}, expectedErr: ErrElasticityCannotBeNegative},
+		{name: "zero max quantity", model: DemandModel{Views: 1, ConversionRate: 0.1}, expectedErr: ErrMaxQuantityMustBePositive},
+		{name: "too many views", model: DemandModel{Views: MaxViews + 1, ConversionRate: 0.1, MaxQuantity: 1}, expectedErr: ErrViewsTooMany},
+		{name: "most views", model: DemandModel{Views: MaxViews, ConversionRate: 0.1, MaxQuantity: 1}},
+		{name: "NaN views", model: DemandModel{Views: math.NaN(), ConversionRate: 0.1, MaxQuantity: 1}, expectedErr: ErrDemandModelMustBeFinite},
+		{name: "infinite views", model: DemandModel{Views: math.Inf(1), ConversionRate: 0.1, MaxQuantity: 1}, expectedErr: ErrDemandModelMustBeFinite},
+		{name: "NaN conversion rate", model: DemandModel{Views: 1, ConversionRate: math.NaN(), MaxQuantity: 1}, expectedErr: ErrDemandModelMustBeFinite},
+		{name: "infinite conversion rate", model: DemandModel{Views: 1, ConversionRate: math.Inf(-1), MaxQuantity: 1}, expectedErr: ErrDemandModelMustBeFinite},
+		{name: "NaN elasticity", model: DemandModel{Views: 1, ConversionRate: 0.1, Elasticity: math.NaN(), MaxQuantity: 1}, expectedErr: ErrDemandModelMustBeFinite},
+		{name: "infinite elasticity", model: DemandModel{Views: 1, ConversionRate: 0.1, Elasticity: math.Inf(1), MaxQuantity: 1}, expectedErr: ErrDemandModelMustBeFinite},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.ErrorIs(t, testCase.model.Validate(), testCase.expectedErr)
+		})
+	}
+}
+
+func TestDemandModelConversionProbability(t *testing.T) {
+	model := DemandModel{ConversionRate: 0.2, Elasticity: 2}
+
+	assert.InDelta(t, 0.2, model.ConversionProbability(100, 100), 1e-9)
+	assert.InDelta(t, 0.05, model.ConversionProbability(200, 100), 1e-9)
+	assert.Equal(t, 1.0, model.ConversionProbability(10, 100))
+}
+
+func TestSimulatorPoisson(t *testing.T) {
+	simulator := NewSimulator(nil, nil, DefaultDemandModel, 1)
+
+	for _, mean := range []float64{5, 1000, 100000} {
+		const draws = 2000
+		sum := 0.0
+		squares := 0.0
+		for i := 0; i < draws; i++ {
+			count := float64(simulator.poisson(mean))
+			assert.GreaterOrEqual(t, count, 0.0)
+			sum += count
+			squares += count * count
+		}
+
+		average := sum / draws
+		variance := squares/draws - average*average
+		assert.InDelta(t, mean, average, 4*math.Sqrt(mean/draws), "mean %v", mean)
+		assert.InEpsilon(t, mean, variance, 0.15, "mean %v", mean)
+	}
+}
+
+func TestSimulatorRun(t *testing.T) {
+	t.Run("should return error when hours is invalid", func(t *testing.T) {
+		productService, orderService := setup(t)
+		simulator := NewSimulator(productService, orderService, DefaultDemandModel, 1)
+
+		report, err := simulator.Run(0, func() error { return nil })
+		assert.ErrorIs(t, err, ErrHoursMustBePositive)
+		assert.Nil(t, report)
+	})
+
+	t.Run("should return error when advance fails", func(t *testing.T) {
+		productService, orderService := setup(t)
+		productService.Create("P1", 100, 100)
+		simulator := NewSimulator(productService, orderService, DefaultDemandModel, 1)
+
+		returnErr := errors.New("error")
+		report, err := simulator.Run(3, func() error { return returnErr })
+		assert.ErrorIs(t, err, returnErr)
+		assert.Nil(t, report)
+	})
+
+	t.Run("should not order when nobody converts", func(t *testing.T) {
+		productService, orderService := setup(t)
+		productService.Create("P1", 100, 100)
+		model := DemandModel{Views: 5, ConversionRate: 0, MaxQuantity: 1}
+		simulator := NewSimulator(productService, orderService, model, 1)
+
+		advanced := 0
+		report, err := simulator.Run(4, func() error {
+			advanced++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, advanced)
+		assert.Equal(t, 4, report.Hours)
+		assert.Equal(t, 0, report.Orders)
+
+		p, _ := productService.Get("P1")
+		assert.Equal(t, 100, p.Stock.Value())
+		assert.Equal(t, report.Views, p.TotalDemandCount.Value())
+	})
+
+	t.Run("should never sell more than stock", func(t *testing.T) {
+		productService, orderService := setup(t)
+		productService.Create("P1", 100, 5)
+		model := DemandModel{Views: 20, ConversionRate: 1, MaxQuantity: 3}
+		simulator := NewSimulator(productService, orderService, model, 1)
+
+		report, err := simulator.Run(2, func() error { return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.Units)
+
+		p, _ := productService.Get("P1")
+		assert.Equal(t, 0, p.Stock.Value())
+	})
+
+	t.Run("should order the variants of a parent", func(t *testing.T) {
+		productService, orderService := setup(t)
+		productService.Create("T1", 100, 0)
+		productService.CreateVariant("T1", "V1", 100, 5, map[string]string{"size": "S"})
+		productService.CreateVariant("T1", "V2", 100, 5, map[string]string{"size": "M"})
+		model := DemandModel{Views: 20, ConversionRate: 1, MaxQuantity: 3}
+		simulator := NewSimulator(productService, orderService, model, 1)
+
+		report, err := simulator.Run(2, func() error { return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, 10, report.Units)
+
+		parent, _ := productService.Get("T1")
+		assert.Equal(t, 0, parent.Stock.Value())
+		assert.Equal(t, 10, parent.TotalDemandCount.Value())
+	})
+
+	t.Run("same seed produces the same report", func(t *testing.T) {
+		run := func() *Report {
+			productService, orderService := setup(t)
+			productService.Create("P1", 100, 1000)
+			productService.Create("P2", 50, 1000)
+			simulator := NewSimulator(productService, orderService, DefaultDemandModel, 42)
+
+			report, err := simulator.Run(10, func() error { return nil })
+			assert.NoError(t, err)
+			return report
+		}
+
+		first := run()
+		second := run()
+		assert.Equal(t, first, second)
+		assert.Greater(t, first.Orders, 0)
+	})
+}
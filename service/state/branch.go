@@ -0,0 +1,152 @@
+package state
+
+import (
+	"errors"
+	"sort"
+)
+
+// MainBranch is the branch every session starts on.
+const MainBranch = "main"
+
+var (
+	ErrBranchNameCannotBeEmpty = errors.New("Branch name cannot be empty")
+	ErrBranchAlreadyExist      = errors.New("Branch already exist")
+	ErrBranchNotFound          = errors.New("Branch not found")
+	ErrCannotDiscardMainBranch = errors.New("Main branch cannot be discarded")
+)
+
+type BranchServiceInterface interface {
+	Current() string
+	Fork(name string, hours int) error
+	Switch(name string, hours int) (int, error)
+	Discard() (string, int, error)
+	Snapshots(hours int) ([]BranchSnapshot, error)
+}
+
+type BranchSnapshot struct {
+	Name     string
+	Snapshot *Snapshot
+}
+
+type branch struct {
+	parent   string
+	snapshot *Snapshot
+}
+
+// BranchService keeps named copies of the whole state. The live repositories
+// always hold the current branch; every other branch is kept as a snapshot, so
+// branches never share products, campaigns or orders.
+type BranchService struct {
+	stateService StateServiceInterface
+	current      string
+	branches     map[string]*branch
+}
+
+func NewBranchService(stateService StateServiceInterface) *BranchService {
+	return &BranchService{
+		stateService: stateService,
+		current:      MainBranch,
+		branches:     map[string]*branch{MainBranch: {}},
+	}
+}
+
+func (s *BranchService) Current() string {
+	return s.current
+}
+
+// Fork copies the current state into a new branch and switches to it.
+func (s *BranchService) Fork(name string, hours int) error {
+	if name == "" {
+		return ErrBranchNameCannotBeEmpty
+	}
+
+	if _, ok := s.branches[name]; ok {
+		return ErrBranchAlreadyExist
+	}
+
+	snapshot, err := s.stateService.Export(hours)
+	if err != nil {
+		return err
+	}
+
+	s.branches[s.current].snapshot = snapshot
+	s.branches[name] = &branch{parent: s.current}
+	s.current = name
+	return nil
+}
+
+// Switch stores the current state in its branch and loads the named branch,
+// returning the hours elapsed on its clock.
+func (s *BranchService) Switch(name string, hours int) (int, error) {
+	target, ok := s.branches[name]
+	if !ok {
+		return 0, ErrBranchNotFound
+	}
+
+	if name == s.current {
+		return hours, nil
+	}
+
+	snapshot, err := s.stateService.Export(hours)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.stateService.Import(target.snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	s.branches[s.current].snapshot = snapshot
+	s.current = name
+	return target.snapshot.Hours, nil
+}
+
+// Discard drops the current branch with all its changes and goes back to the
+// branch it was forked from, returning that branch and its clock.
+func (s *BranchService) Discard() (string, int, error) {
+	if s.current == MainBranch {
+		return "", 0, ErrCannotDiscardMainBranch
+	}
+
+	discarded := s.branches[s.current]
+	parent := s.branches[discarded.parent]
+	err := s.stateService.Import(parent.snapshot)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, item := range s.branches {
+		if item.parent == s.current {
+			item.parent = discarded.parent
+		}
+	}
+
+	delete(s.branches, s.current)
+	s.current = discarded.parent
+	return s.current, parent.snapshot.Hours, nil
+}
+
+// Snapshots returns the state of every branch ordered by name, capturing the
+// current branch with the given clock.
+func (s *BranchService) Snapshots(hours int) ([]BranchSnapshot, error) {
+	current, err := s.stateService.Export(hours)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]BranchSnapshot, 0, len(s.branches))
+	for name, item := range s.branches {
+		snapshot := item.snapshot
+		if name == s.current {
+			snapshot = current
+		}
+		result = append(result, BranchSnapshot{Name: name, Snapshot: snapshot})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
@@ -0,0 +1,127 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBranchServiceFork(t *testing.T) {
+	s := setup(t)
+	branches := NewBranchService(s.state)
+	assert.Equal(t, MainBranch, branches.Current())
+
+	err := branches.Fork("", 0)
+	assert.ErrorIs(t, err, ErrBranchNameCannotBeEmpty)
+
+	err = branches.Fork(MainBranch, 0)
+	assert.ErrorIs(t, err, ErrBranchAlreadyExist)
+
+	err = branches.Fork("what-if", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "what-if", branches.Current())
+
+	err = branches.Fork("what-if", 0)
+	assert.ErrorIs(t, err, ErrBranchAlreadyExist)
+}
+
+func TestBranchServiceSwitch(t *testing.T) {
+	s := setup(t)
+	s.product.Create("P1", 100, 100)
+	p1, _ := s.product.Get("P1")
+	s.campaign.Create("C1", p1, 5, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+
+	branches := NewBranchService(s.state)
+	_, err := branches.Switch("missing", 0)
+	assert.ErrorIs(t, err, ErrBranchNotFound)
+
+	hours, err := branches.Switch(MainBranch, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hours)
+
+	err = branches.Fork("what-if", 2)
+	assert.NoError(t, err)
+	s.order.Create(p1, 10)
+
+	hours, err = branches.Switch(MainBranch, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, hours)
+	assert.Equal(t, MainBranch, branches.Current())
+
+	mainProduct, _ := s.product.Get("P1")
+	assert.NotSame(t, p1, mainProduct)
+	assert.Equal(t, 100, mainProduct.Stock.Value())
+	assert.Len(t, s.orders.GetAll(), 0)
+
+	hours, err = branches.Switch("what-if", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, hours)
+
+	branchProduct, _ := s.product.Get("P1")
+	assert.NotSame(t, mainProduct, branchProduct)
+	assert.Equal(t, 90, branchProduct.Stock.Value())
+	assert.Same(t, branchProduct, branchProduct.Campaign.Product)
+	assert.Equal(t, 10, branchProduct.Campaign.TotalSales.Value())
+	assert.Len(t, s.orders.GetAll(), 1)
+}
+
+func TestBranchServiceDiscard(t *testing.T) {
+	s := setup(t)
+	s.product.Create("P1", 100, 100)
+	p1, _ := s.product.Get("P1")
+
+	branches := NewBranchService(s.state)
+	_, _, err := branches.Discard()
+	assert.ErrorIs(t, err, ErrCannotDiscardMainBranch)
+
+	branches.Fork("first", 1)
+	branches.Fork("second", 3)
+	s.order.Create(p1, 10)
+
+	branches.Switch("first", 4)
+	name, hours, err := branches.Discard()
+	assert.NoError(t, err)
+	assert.Equal(t, MainBranch, name)
+	assert.Equal(t, 1, hours)
+	assert.Equal(t, MainBranch, branches.Current())
+
+	// second was forked from the discarded branch and now falls back to main.
+	branches.Switch("second", 1)
+	product, _ := s.product.Get("P1")
+	assert.Equal(t, 90, product.Stock.Value())
+
+	name, hours, err = branches.Discard()
+	assert.NoError(t, err)
+	assert.Equal(t, MainBranch, name)
+	assert.Equal(t, 1, hours)
+
+	product, _ = s.product.Get("P1")
+	assert.Equal(t, 100, product.Stock.Value())
+
+	_, err = branches.Switch("first", 1)
+	assert.ErrorIs(t, err, ErrBranchNotFound)
+}
+
+func TestBranchServiceSnapshots(t *testing.T) {
+	s := setup(t)
+	s.product.Create("P1", 100, 100)
+	p1, _ := s.product.Get("P1")
+	s.campaign.Create("C1", p1, 5, 20, 50, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+
+	branches := NewBranchService(s.state)
+	branches.Fork("what-if", 0)
+	s.order.Create(p1, 10)
+
+	result, err := branches.Snapshots(3)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	assert.Equal(t, MainBranch, result[0].Name)
+	assert.Equal(t, 0, result[0].Snapshot.Hours)
+	assert.Equal(t, 0, result[0].Snapshot.Campaigns[0].TotalSales)
+
+	assert.Equal(t, "what-if", result[1].Name)
+	assert.Equal(t, 3, result[1].Snapshot.Hours)
+	assert.Equal(t, 10, result[1].Snapshot.Campaigns[0].TotalSales)
+}
@@ -0,0 +1,897 @@
+package state
+
+import (
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+// Snapshot is the serializable form of the whole simulation state. Products
+// and campaigns refer to each other by code and name instead of pointers, and
+// the clock is kept as the hours elapsed since the simulation started.
+type Snapshot struct {
+	Hours      int               `json:"hours"`
+	Warehouses []WarehouseRecord `json:"warehouses,omitempty"`
+	Categories []CategoryRecord  `json:"categories,omitempty"`
+	Products   []ProductRecord   `json:"products"`
+	Coupons    []CouponRecord    `json:"coupons,omitempty"`
+	Regions    []RegionRecord    `json:"regions,omitempty"`
+	Campaigns  []CampaignRecord  `json:"campaigns"`
+	Orders     []OrderRecord     `json:"orders"`
+	Movements  []MovementRecord  `json:"movements,omitempty"`
+}
+
+type WarehouseRecord struct {
+	ID      uuid.UUID `json:"id"`
+	Code    string    `json:"code"`
+	X       float64   `json:"x"`
+	Y       float64   `json:"y"`
+	Version int       `json:"version"`
+}
+
+// CategoryRecord refers to the parent category by code.
+type CategoryRecord struct {
+	ID      uuid.UUID `json:"id"`
+	Code    string    `json:"code"`
+	Parent  string    `json:"parent,omitempty"`
+	Version int       `json:"version"`
+}
+
+// CouponRecord keeps the expiry in Unix seconds, like OrderRecord, and zero
+// when the coupon never expires. Products and Categories refer to the
+// products and categories the coupon is restricted to by code.
+type CouponRecord struct {
+	ID           uuid.UUID `json:"id"`
+	Code         string    `json:"code"`
+	DiscountType string    `json:"discount_type"`
+	Amount       float64   `json:"amount"`
+	UsageLimit   int       `json:"usage_limit,omitempty"`
+	Uses         int       `json:"uses,omitempty"`
+	ExpiresAt    int64     `json:"expires_at,omitempty"`
+	Products     []string  `json:"products,omitempty"`
+	Categories   []string  `json:"categories,omitempty"`
+	Version      int       `json:"version"`
+}
+
+type ProductRecord struct {
+	ID               uuid.UUID   `json:"id"`
+	Code             string      `json:"code"`
+	Price            float64     `json:"price"`
+	Stock            int         `json:"stock"`
+	Campaign         string      `json:"campaign,omitempty"`
+	Version          int         `json:"version"`
+	InitialStock     int         `json:"initial_stock"`
+	InitialPrice     float64     `json:"initial_price"`
+	TotalDemandCount int         `json:"total_demand_count"`
+	BackorderLimit   int         `json:"backorder_limit,omitempty"`
+	Backordered      int         `json:"backordered,omitempty"`
+	BackorderQueue   []uuid.UUID `json:"backorder_queue,omitempty"`
+	// LaunchAt is in Unix seconds, like OrderRecord, and zero when the
+	// product is on sale from the start.
+	LaunchAt   int64             `json:"launch_at,omitempty"`
+	Inventory  []StockRecord     `json:"inventory,omitempty"`
+	Components []ComponentRecord `json:"components,omitempty"`
+	// Variants and Parent refer to products by code.
+	Variants      []string          `json:"variants,omitempty"`
+	Parent        string            `json:"parent,omitempty"`
+	Attributes    []AttributeRecord `json:"attributes,omitempty"`
+	PriceOverride bool              `json:"price_override,omitempty"`
+	Category      string            `json:"category,omitempty"`
+	PriceTiers    []TierRecord      `json:"price_tiers,omitempty"`
+	TaxClass      string            `json:"tax_class,omitempty"`
+}
+
+// ComponentRecord refers to the component product of a bundle by code.
+type ComponentRecord struct {
+	Product  string `json:"product"`
+	Quantity int    `json:"quantity"`
+}
+
+type AttributeRecord struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type TierRecord struct {
+	MinQuantity int     `json:"min_quantity"`
+	Percent     float64 `json:"percent"`
+}
+
+// StockRecord refers to the warehouse holding the stock by code.
+type StockRecord struct {
+	Warehouse string `json:"warehouse"`
+	Stock     int    `json:"stock"`
+}
+
+type CampaignRecord struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Product string    `json:"product,omitempty"`
+	// Category and Members describe a category campaign, whose members are
+	// referred to by code.
+	Category               string            `json:"category,omitempty"`
+	Members                []string          `json:"members,omitempty"`
+	Duration               int               `json:"duration"`
+	PriceManipulationLimit int               `json:"price_manipulation_limit"`
+	TargetSalesCount       int               `json:"target_sales_count"`
+	Status                 string            `json:"status"`
+	TotalSales             int               `json:"total_sales"`
+	AverageItemPrice       float64           `json:"average_item_price"`
+	Tax                    float64           `json:"tax,omitempty"`
+	StockPolicy            string            `json:"stock_policy,omitempty"`
+	BackorderPolicy        string            `json:"backorder_policy,omitempty"`
+	EndReason              string            `json:"end_reason,omitempty"`
+	EndedAt                int64             `json:"ended_at,omitempty"`
+	FinalPrice             float64           `json:"final_price,omitempty"`
+	Amendments             []AmendmentRecord `json:"amendments,omitempty"`
+	Version                int               `json:"version"`
+}
+
+// AmendmentRecord keeps the amendment time in Unix seconds, like OrderRecord.
+type AmendmentRecord struct {
+	Time                   int64 `json:"time"`
+	DurationExtension      int   `json:"duration_extension,omitempty"`
+	PriceManipulationLimit int   `json:"price_manipulation_limit,omitempty"`
+	TargetSalesCount       int   `json:"target_sales_count,omitempty"`
+}
+
+// OrderRecord keeps the creation time in Unix seconds, since the simulation
+// clock starts before the years JSON can encode.
+type OrderRecord struct {
+	ID          uuid.UUID          `json:"id"`
+	ProductID   uuid.UUID          `json:"product_id"`
+	Quantity    int                `json:"quantity"`
+	Price       float64            `json:"price"`
+	ListPrice   float64            `json:"list_price,omitempty"`
+	TierPercent float64            `json:"tier_percent,omitempty"`
+	Campaign    string             `json:"campaign,omitempty"`
+	Coupon      string             `json:"coupon,omitempty"`
+	Discount    float64            `json:"discount,omitempty"`
+	Region      string             `json:"region,omitempty"`
+	TaxRate     float64            `json:"tax_rate,omitempty"`
+	Tax         float64            `json:"tax,omitempty"`
+	Backordered int                `json:"backordered,omitempty"`
+	Status      string             `json:"status"`
+	CreatedAt   int64              `json:"created_at"`
+	Allocations []AllocationRecord `json:"allocations,omitempty"`
+	Version     int                `json:"version"`
+}
+
+// MovementRecord keeps the movement time in Unix seconds, like OrderRecord.
+// Movements are listed in ledger order.
+type MovementRecord struct {
+	ID        uuid.UUID `json:"id"`
+	ProductID uuid.UUID `json:"product_id"`
+	Type      string    `json:"type"`
+	Quantity  int       `json:"quantity"`
+	Reference uuid.UUID `json:"reference"`
+	Time      int64     `json:"time"`
+}
+
+type AllocationRecord struct {
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+	Product   string `json:"product,omitempty"`
+}
+
+func newWarehouseRecord(warehouse *entity.Warehouse) WarehouseRecord {
+	return WarehouseRecord{
+		ID:      warehouse.ID,
+		Code:    warehouse.Code.Value(),
+		X:       warehouse.Location.X(),
+		Y:       warehouse.Location.Y(),
+		Version: warehouse.Version,
+	}
+}
+
+// RegionRecord refers to the tax classes of its rates by code.
+type RegionRecord struct {
+	ID      uuid.UUID       `json:"id"`
+	Code    string          `json:"code"`
+	Rates   []TaxRateRecord `json:"rates,omitempty"`
+	Version int             `json:"version"`
+}
+
+type TaxRateRecord struct {
+	Class   string  `json:"class"`
+	Percent float64 `json:"percent"`
+}
+
+func newCategoryRecord(category *entity.Category) CategoryRecord {
+	return CategoryRecord{
+		ID:      category.ID,
+		Code:    category.Code.Value(),
+		Parent:  category.Parent.Value(),
+		Version: category.Version,
+	}
+}
+
+func newCouponRecord(coupon *entity.Coupon) CouponRecord {
+	record := CouponRecord{
+		ID:           coupon.ID,
+		Code:         coupon.Code.Value(),
+		DiscountType: coupon.Discount.Type(),
+		Amount:       coupon.Discount.Amount(),
+		UsageLimit:   coupon.UsageLimit,
+		Uses:         coupon.Uses,
+		Version:      coupon.Version,
+	}
+	if !coupon.ExpiresAt.IsZero() {
+		record.ExpiresAt = coupon.ExpiresAt.Unix()
+	}
+
+	for _, code := range coupon.Products {
+		record.Products = append(record.Products, code.Value())
+	}
+	for _, code := range coupon.Categories {
+		record.Categories = append(record.Categories, code.Value())
+	}
+
+	return record
+}
+
+func newRegionRecord(region *entity.Region) RegionRecord {
+	record := RegionRecord{
+		ID:      region.ID,
+		Code:    region.Code.Value(),
+		Version: region.Version,
+	}
+
+	for _, item := range region.Rates {
+		record.Rates = append(record.Rates, TaxRateRecord{Class: item.Class.Value(), Percent: item.Percent})
+	}
+
+	return record
+}
+
+func newProductRecord(product *entity.Product) ProductRecord {
+	record := ProductRecord{
+		ID:               product.ID,
+		Code:             product.Code.Value(),
+		Price:            product.Price.Value(),
+		Stock:            product.Stock.Value(),
+		Version:          product.Version,
+		InitialStock:     product.InititalStock.Value(),
+		InitialPrice:     product.InititalPrice.Value(),
+		TotalDemandCount: product.TotalDemandCount.Value(),
+		BackorderLimit:   product.BackorderLimit.Value(),
+		Backordered:      product.Backordered.Value(),
+		BackorderQueue:   product.BackorderQueue,
+		Parent:           product.Parent.Value(),
+		PriceOverride:    product.PriceOverride,
+		Category:         product.Category.Value(),
+		TaxClass:         product.TaxClass.Value(),
+	}
+
+	if product.Campaign != nil {
+		record.Campaign = product.Campaign.Name.Value()
+	}
+
+	if !product.LaunchAt.IsZero() {
+		record.LaunchAt = product.LaunchAt.Unix()
+	}
+
+	for _, item := range product.Inventory {
+		record.Inventory = append(record.Inventory, StockRecord{Warehouse: item.Warehouse.Value(), Stock: item.Stock.Value()})
+	}
+
+	for _, item := range product.Components {
+		record.Components = append(record.Components, ComponentRecord{Product: item.Product.Value(), Quantity: item.Quantity})
+	}
+
+	for _, item := range product.Variants {
+		record.Variants = append(record.Variants, item.Value())
+	}
+
+	for _, item := range product.Attributes {
+		record.Attributes = append(record.Attributes, AttributeRecord{Name: item.Name, Value: item.Value})
+	}
+
+	for _, item := range product.PriceTiers {
+		record.PriceTiers = append(record.PriceTiers, TierRecord{MinQuantity: item.MinQuantity, Percent: item.Percent})
+	}
+
+	return record
+}
+
+func newCampaignRecord(campaign *entity.Campaign) CampaignRecord {
+	record := CampaignRecord{
+		ID:                     campaign.ID,
+		Name:                   campaign.Name.Value(),
+		Duration:               campaign.Duration.Value(),
+		PriceManipulationLimit: campaign.PriceManipulationLimit.Value(),
+		TargetSalesCount:       campaign.TargetSalesCount.Value(),
+		Status:                 campaign.Status.Value(),
+		TotalSales:             campaign.TotalSales.Value(),
+		AverageItemPrice:       campaign.AverageItemPrice.Value(),
+		Tax:                    campaign.Tax,
+		StockPolicy:            campaign.StockPolicy.Value(),
+		BackorderPolicy:        campaign.BackorderPolicy.Value(),
+		Category:               campaign.Category.Value(),
+		Version:                campaign.Version,
+	}
+
+	if campaign.Product != nil {
+		record.Product = campaign.Product.Code.Value()
+	}
+
+	for _, member := range campaign.Members {
+		record.Members = append(record.Members, member.Code.Value())
+	}
+
+	if campaign.EndReason.Value() != "" {
+		record.EndReason = campaign.EndReason.Value()
+		record.EndedAt = campaign.EndedAt.Unix()
+		record.FinalPrice = campaign.FinalPrice.Value()
+	}
+
+	for _, amendment := range campaign.Amendments {
+		record.Amendments = append(record.Amendments, AmendmentRecord{
+			Time:                   amendment.Time.Unix(),
+			DurationExtension:      amendment.DurationExtension,
+			PriceManipulationLimit: amendment.PriceManipulationLimit,
+			TargetSalesCount:       amendment.TargetSalesCount,
+		})
+	}
+
+	return record
+}
+
+func newOrderRecord(order *entity.Order) OrderRecord {
+	record := OrderRecord{
+		ID:          order.ID,
+		ProductID:   order.ProductID,
+		Quantity:    order.Quantity.Value(),
+		Price:       order.Price.Value(),
+		ListPrice:   order.ListPrice.Value(),
+		TierPercent: order.TierPercent,
+		Campaign:    order.Campaign.Value(),
+		Coupon:      order.Coupon.Value(),
+		Discount:    order.Discount.Value(),
+		Region:      order.Region.Value(),
+		TaxRate:     order.TaxRate,
+		Tax:         order.Tax,
+		Backordered: order.Backordered.Value(),
+		Status:      order.Status.Value(),
+		CreatedAt:   order.CreatedAt.Unix(),
+		Version:     order.Version,
+	}
+
+	for _, item := range order.Allocations {
+		record.Allocations = append(record.Allocations, AllocationRecord{Warehouse: item.Warehouse.Value(), Quantity: item.Quantity, Product: item.Product.Value()})
+	}
+
+	return record
+}
+
+func newMovementRecord(movement *entity.StockMovement) MovementRecord {
+	return MovementRecord{
+		ID:        movement.ID,
+		ProductID: movement.ProductID,
+		Type:      movement.Type.Value(),
+		Quantity:  movement.Quantity,
+		Reference: movement.Reference,
+		Time:      movement.Time.Unix(),
+	}
+}
+
+func (r WarehouseRecord) toWarehouse() (*entity.Warehouse, error) {
+	code, err := valueobject.NewCode(r.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := valueobject.NewLocation(r.X, r.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Warehouse{
+		ID:       r.ID,
+		Code:     code,
+		Location: location,
+		Version:  r.Version,
+	}, nil
+}
+
+func (r CategoryRecord) toCategory() (*entity.Category, error) {
+	code, err := valueobject.NewCode(r.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent valueobject.Code
+	if r.Parent != "" {
+		parent, err = valueobject.NewCode(r.Parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &entity.Category{
+		ID:      r.ID,
+		Code:    code,
+		Parent:  parent,
+		Version: r.Version,
+	}, nil
+}
+
+func (r CouponRecord) toCoupon() (*entity.Coupon, error) {
+	code, err := valueobject.NewCode(r.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	discount, err := valueobject.NewDiscount(r.DiscountType, r.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt time.Time
+	if r.ExpiresAt != 0 {
+		expiresAt = time.Unix(r.ExpiresAt, 0).UTC()
+	}
+
+	var products []valueobject.Code
+	for _, value := range r.Products {
+		product, err := valueobject.NewCode(value)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	var categories []valueobject.Code
+	for _, value := range r.Categories {
+		category, err := valueobject.NewCode(value)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return &entity.Coupon{
+		ID:         r.ID,
+		Code:       code,
+		Discount:   discount,
+		UsageLimit: r.UsageLimit,
+		Uses:       r.Uses,
+		ExpiresAt:  expiresAt,
+		Products:   products,
+		Categories: categories,
+		Version:    r.Version,
+	}, nil
+}
+
+func (r RegionRecord) toRegion() (*entity.Region, error) {
+	code, err := valueobject.NewCode(r.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []entity.TaxRate
+	for _, item := range r.Rates {
+		class, err := valueobject.NewCode(item.Class)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, entity.TaxRate{Class: class, Percent: item.Percent})
+	}
+
+	return &entity.Region{
+		ID:      r.ID,
+		Code:    code,
+		Rates:   rates,
+		Version: r.Version,
+	}, nil
+}
+
+// toProduct rebuilds a product without its campaign link, which is restored
+// once all campaigns exist.
+func (r ProductRecord) toProduct() (*entity.Product, error) {
+	code, err := valueobject.NewCode(r.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	price, err := valueobject.NewPrice(r.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := valueobject.NewStock(r.Stock)
+	if err != nil {
+		return nil, err
+	}
+
+	initialStock, err := valueobject.NewStock(r.InitialStock)
+	if err != nil {
+		return nil, err
+	}
+
+	initialPrice, err := valueobject.NewPrice(r.InitialPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	totalDemandCount, err := valueobject.NewDemand(r.TotalDemandCount)
+	if err != nil {
+		return nil, err
+	}
+
+	backorderLimit, err := valueobject.NewStock(r.BackorderLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	backordered, err := valueobject.NewStock(r.Backordered)
+	if err != nil {
+		return nil, err
+	}
+
+	var launchAt time.Time
+	if r.LaunchAt != 0 {
+		launchAt = time.Unix(r.LaunchAt, 0).UTC()
+	}
+
+	var inventory []entity.WarehouseStock
+	for _, item := range r.Inventory {
+		warehouse, err := valueobject.NewCode(item.Warehouse)
+		if err != nil {
+			return nil, err
+		}
+
+		warehouseStock, err := valueobject.NewStock(item.Stock)
+		if err != nil {
+			return nil, err
+		}
+
+		inventory = append(inventory, entity.WarehouseStock{Warehouse: warehouse, Stock: warehouseStock})
+	}
+
+	var components []entity.BundleComponent
+	for _, item := range r.Components {
+		component, err := valueobject.NewCode(item.Product)
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, entity.BundleComponent{Product: component, Quantity: item.Quantity})
+	}
+
+	var variants []valueobject.Code
+	for _, item := range r.Variants {
+		variant, err := valueobject.NewCode(item)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, variant)
+	}
+
+	var parent valueobject.Code
+	if r.Parent != "" {
+		parent, err = valueobject.NewCode(r.Parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var attributes []entity.VariantAttribute
+	for _, item := range r.Attributes {
+		attributes = append(attributes, entity.VariantAttribute{Name: item.Name, Value: item.Value})
+	}
+
+	var category valueobject.Code
+	if r.Category != "" {
+		category, err = valueobject.NewCode(r.Category)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var taxClass valueobject.Code
+	if r.TaxClass != "" {
+		taxClass, err = valueobject.NewCode(r.TaxClass)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var priceTiers []entity.PriceTier
+	for _, item := range r.PriceTiers {
+		priceTiers = append(priceTiers, entity.PriceTier{MinQuantity: item.MinQuantity, Percent: item.Percent})
+	}
+
+	return &entity.Product{
+		ID:               r.ID,
+		Code:             code,
+		Price:            price,
+		Stock:            stock,
+		Version:          r.Version,
+		InititalStock:    initialStock,
+		InititalPrice:    initialPrice,
+		TotalDemandCount: totalDemandCount,
+		BackorderLimit:   backorderLimit,
+		Backordered:      backordered,
+		BackorderQueue:   r.BackorderQueue,
+		LaunchAt:         launchAt,
+		Inventory:        inventory,
+		Components:       components,
+		Variants:         variants,
+		Parent:           parent,
+		Attributes:       attributes,
+		PriceOverride:    r.PriceOverride,
+		Category:         category,
+		PriceTiers:       priceTiers,
+		TaxClass:         taxClass,
+	}, nil
+}
+
+// toCampaign rebuilds a campaign of product, or of the members of a category
+// campaign.
+func (r CampaignRecord) toCampaign(product *entity.Product, members []*entity.Product) (*entity.Campaign, error) {
+	name, err := valueobject.NewName(r.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := valueobject.NewDuration(r.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	priceManipulationLimit, err := valueobject.NewPriceManipulationLimit(r.PriceManipulationLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	targetSalesCount, err := valueobject.NewTargetSalesCount(r.TargetSalesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := valueobject.NewStatus(r.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	// A campaign without sales holds zero values, which the value object
+	// constructors reject.
+	var totalSales valueobject.Quantity
+	if r.TotalSales != 0 {
+		totalSales, err = valueobject.NewQuantity(r.TotalSales)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var averageItemPrice valueobject.Price
+	if r.AverageItemPrice != 0 {
+		averageItemPrice, err = valueobject.NewPrice(r.AverageItemPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Campaigns saved before stock policies existed hold none and end when
+	// their stock is exhausted.
+	var stockPolicy valueobject.StockPolicy
+	if r.StockPolicy != "" {
+		stockPolicy, err = valueobject.NewStockPolicy(r.StockPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Campaigns saved before backorders existed count them when ordered.
+	var backorderPolicy valueobject.BackorderPolicy
+	if r.BackorderPolicy != "" {
+		backorderPolicy, err = valueobject.NewBackorderPolicy(r.BackorderPolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Campaigns saved before endings were recorded hold no end reason.
+	var endReason valueobject.EndReason
+	var endedAt time.Time
+	var finalPrice valueobject.Price
+	if r.EndReason != "" {
+		endReason, err = valueobject.NewEndReason(r.EndReason)
+		if err != nil {
+			return nil, err
+		}
+
+		endedAt = time.Unix(r.EndedAt, 0).UTC()
+		if r.FinalPrice != 0 {
+			finalPrice, err = valueobject.NewPrice(r.FinalPrice)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var category valueobject.Code
+	if r.Category != "" {
+		category, err = valueobject.NewCode(r.Category)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var amendments []entity.CampaignAmendment
+	for _, amendment := range r.Amendments {
+		amendments = append(amendments, entity.CampaignAmendment{
+			Time:                   time.Unix(amendment.Time, 0).UTC(),
+			DurationExtension:      amendment.DurationExtension,
+			PriceManipulationLimit: amendment.PriceManipulationLimit,
+			TargetSalesCount:       amendment.TargetSalesCount,
+		})
+	}
+
+	return &entity.Campaign{
+		ID:                     r.ID,
+		Name:                   name,
+		Product:                product,
+		Category:               category,
+		Members:                members,
+		Duration:               duration,
+		PriceManipulationLimit: priceManipulationLimit,
+		TargetSalesCount:       targetSalesCount,
+		Status:                 status,
+		TotalSales:             totalSales,
+		AverageItemPrice:       averageItemPrice,
+		Tax:                    r.Tax,
+		StockPolicy:            stockPolicy,
+		BackorderPolicy:        backorderPolicy,
+		EndReason:              endReason,
+		EndedAt:                endedAt,
+		FinalPrice:             finalPrice,
+		Amendments:             amendments,
+		Version:                r.Version,
+	}, nil
+}
+
+func (r OrderRecord) toOrder() (*entity.Order, error) {
+	quantity, err := valueobject.NewQuantity(r.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshots saved before prices were recorded hold none.
+	var price valueobject.Price
+	if r.Price != 0 {
+		price, err = valueobject.NewPrice(r.Price)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var listPrice valueobject.Price
+	if r.TierPercent != 0 {
+		listPrice, err = valueobject.NewPrice(r.ListPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var campaign valueobject.Name
+	if r.Campaign != "" {
+		campaign, err = valueobject.NewName(r.Campaign)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var coupon valueobject.Code
+	var discount valueobject.Price
+	if r.Coupon != "" {
+		coupon, err = valueobject.NewCode(r.Coupon)
+		if err != nil {
+			return nil, err
+		}
+
+		discount, err = valueobject.NewPrice(r.Discount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var region valueobject.Code
+	if r.Region != "" {
+		region, err = valueobject.NewCode(r.Region)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Snapshots saved before orders had a status hold placed orders.
+	status := r.Status
+	if status == "" {
+		status = valueobject.Placed
+	}
+	orderStatus, err := valueobject.NewOrderStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	backordered, err := valueobject.NewStock(r.Backordered)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []entity.Allocation
+	for _, item := range r.Allocations {
+		warehouse, err := valueobject.NewCode(item.Warehouse)
+		if err != nil {
+			return nil, err
+		}
+
+		var product valueobject.Code
+		if item.Product != "" {
+			product, err = valueobject.NewCode(item.Product)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		allocations = append(allocations, entity.Allocation{Warehouse: warehouse, Quantity: item.Quantity, Product: product})
+	}
+
+	return &entity.Order{
+		ID:          r.ID,
+		ProductID:   r.ProductID,
+		Quantity:    quantity,
+		Price:       price,
+		ListPrice:   listPrice,
+		TierPercent: r.TierPercent,
+		Campaign:    campaign,
+		Coupon:      coupon,
+		Discount:    discount,
+		Region:      region,
+		TaxRate:     r.TaxRate,
+		Tax:         r.Tax,
+		Backordered: backordered,
+		Status:      orderStatus,
+		CreatedAt:   time.Unix(r.CreatedAt, 0).UTC(),
+		Allocations: allocations,
+		Version:     r.Version,
+	}, nil
+}
+
+func (r MovementRecord) toMovement() (*entity.StockMovement, error) {
+	movementType, err := valueobject.NewMovementType(r.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.StockMovement{
+		ID:        r.ID,
+		ProductID: r.ProductID,
+		Type:      movementType,
+		Quantity:  r.Quantity,
+		Reference: r.Reference,
+		Time:      time.Unix(r.Time, 0).UTC(),
+	}, nil
+}
+
+// openingMovement records the stock of a product saved before the ledger
+// existed as its initial stock, at the time the snapshot was taken.
+func openingMovement(product *entity.Product, at time.Time) (*entity.StockMovement, error) {
+	movementType, err := valueobject.NewMovementType(valueobject.InitialMovement)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.StockMovement{
+		ID:        uuid.New(),
+		ProductID: product.ID,
+		Type:      movementType,
+		Quantity:  product.Stock.Value(),
+		Reference: product.ID,
+		Time:      at,
+	}, nil
+}
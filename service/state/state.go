@@ -0,0 +1,418 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aaydin-tr/e-commerce/domain/campaign"
+	"github.com/aaydin-tr/e-commerce/domain/category"
+	"github.com/aaydin-tr/e-commerce/domain/coupon"
+	"github.com/aaydin-tr/e-commerce/domain/ledger"
+	"github.com/aaydin-tr/e-commerce/domain/order"
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownProduct   = errors.New("Snapshot refers to an unknown product")
+	ErrUnknownCampaign  = errors.New("Snapshot refers to an unknown campaign")
+	ErrUnknownWarehouse = errors.New("Snapshot refers to an unknown warehouse")
+	ErrUnknownCategory  = errors.New("Snapshot refers to an unknown category")
+	ErrUnknownCoupon    = errors.New("Snapshot refers to an unknown coupon")
+	ErrUnknownRegion    = errors.New("Snapshot refers to an unknown region")
+	ErrCategoryCycle    = errors.New("Snapshot has a cycle of category parents")
+)
+
+type StateServiceInterface interface {
+	Export(hours int) (*Snapshot, error)
+	Import(snapshot *Snapshot) error
+	Save(w io.Writer, hours int) error
+	Load(r io.Reader) (int, error)
+}
+
+type StateService struct {
+	productRepository   product.ProductRepository
+	orderRepository     order.OrderRepository
+	campaignRepository  campaign.CampaignRepository
+	warehouseRepository warehouse.WarehouseRepository
+	ledgerRepository    ledger.LedgerRepository
+	categoryRepository  category.CategoryRepository
+	couponRepository    coupon.CouponRepository
+	regionRepository    region.RegionRepository
+	unitOfWork          types.UnitOfWork
+}
+
+func NewStateService(productRepository product.ProductRepository, orderRepository order.OrderRepository, campaignRepository campaign.CampaignRepository, warehouseRepository warehouse.WarehouseRepository, ledgerRepository ledger.LedgerRepository, categoryRepository category.CategoryRepository, couponRepository coupon.CouponRepository, regionRepository region.RegionRepository, unitOfWork types.UnitOfWork) *StateService {
+	return &StateService{
+		productRepository:   productRepository,
+		orderRepository:     orderRepository,
+		campaignRepository:  campaignRepository,
+		warehouseRepository: warehouseRepository,
+		ledgerRepository:    ledgerRepository,
+		categoryRepository:  categoryRepository,
+		couponRepository:    couponRepository,
+		regionRepository:    regionRepository,
+		unitOfWork:          unitOfWork,
+	}
+}
+
+// Export captures every warehouse, category, product, coupon, region,
+// campaign, order and stock movement together with the hours elapsed on the
+// clock. It runs as a transaction so no order is half applied in the snapshot.
+func (s *StateService) Export(hours int) (*Snapshot, error) {
+	snapshot := &Snapshot{Hours: hours}
+
+	err := s.unitOfWork.Do(func() error {
+		for _, item := range s.warehouseRepository.GetAll() {
+			snapshot.Warehouses = append(snapshot.Warehouses, newWarehouseRecord(item))
+		}
+
+		for _, item := range s.categoryRepository.GetAll() {
+			snapshot.Categories = append(snapshot.Categories, newCategoryRecord(item))
+		}
+
+		for _, item := range s.productRepository.GetAll() {
+			item.Acquire()
+			snapshot.Products = append(snapshot.Products, newProductRecord(item))
+			item.Release()
+		}
+
+		for _, item := range s.couponRepository.GetAll() {
+			item.Acquire()
+			snapshot.Coupons = append(snapshot.Coupons, newCouponRecord(item))
+			item.Release()
+		}
+
+		for _, item := range s.regionRepository.GetAll() {
+			item.Acquire()
+			snapshot.Regions = append(snapshot.Regions, newRegionRecord(item))
+			item.Release()
+		}
+
+		for _, item := range s.campaignRepository.GetAll() {
+			item.Acquire()
+			snapshot.Campaigns = append(snapshot.Campaigns, newCampaignRecord(item))
+			item.Release()
+		}
+
+		for _, item := range s.orderRepository.GetAll() {
+			snapshot.Orders = append(snapshot.Orders, newOrderRecord(item))
+		}
+
+		for _, item := range s.ledgerRepository.GetAll() {
+			snapshot.Movements = append(snapshot.Movements, newMovementRecord(item))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(snapshot.Products, func(i, j int) bool {
+		return snapshot.Products[i].Code < snapshot.Products[j].Code
+	})
+	sort.Slice(snapshot.Campaigns, func(i, j int) bool {
+		return snapshot.Campaigns[i].Name < snapshot.Campaigns[j].Name
+	})
+	sort.Slice(snapshot.Orders, func(i, j int) bool {
+		return snapshot.Orders[i].ID.String() < snapshot.Orders[j].ID.String()
+	})
+
+	return snapshot, nil
+}
+
+// Import replaces the content of every repository with the snapshot. Nothing
+// is changed if the snapshot is invalid.
+func (s *StateService) Import(snapshot *Snapshot) error {
+	return s.unitOfWork.Do(func() error {
+		s.warehouseRepository.Clear()
+		s.categoryRepository.Clear()
+		s.productRepository.Clear()
+		s.couponRepository.Clear()
+		s.regionRepository.Clear()
+		s.campaignRepository.Clear()
+		s.orderRepository.Clear()
+		s.ledgerRepository.Clear()
+
+		warehouses := make(map[string]bool, len(snapshot.Warehouses))
+		for _, record := range snapshot.Warehouses {
+			item, err := record.toWarehouse()
+			if err != nil {
+				return err
+			}
+
+			err = s.warehouseRepository.Create(item)
+			if err != nil {
+				return err
+			}
+			warehouses[record.Code] = true
+		}
+
+		categories := make(map[string]bool, len(snapshot.Categories))
+		parents := make(map[string]string, len(snapshot.Categories))
+		for _, record := range snapshot.Categories {
+			item, err := record.toCategory()
+			if err != nil {
+				return err
+			}
+
+			err = s.categoryRepository.Create(item)
+			if err != nil {
+				return err
+			}
+			categories[record.Code] = true
+			parents[record.Code] = record.Parent
+		}
+
+		for _, record := range snapshot.Categories {
+			if record.Parent != "" && !categories[record.Parent] {
+				return ErrUnknownCategory
+			}
+
+			visited := map[string]bool{record.Code: true}
+			for code := record.Parent; code != ""; code = parents[code] {
+				if visited[code] {
+					return ErrCategoryCycle
+				}
+				visited[code] = true
+			}
+		}
+
+		products := make(map[string]*entity.Product, len(snapshot.Products))
+		for _, record := range snapshot.Products {
+			for _, stock := range record.Inventory {
+				if !warehouses[stock.Warehouse] {
+					return ErrUnknownWarehouse
+				}
+			}
+
+			if record.Category != "" && !categories[record.Category] {
+				return ErrUnknownCategory
+			}
+
+			item, err := record.toProduct()
+			if err != nil {
+				return err
+			}
+
+			err = s.productRepository.Create(item)
+			if err != nil {
+				return err
+			}
+			products[record.Code] = item
+		}
+
+		coupons := make(map[string]bool, len(snapshot.Coupons))
+		for _, record := range snapshot.Coupons {
+			for _, code := range record.Products {
+				if _, ok := products[code]; !ok {
+					return ErrUnknownProduct
+				}
+			}
+
+			for _, code := range record.Categories {
+				if !categories[code] {
+					return ErrUnknownCategory
+				}
+			}
+
+			item, err := record.toCoupon()
+			if err != nil {
+				return err
+			}
+
+			err = s.couponRepository.Create(item)
+			if err != nil {
+				return err
+			}
+			coupons[record.Code] = true
+		}
+
+		regions := make(map[string]bool, len(snapshot.Regions))
+		for _, record := range snapshot.Regions {
+			item, err := record.toRegion()
+			if err != nil {
+				return err
+			}
+
+			err = s.regionRepository.Create(item)
+			if err != nil {
+				return err
+			}
+			regions[record.Code] = true
+		}
+
+		campaigns := make(map[string]*entity.Campaign, len(snapshot.Campaigns))
+		for _, record := range snapshot.Campaigns {
+			var campaignProduct *entity.Product
+			if record.Product != "" {
+				var ok bool
+				campaignProduct, ok = products[record.Product]
+				if !ok {
+					return ErrUnknownProduct
+				}
+			}
+
+			if record.Category != "" && !categories[record.Category] {
+				return ErrUnknownCategory
+			}
+
+			var members []*entity.Product
+			for _, code := range record.Members {
+				member, ok := products[code]
+				if !ok {
+					return ErrUnknownProduct
+				}
+				members = append(members, member)
+			}
+
+			item, err := record.toCampaign(campaignProduct, members)
+			if err != nil {
+				return err
+			}
+
+			err = s.campaignRepository.Create(item)
+			if err != nil {
+				return err
+			}
+			campaigns[record.Name] = item
+		}
+
+		for _, record := range snapshot.Products {
+			for _, component := range record.Components {
+				if _, ok := products[component.Product]; !ok {
+					return ErrUnknownProduct
+				}
+			}
+
+			for _, variant := range record.Variants {
+				if _, ok := products[variant]; !ok {
+					return ErrUnknownProduct
+				}
+			}
+
+			if _, ok := products[record.Parent]; record.Parent != "" && !ok {
+				return ErrUnknownProduct
+			}
+		}
+
+		for _, record := range snapshot.Products {
+			if record.Campaign == "" {
+				continue
+			}
+
+			productCampaign, ok := campaigns[record.Campaign]
+			if !ok {
+				return ErrUnknownCampaign
+			}
+			products[record.Code].Campaign = productCampaign
+		}
+
+		for _, record := range snapshot.Orders {
+			if record.Coupon != "" && !coupons[record.Coupon] {
+				return ErrUnknownCoupon
+			}
+
+			if record.Region != "" && !regions[record.Region] {
+				return ErrUnknownRegion
+			}
+
+			item, err := record.toOrder()
+			if err != nil {
+				return err
+			}
+
+			err = s.orderRepository.Create(item)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.importMovements(snapshot, products)
+	})
+}
+
+// importMovements appends the stock movements of the snapshot to the ledger.
+// Snapshots saved before the ledger existed hold none, so every product holding
+// stock of its own gets an initial movement for the stock it holds instead.
+func (s *StateService) importMovements(snapshot *Snapshot, products map[string]*entity.Product) error {
+	if len(snapshot.Movements) == 0 {
+		at := clock.Start.Add(time.Duration(snapshot.Hours) * time.Hour)
+		for _, record := range snapshot.Products {
+			item := products[record.Code]
+			if item.Stock.Value() == 0 || !item.HoldsStock() {
+				continue
+			}
+
+			movement, err := openingMovement(item, at)
+			if err != nil {
+				return err
+			}
+
+			err = s.ledgerRepository.Append(movement)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	known := make(map[uuid.UUID]bool, len(products))
+	for _, item := range products {
+		known[item.ID] = true
+	}
+
+	for _, record := range snapshot.Movements {
+		if !known[record.ProductID] {
+			return ErrUnknownProduct
+		}
+
+		movement, err := record.toMovement()
+		if err != nil {
+			return err
+		}
+
+		err = s.ledgerRepository.Append(movement)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *StateService) Save(w io.Writer, hours int) error {
+	snapshot, err := s.Export(hours)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+func (s *StateService) Load(r io.Reader) (int, error) {
+	snapshot := &Snapshot{}
+	err := json.NewDecoder(r).Decode(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.Import(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	return snapshot.Hours, nil
+}
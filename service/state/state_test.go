@@ -0,0 +1,422 @@
+package state
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	campaignRepo "github.com/aaydin-tr/e-commerce/domain/campaign/memory"
+	categoryRepo "github.com/aaydin-tr/e-commerce/domain/category/memory"
+	couponRepo "github.com/aaydin-tr/e-commerce/domain/coupon/memory"
+	ledgerRepo "github.com/aaydin-tr/e-commerce/domain/ledger/memory"
+	orderRepo "github.com/aaydin-tr/e-commerce/domain/order/memory"
+	productRepo "github.com/aaydin-tr/e-commerce/domain/product/memory"
+	regionRepo "github.com/aaydin-tr/e-commerce/domain/region/memory"
+	warehouseRepo "github.com/aaydin-tr/e-commerce/domain/warehouse/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/clock"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/service/campaign"
+	"github.com/aaydin-tr/e-commerce/service/category"
+	"github.com/aaydin-tr/e-commerce/service/coupon"
+	"github.com/aaydin-tr/e-commerce/service/order"
+	"github.com/aaydin-tr/e-commerce/service/product"
+	"github.com/aaydin-tr/e-commerce/service/tax"
+	"github.com/aaydin-tr/e-commerce/service/warehouse"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// orderTime is the clock value orders are created at in tests.
+var orderTime = time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC)
+
+type services struct {
+	state     *StateService
+	product   *product.ProductService
+	order     *order.OrderService
+	campaign  *campaign.CampaignService
+	warehouse *warehouse.WarehouseService
+	category  *category.CategoryService
+	coupon    *coupon.CouponService
+	tax       *tax.TaxService
+	orders    *orderRepo.OrderRepository
+	movements *ledgerRepo.LedgerRepository
+}
+
+func setup(t *testing.T) services {
+	unitOfWork := uow.New()
+	productRepository := productRepo.NewProductRepository(uow.NewStorage[*entity.Product](unitOfWork, storage.New[*entity.Product]()))
+	orderRepository := orderRepo.NewOrderRepository(uow.NewStorage[*entity.Order](unitOfWork, storage.New[*entity.Order]()))
+	campaignRepository := campaignRepo.NewCampaignRepository(uow.NewStorage[*entity.Campaign](unitOfWork, storage.New[*entity.Campaign]()))
+	warehouseRepository := warehouseRepo.NewWarehouseRepository(uow.NewStorage[*entity.Warehouse](unitOfWork, storage.New[*entity.Warehouse]()))
+	ledgerRepository := ledgerRepo.NewLedgerRepository(uow.NewStorage[*entity.StockMovement](unitOfWork, storage.New[*entity.StockMovement]()))
+	categoryRepository := categoryRepo.NewCategoryRepository(uow.NewStorage[*entity.Category](unitOfWork, storage.New[*entity.Category]()))
+	couponRepository := couponRepo.NewCouponRepository(uow.NewStorage[*entity.Coupon](unitOfWork, storage.New[*entity.Coupon]()))
+	regionRepository := regionRepo.NewRegionRepository(uow.NewStorage[*entity.Region](unitOfWork, storage.New[*entity.Region]()))
+
+	return services{
+		state:     NewStateService(productRepository, orderRepository, campaignRepository, warehouseRepository, ledgerRepository, categoryRepository, couponRepository, regionRepository, unitOfWork),
+		product:   product.NewProductService(productRepository, ledgerRepository, unitOfWork, clock.New(orderTime)),
+		order:     order.NewOrderService(orderRepository, productRepository, campaignRepository, warehouseRepository, ledgerRepository, couponRepository, categoryRepository, unitOfWork, clock.New(orderTime)),
+		campaign:  campaign.NewCampaignService(campaignRepository, productRepository, unitOfWork, clock.New(orderTime)),
+		warehouse: warehouse.NewWarehouseService(warehouseRepository, productRepository, unitOfWork),
+		category:  category.NewCategoryService(categoryRepository, productRepository, unitOfWork),
+		coupon:    coupon.NewCouponService(couponRepository, productRepository, categoryRepository, clock.New(orderTime)),
+		tax:       tax.NewTaxService(regionRepository, unitOfWork),
+		orders:    orderRepository,
+		movements: ledgerRepository,
+	}
+}
+
+func TestStateServiceSaveLoad(t *testing.T) {
+	source := setup(t)
+	source.product.Create("P1", 100, 100)
+	source.product.Create("P2", 50, 10)
+	source.warehouse.Create("W1", 3, 4)
+	source.warehouse.AssignStock("P1", "W1", 30)
+	p1, _ := source.product.Get("P1")
+	source.campaign.Create("C1", p1, 5, 20, 50, valueobject.PauseOnStockOut, valueobject.CountOnFulfillment)
+	source.order.Create(p1, 10)
+	source.product.CreateBundle("B1", 120, map[string]int{"P1": 2})
+	source.product.Create("T1", 20, 0)
+	source.product.CreateVariant("T1", "V1", 25, 6, map[string]string{"size": "S"})
+	source.category.Create("K1", "")
+	source.category.Create("K2", "K1")
+	source.category.Assign("V1", "K2")
+	k1, _ := source.category.Get("K1")
+	members, _ := source.category.Members("K1")
+	source.campaign.CreateForCategory("S1", k1, members, 4, 10, 5, valueobject.EndOnStockOut, valueobject.CountOnOrder)
+	save10, _ := source.coupon.Create("SAVE10", valueobject.FixedDiscount, 10, 3, 24, []string{"P1"}, []string{"K1"})
+	eu, _ := source.tax.SetRate("EU", entity.StandardTaxClass, 20)
+	source.tax.SetRate("EU", "REDUCED", 7)
+	source.product.SetTaxClass("T1", "REDUCED")
+	couponOrder, _ := source.order.CreateWithStrategy(p1, 1, valueobject.SplitAcrossWarehouses, 0, 0, save10, eu)
+	p2, _ := source.product.AllowBackorders("P2", 5, orderTime.Add(2*time.Hour))
+	source.product.SetPriceTiers("P2", map[int]float64{10: 5})
+	preOrder, _ := source.order.Create(p2, 12)
+	source.campaign.Amend("C1", 2, 0, 45)
+	p1.Discount(p1.Campaign.PriceManipulationLimit)
+
+	var buffer bytes.Buffer
+	err := source.state.Save(&buffer, 7)
+	assert.NoError(t, err)
+
+	target := setup(t)
+	target.product.Create("P3", 10, 10)
+
+	hours, err := target.state.Load(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, hours)
+
+	_, err = target.product.Get("P3")
+	assert.Error(t, err)
+
+	loaded, err := target.product.Get("P1")
+	assert.NoError(t, err)
+	assert.Equal(t, p1.ID, loaded.ID)
+	assert.Equal(t, p1.Price, loaded.Price)
+	assert.Equal(t, 89, loaded.Stock.Value())
+	assert.Equal(t, p1.InititalStock, loaded.InititalStock)
+	assert.Equal(t, p1.InititalPrice, loaded.InititalPrice)
+	assert.Equal(t, p1.TotalDemandCount, loaded.TotalDemandCount)
+	assert.Equal(t, p1.Version, loaded.Version)
+	assert.Equal(t, p1.Inventory, loaded.Inventory)
+	assert.Equal(t, 19, loaded.Inventory[0].Stock.Value())
+
+	loadedWarehouse, err := target.warehouse.Get("W1")
+	assert.NoError(t, err)
+	assert.Equal(t, 3.0, loadedWarehouse.Location.X())
+	assert.Equal(t, 4.0, loadedWarehouse.Location.Y())
+
+	loadedCampaign, err := target.campaign.Get("C1")
+	assert.NoError(t, err)
+	assert.Same(t, loadedCampaign, loaded.Campaign)
+	assert.Same(t, loaded, loadedCampaign.Product)
+	assert.Equal(t, 11, loadedCampaign.TotalSales.Value())
+	assert.InDelta(t, 1090.0/11, loadedCampaign.AverageItemPrice.Value(), 0.001)
+	assert.Equal(t, 18.0, loadedCampaign.Tax)
+	assert.Equal(t, valueobject.Active, loadedCampaign.Status.Value())
+	assert.Equal(t, 7, loadedCampaign.Duration.Value())
+	assert.Equal(t, valueobject.PauseOnStockOut, loadedCampaign.StockPolicy.Value())
+	assert.Equal(t, valueobject.CountOnFulfillment, loadedCampaign.BackorderPolicy.Value())
+	assert.Equal(t, []entity.CampaignAmendment{{Time: orderTime, DurationExtension: 2, TargetSalesCount: 45}}, loadedCampaign.Amendments)
+
+	loadedBundle, err := target.product.Get("B1")
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.BundleComponent{{Product: loaded.Code, Quantity: 2}}, loadedBundle.Components)
+	assert.Equal(t, 44, loadedBundle.Stock.Value())
+
+	loadedVariant, err := target.product.Get("V1")
+	assert.NoError(t, err)
+	assert.Equal(t, "T1", loadedVariant.Parent.Value())
+	assert.Equal(t, []entity.VariantAttribute{{Name: "size", Value: "S"}}, loadedVariant.Attributes)
+	assert.True(t, loadedVariant.PriceOverride)
+	assert.Equal(t, 25.0, loadedVariant.Price.Value())
+	assert.Equal(t, "K2", loadedVariant.Category.Value())
+
+	loadedCategory, err := target.category.Get("K2")
+	assert.NoError(t, err)
+	assert.Equal(t, "K1", loadedCategory.Parent.Value())
+
+	loadedCategoryCampaign, err := target.campaign.Get("S1")
+	assert.NoError(t, err)
+	assert.Nil(t, loadedCategoryCampaign.Product)
+	assert.Equal(t, "K1", loadedCategoryCampaign.Category.Value())
+	assert.Equal(t, []*entity.Product{loadedVariant}, loadedCategoryCampaign.Members)
+	assert.Same(t, loadedCategoryCampaign, loadedVariant.Campaign)
+
+	loadedParent, err := target.product.Get("T1")
+	assert.NoError(t, err)
+	assert.Equal(t, []valueobject.Code{loadedVariant.Code}, loadedParent.Variants)
+	assert.Equal(t, 6, loadedParent.Stock.Value())
+	assert.Equal(t, "REDUCED", loadedParent.TaxClass.Value())
+
+	loadedP2, err := target.product.Get("P2")
+	assert.NoError(t, err)
+	assert.Nil(t, loadedP2.Campaign)
+	assert.Equal(t, 5, loadedP2.BackorderLimit.Value())
+	assert.Equal(t, 12, loadedP2.Backordered.Value())
+	assert.Equal(t, []uuid.UUID{preOrder.ID}, loadedP2.BackorderQueue)
+	assert.Equal(t, orderTime.Add(2*time.Hour), loadedP2.LaunchAt)
+	assert.Equal(t, []entity.PriceTier{{MinQuantity: 10, Percent: 5}}, loadedP2.PriceTiers)
+
+	loadedPreOrder, err := target.order.Get(preOrder.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, valueobject.PreOrdered, loadedPreOrder.Status.Value())
+	assert.Equal(t, 12, loadedPreOrder.Backordered.Value())
+	assert.Equal(t, 47.5, loadedPreOrder.Price.Value())
+	assert.Equal(t, 50.0, loadedPreOrder.ListPrice.Value())
+	assert.Equal(t, 5.0, loadedPreOrder.TierPercent)
+
+	loadedCoupon, err := target.coupon.Get("SAVE10")
+	assert.NoError(t, err)
+	assert.Equal(t, save10.ID, loadedCoupon.ID)
+	assert.Equal(t, save10.Discount, loadedCoupon.Discount)
+	assert.Equal(t, 3, loadedCoupon.UsageLimit)
+	assert.Equal(t, 1, loadedCoupon.Uses)
+	assert.Equal(t, orderTime.Add(24*time.Hour), loadedCoupon.ExpiresAt)
+	assert.Equal(t, []valueobject.Code{loaded.Code}, loadedCoupon.Products)
+	assert.Equal(t, []valueobject.Code{loadedCategory.Parent}, loadedCoupon.Categories)
+
+	loadedCouponOrder, err := target.order.Get(couponOrder.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "SAVE10", loadedCouponOrder.Coupon.Value())
+	assert.Equal(t, 10.0, loadedCouponOrder.Discount.Value())
+	assert.Equal(t, 90.0, loadedCouponOrder.Total())
+	assert.Equal(t, "EU", loadedCouponOrder.Region.Value())
+	assert.Equal(t, 20.0, loadedCouponOrder.TaxRate)
+	assert.Equal(t, 108.0, loadedCouponOrder.Gross())
+
+	loadedRegion, err := target.tax.Get("EU")
+	assert.NoError(t, err)
+	assert.Equal(t, eu.ID, loadedRegion.ID)
+	assert.Equal(t, eu.Rates, loadedRegion.Rates)
+
+	orders := target.orders.ListByProduct(p1.ID)
+	assert.Len(t, orders, 2)
+	if orders[0].ID == couponOrder.ID {
+		orders = orders[1:]
+	}
+	assert.Equal(t, p1.ID, orders[0].ProductID)
+	assert.Equal(t, 10, orders[0].Quantity.Value())
+	assert.Equal(t, 100.0, orders[0].Price.Value())
+	assert.Equal(t, orderTime, orders[0].CreatedAt)
+	assert.Equal(t, "C1", orders[0].Campaign.Value())
+	assert.Equal(t, valueobject.Placed, orders[0].Status.Value())
+	assert.Equal(t, []entity.Allocation{{Warehouse: loadedWarehouse.Code, Quantity: 10}}, orders[0].Allocations)
+
+	assert.Equal(t, "", loadedCampaign.EndReason.Value())
+
+	sourceMovements, targetMovements := source.movements.GetAll(), target.movements.GetAll()
+	assert.Len(t, targetMovements, len(sourceMovements))
+	for i := range sourceMovements {
+		assert.Equal(t, sourceMovements[i].ID, targetMovements[i].ID)
+		assert.Equal(t, sourceMovements[i].Time, targetMovements[i].Time)
+	}
+	movements := target.movements.ListByProduct(p1.ID)
+	assert.Len(t, movements, 3)
+	assert.Equal(t, valueobject.InitialMovement, movements[0].Type.Value())
+	assert.Equal(t, valueobject.SaleMovement, movements[1].Type.Value())
+	assert.Equal(t, -10, movements[1].Quantity)
+	assert.Equal(t, orders[0].ID, movements[1].Reference)
+
+	_, err = target.order.Create(loaded, 40)
+	assert.NoError(t, err)
+	assert.Equal(t, valueobject.Ended, loadedCampaign.Status.Value())
+	assert.Equal(t, valueobject.Active, p1.Campaign.Status.Value())
+}
+
+func TestStateServiceImport(t *testing.T) {
+	t.Run("should keep state when snapshot refers to unknown product", func(t *testing.T) {
+		s := setup(t)
+		s.product.Create("P1", 100, 100)
+
+		err := s.state.Import(&Snapshot{
+			Products:  []ProductRecord{{Code: "P2", Price: 10, InitialPrice: 10}},
+			Campaigns: []CampaignRecord{{Name: "C1", Product: "P3", Duration: 1, PriceManipulationLimit: 1, TargetSalesCount: 1, Status: valueobject.Active}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownProduct)
+
+		_, err = s.product.Get("P1")
+		assert.NoError(t, err)
+		_, err = s.product.Get("P2")
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when snapshot refers to unknown campaign", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products: []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10, Campaign: "C1"}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownCampaign)
+		assert.Len(t, s.product.GetAll(), 0)
+	})
+
+	t.Run("should return error when snapshot refers to unknown warehouse", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Warehouses: []WarehouseRecord{{Code: "W1"}},
+			Products:   []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10, Stock: 5, Inventory: []StockRecord{{Warehouse: "W2", Stock: 5}}}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownWarehouse)
+		assert.Len(t, s.warehouse.GetAll(), 0)
+	})
+
+	t.Run("should return error when a bundle refers to unknown product", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products: []ProductRecord{{Code: "B1", Price: 10, InitialPrice: 10, Components: []ComponentRecord{{Product: "P1", Quantity: 1}}}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownProduct)
+		assert.Len(t, s.product.GetAll(), 0)
+	})
+
+	t.Run("should return error when a variant refers to unknown parent", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products: []ProductRecord{{Code: "V1", Price: 10, InitialPrice: 10, Stock: 5, Parent: "T1", Attributes: []AttributeRecord{{Name: "size", Value: "S"}}}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownProduct)
+		assert.Len(t, s.product.GetAll(), 0)
+	})
+
+	t.Run("should return error when a product refers to unknown category", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Categories: []CategoryRecord{{Code: "K1"}},
+			Products:   []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10, Category: "K2"}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownCategory)
+	})
+
+	t.Run("should return error when categories are their own ancestor", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Categories: []CategoryRecord{{Code: "K1", Parent: "K3"}, {Code: "K2", Parent: "K1"}, {Code: "K3", Parent: "K2"}},
+		})
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+
+		err = s.state.Import(&Snapshot{
+			Categories: []CategoryRecord{{Code: "K1", Parent: "K1"}},
+		})
+		assert.ErrorIs(t, err, ErrCategoryCycle)
+	})
+
+	t.Run("should return error when a category campaign refers to unknown member", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Categories: []CategoryRecord{{Code: "K1"}},
+			Products:   []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10, Category: "K1"}},
+			Campaigns:  []CampaignRecord{{Name: "S1", Category: "K1", Members: []string{"P1", "P2"}, Duration: 1, PriceManipulationLimit: 1, TargetSalesCount: 1, Status: valueobject.Active}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownProduct)
+	})
+
+	t.Run("should return error when a coupon refers to unknown category", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Coupons: []CouponRecord{{Code: "SAVE10", DiscountType: valueobject.FixedDiscount, Amount: 10, Categories: []string{"K1"}}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownCategory)
+	})
+
+	t.Run("should return error when an order refers to unknown coupon", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products: []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10}},
+			Orders:   []OrderRecord{{ID: uuid.New(), Quantity: 1, Price: 10, Coupon: "SAVE10", Discount: 1}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownCoupon)
+		assert.Len(t, s.orders.GetAll(), 0)
+	})
+
+	t.Run("should return error when an order refers to unknown region", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products: []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10}},
+			Orders:   []OrderRecord{{ID: uuid.New(), Quantity: 1, Price: 10, Region: "EU", TaxRate: 20, Tax: 2}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownRegion)
+		assert.Len(t, s.orders.GetAll(), 0)
+	})
+
+	t.Run("should return error when a movement refers to unknown product", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products:  []ProductRecord{{Code: "P1", Price: 10, InitialPrice: 10, Stock: 5}},
+			Movements: []MovementRecord{{ID: uuid.New(), ProductID: uuid.New(), Type: valueobject.InitialMovement, Quantity: 5}},
+		})
+		assert.ErrorIs(t, err, ErrUnknownProduct)
+		assert.Len(t, s.movements.GetAll(), 0)
+	})
+
+	t.Run("should open the ledger of snapshots without movements", func(t *testing.T) {
+		s := setup(t)
+		id := uuid.New()
+
+		err := s.state.Import(&Snapshot{
+			Hours: 3,
+			Products: []ProductRecord{
+				{ID: id, Code: "P1", Price: 10, InitialPrice: 10, Stock: 5, InitialStock: 8},
+				{ID: uuid.New(), Code: "P2", Price: 10, InitialPrice: 10},
+			},
+		})
+		assert.NoError(t, err)
+
+		movements := s.movements.GetAll()
+		assert.Len(t, movements, 1)
+		assert.Equal(t, id, movements[0].ProductID)
+		assert.Equal(t, valueobject.InitialMovement, movements[0].Type.Value())
+		assert.Equal(t, 5, movements[0].Quantity)
+		assert.Equal(t, clock.Start.Add(3*time.Hour), movements[0].Time)
+	})
+
+	t.Run("should return error when a value is invalid", func(t *testing.T) {
+		s := setup(t)
+
+		err := s.state.Import(&Snapshot{
+			Products: []ProductRecord{{Code: "P1", Price: -1, InitialPrice: 10}},
+		})
+		assert.ErrorIs(t, err, valueobject.ErrPriceMustBePositive)
+	})
+}
+
+func TestStateServiceLoadInvalidJSON(t *testing.T) {
+	s := setup(t)
+
+	_, err := s.state.Load(bytes.NewBufferString("{"))
+	assert.Error(t, err)
+}
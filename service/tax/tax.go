@@ -0,0 +1,91 @@
+package tax
+
+import (
+	"errors"
+	"math"
+
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var ErrTaxRateOutOfRange = errors.New("Tax rate must be between 0 and 100 percent")
+
+type TaxServiceInterface interface {
+	SetRate(regionCode string, taxClass string, rate float64) (*entity.Region, error)
+	Get(regionCode string) (*entity.Region, error)
+}
+
+type TaxService struct {
+	regionRepository region.RegionRepository
+	unitOfWork       types.UnitOfWork
+}
+
+func NewTaxService(regionRepository region.RegionRepository, unitOfWork types.UnitOfWork) *TaxService {
+	return &TaxService{
+		regionRepository: regionRepository,
+		unitOfWork:       unitOfWork,
+	}
+}
+
+// SetRate sets the rate of the tax class in the region to rate percent. The
+// region is created with its first rate.
+func (s *TaxService) SetRate(regionCode string, taxClass string, rate float64) (*entity.Region, error) {
+	code, err := valueobject.NewCode(regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	class, err := valueobject.NewCode(taxClass)
+	if err != nil {
+		return nil, err
+	}
+
+	if rate < 0 || rate > 100 || math.IsNaN(rate) {
+		return nil, ErrTaxRateOutOfRange
+	}
+
+	result, err := s.regionRepository.Get(code)
+	if errors.Is(err, region.ErrNotFound) {
+		newRegion := &entity.Region{ID: uuid.New(), Code: code}
+		newRegion.SetRate(class, rate)
+
+		err = s.regionRepository.Create(newRegion)
+		if err != nil {
+			return nil, err
+		}
+
+		return newRegion, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	err = s.unitOfWork.Do(func() error {
+		version := result.Version
+		uow.Track(s.unitOfWork, result)
+
+		result.SetRate(class, rate)
+		return s.regionRepository.Update(result, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *TaxService) Get(regionCode string) (*entity.Region, error) {
+	code, err := valueobject.NewCode(regionCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.regionRepository.Get(code)
+}
@@ -0,0 +1,132 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/region"
+	regionRepo "github.com/aaydin-tr/e-commerce/domain/region/memory"
+	"github.com/aaydin-tr/e-commerce/entity"
+	mockRegion "github.com/aaydin-tr/e-commerce/mock/repository/region"
+	"github.com/aaydin-tr/e-commerce/pkg/storage"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var mockRegionRepo *mockRegion.MockRegionRepository
+
+func setup(t *testing.T) (*TaxService, func()) {
+	ct := gomock.NewController(t)
+
+	mockRegionRepo = mockRegion.NewMockRegionRepository(ct)
+
+	taxService := NewTaxService(mockRegionRepo, uow.New())
+
+	return taxService, func() {
+		ct.Finish()
+		mockRegionRepo = nil
+	}
+}
+
+func TestNewTaxService(t *testing.T) {
+	ct := gomock.NewController(t)
+
+	mockRegionRepo = mockRegion.NewMockRegionRepository(ct)
+	unitOfWork := uow.New()
+	taxService := NewTaxService(mockRegionRepo, unitOfWork)
+
+	assert.Equal(t, taxService.regionRepository, mockRegionRepo)
+	assert.Equal(t, taxService.unitOfWork, unitOfWork)
+
+	ct.Finish()
+}
+
+func TestTaxServiceSetRate(t *testing.T) {
+	taxService, teardown := setup(t)
+	defer teardown()
+
+	code, _ := valueobject.NewCode("EU")
+	standard, _ := valueobject.NewCode(entity.StandardTaxClass)
+	reduced, _ := valueobject.NewCode("REDUCED")
+
+	t.Run("should return error when region code is invalid", func(t *testing.T) {
+		_, err := taxService.SetRate("", entity.StandardTaxClass, 20)
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when tax class is invalid", func(t *testing.T) {
+		_, err := taxService.SetRate("EU", "", 20)
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when rate is out of range", func(t *testing.T) {
+		_, err := taxService.SetRate("EU", entity.StandardTaxClass, -1)
+		assert.ErrorIs(t, err, ErrTaxRateOutOfRange)
+
+		_, err = taxService.SetRate("EU", entity.StandardTaxClass, 101)
+		assert.ErrorIs(t, err, ErrTaxRateOutOfRange)
+	})
+
+	t.Run("should create the region with its first rate", func(t *testing.T) {
+		mockRegionRepo.EXPECT().Get(code).Return(nil, region.ErrNotFound)
+		mockRegionRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		result, err := taxService.SetRate("EU", entity.StandardTaxClass, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, code, result.Code)
+		assert.Equal(t, []entity.TaxRate{{Class: standard, Percent: 20}}, result.Rates)
+	})
+
+	t.Run("should add and replace rates ordered by class", func(t *testing.T) {
+		existing := &entity.Region{ID: uuid.New(), Code: code, Rates: []entity.TaxRate{{Class: standard, Percent: 20}}}
+		mockRegionRepo.EXPECT().Get(code).Return(existing, nil).Times(2)
+		mockRegionRepo.EXPECT().Update(existing, 0).Return(nil).Times(2)
+
+		_, err := taxService.SetRate("EU", "REDUCED", 7)
+		assert.NoError(t, err)
+		result, err := taxService.SetRate("EU", entity.StandardTaxClass, 19)
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.TaxRate{{Class: reduced, Percent: 7}, {Class: standard, Percent: 19}}, result.Rates)
+	})
+
+	t.Run("should keep previous rates when update fails", func(t *testing.T) {
+		previous := []entity.TaxRate{{Class: standard, Percent: 20}}
+		existing := &entity.Region{ID: uuid.New(), Code: code, Rates: previous}
+		mockRegionRepo.EXPECT().Get(code).Return(existing, nil)
+		mockRegionRepo.EXPECT().Update(existing, 0).Return(&types.VersionConflictError{Aggregate: "region", Key: "EU", Expected: 0, Actual: 1})
+
+		_, err := taxService.SetRate("EU", entity.StandardTaxClass, 19)
+		assert.ErrorIs(t, err, types.ErrVersionConflict)
+		assert.Equal(t, previous, existing.Rates)
+	})
+
+	t.Run("should bump the version of the region", func(t *testing.T) {
+		unitOfWork := uow.New()
+		taxService := NewTaxService(regionRepo.NewRegionRepository(uow.NewStorage[*entity.Region](unitOfWork, storage.New[*entity.Region]())), unitOfWork)
+
+		_, err := taxService.SetRate("EU", entity.StandardTaxClass, 20)
+		assert.NoError(t, err)
+		result, err := taxService.SetRate("EU", "REDUCED", 7)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Version)
+	})
+}
+
+func TestTaxServiceGet(t *testing.T) {
+	taxService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when region code is invalid", func(t *testing.T) {
+		_, err := taxService.Get("")
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when region is not found", func(t *testing.T) {
+		mockRegionRepo.EXPECT().Get(gomock.Any()).Return(nil, region.ErrNotFound)
+		_, err := taxService.Get("EU")
+		assert.ErrorIs(t, err, region.ErrNotFound)
+	})
+}
@@ -0,0 +1,122 @@
+package warehouse
+
+import (
+	"errors"
+
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/entity"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/types"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAmountMustBePositive     = errors.New("Amount must be positive")
+	ErrCannotBeStocked          = errors.New("Bundles and parent products are stocked through the products they are made of")
+	ErrNotEnoughUnassignedStock = errors.New("Not enough unassigned stock")
+)
+
+type WarehouseServiceInterface interface {
+	Create(warehouseCode string, x float64, y float64) error
+	Get(warehouseCode string) (*entity.Warehouse, error)
+	GetAll() []*entity.Warehouse
+	AssignStock(productCode string, warehouseCode string, amount int) (*entity.Product, error)
+}
+
+type WarehouseService struct {
+	warehouseRepository warehouse.WarehouseRepository
+	productRepository   product.ProductRepository
+	unitOfWork          types.UnitOfWork
+}
+
+func NewWarehouseService(warehouseRepository warehouse.WarehouseRepository, productRepository product.ProductRepository, unitOfWork types.UnitOfWork) *WarehouseService {
+	return &WarehouseService{
+		warehouseRepository: warehouseRepository,
+		productRepository:   productRepository,
+		unitOfWork:          unitOfWork,
+	}
+}
+
+func (s *WarehouseService) Create(warehouseCode string, x float64, y float64) error {
+	code, err := valueobject.NewCode(warehouseCode)
+	if err != nil {
+		return err
+	}
+
+	location, err := valueobject.NewLocation(x, y)
+	if err != nil {
+		return err
+	}
+
+	return s.warehouseRepository.Create(&entity.Warehouse{
+		ID:       uuid.New(),
+		Code:     code,
+		Location: location,
+	})
+}
+
+func (s *WarehouseService) Get(warehouseCode string) (*entity.Warehouse, error) {
+	code, err := valueobject.NewCode(warehouseCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.warehouseRepository.Get(code)
+}
+
+func (s *WarehouseService) GetAll() []*entity.Warehouse {
+	return s.warehouseRepository.GetAll()
+}
+
+// AssignStock places amount units of the product's unassigned stock in the
+// warehouse. Stock a product is created or restocked with is unassigned until
+// then, and orders take it after every warehouse is empty.
+func (s *WarehouseService) AssignStock(productCode string, warehouseCode string, amount int) (*entity.Product, error) {
+	if amount <= 0 {
+		return nil, ErrAmountMustBePositive
+	}
+
+	stored, err := s.Get(warehouseCode)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := valueobject.NewCode(productCode)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.productRepository.Get(code)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Acquire()
+	defer result.Release()
+
+	if !result.HoldsStock() {
+		return nil, ErrCannotBeStocked
+	}
+
+	if result.Unassigned() < amount {
+		return nil, ErrNotEnoughUnassignedStock
+	}
+
+	err = s.unitOfWork.Do(func() error {
+		uow.Track(s.unitOfWork, result)
+		version := result.Version
+		err := result.AssignStock(stored.Code, amount)
+		if err != nil {
+			return err
+		}
+
+		return s.productRepository.Update(result, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,180 @@
+package warehouse
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aaydin-tr/e-commerce/domain/product"
+	"github.com/aaydin-tr/e-commerce/domain/warehouse"
+	"github.com/aaydin-tr/e-commerce/entity"
+	mockProduct "github.com/aaydin-tr/e-commerce/mock/repository/product"
+	mockWarehouse "github.com/aaydin-tr/e-commerce/mock/repository/warehouse"
+	"github.com/aaydin-tr/e-commerce/pkg/uow"
+	"github.com/aaydin-tr/e-commerce/valueobject"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	mockWarehouseRepo *mockWarehouse.MockWarehouseRepository
+	mockProductRepo   *mockProduct.MockProductRepository
+)
+
+func setup(t *testing.T) (*WarehouseService, func()) {
+	ct := gomock.NewController(t)
+
+	mockWarehouseRepo = mockWarehouse.NewMockWarehouseRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+
+	warehouseService := NewWarehouseService(mockWarehouseRepo, mockProductRepo, uow.New())
+
+	return warehouseService, func() {
+		ct.Finish()
+		mockWarehouseRepo = nil
+		mockProductRepo = nil
+	}
+}
+
+func TestNewWarehouseService(t *testing.T) {
+	ct := gomock.NewController(t)
+
+	mockWarehouseRepo = mockWarehouse.NewMockWarehouseRepository(ct)
+	mockProductRepo = mockProduct.NewMockProductRepository(ct)
+
+	unitOfWork := uow.New()
+	warehouseService := NewWarehouseService(mockWarehouseRepo, mockProductRepo, unitOfWork)
+
+	assert.Equal(t, warehouseService.warehouseRepository, mockWarehouseRepo)
+	assert.Equal(t, warehouseService.productRepository, mockProductRepo)
+	assert.Equal(t, warehouseService.unitOfWork, unitOfWork)
+
+	ct.Finish()
+}
+
+func TestWarehouseServiceCreate(t *testing.T) {
+	warehouseService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when warehouse code is invalid", func(t *testing.T) {
+		err := warehouseService.Create("", 1, 2)
+		assert.ErrorIs(t, err, valueobject.ErrCodeIsRequired)
+	})
+
+	t.Run("should return error when warehouse already exist", func(t *testing.T) {
+		mockWarehouseRepo.EXPECT().Create(gomock.Any()).Return(warehouse.ErrAlreadyExist)
+		err := warehouseService.Create("W1", 1, 2)
+		assert.ErrorIs(t, err, warehouse.ErrAlreadyExist)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockWarehouseRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(created *entity.Warehouse) error {
+			assert.Equal(t, "W1", created.Code.Value())
+			assert.Equal(t, 1.0, created.Location.X())
+			assert.Equal(t, 2.0, created.Location.Y())
+			return nil
+		})
+		err := warehouseService.Create("W1", 1, 2)
+		assert.NoError(t, err)
+	})
+}
+
+func TestWarehouseServiceGet(t *testing.T) {
+	warehouseService, teardown := setup(t)
+	defer teardown()
+
+	t.Run("should return error when warehouse is not found", func(t *testing.T) {
+		mockWarehouseRepo.EXPECT().Get(gomock.Any()).Return(nil, warehouse.ErrNotFound)
+		_, err := warehouseService.Get("W1")
+		assert.ErrorIs(t, err, warehouse.ErrNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		code, _ := valueobject.NewCode("W1")
+		stored := &entity.Warehouse{Code: code}
+		mockWarehouseRepo.EXPECT().Get(code).Return(stored, nil)
+		result, err := warehouseService.Get("W1")
+		assert.NoError(t, err)
+		assert.Same(t, stored, result)
+	})
+}
+
+func TestWarehouseServiceAssignStock(t *testing.T) {
+	warehouseService, teardown := setup(t)
+	defer teardown()
+
+	warehouseCode, _ := valueobject.NewCode("W1")
+	stored := &entity.Warehouse{Code: warehouseCode}
+	productCode, _ := valueobject.NewCode("P1")
+	newProduct := func() *entity.Product {
+		stock, _ := valueobject.NewStock(50)
+		return &entity.Product{ID: uuid.New(), Code: productCode, Stock: stock}
+	}
+
+	t.Run("should return error when amount is not positive", func(t *testing.T) {
+		_, err := warehouseService.AssignStock("P1", "W1", 0)
+		assert.ErrorIs(t, err, ErrAmountMustBePositive)
+	})
+
+	t.Run("should return error when warehouse is not found", func(t *testing.T) {
+		mockWarehouseRepo.EXPECT().Get(warehouseCode).Return(nil, warehouse.ErrNotFound)
+		_, err := warehouseService.AssignStock("P1", "W1", 10)
+		assert.ErrorIs(t, err, warehouse.ErrNotFound)
+	})
+
+	t.Run("should return error when product is not found", func(t *testing.T) {
+		mockWarehouseRepo.EXPECT().Get(warehouseCode).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(productCode).Return(nil, product.ErrNotFound)
+		_, err := warehouseService.AssignStock("P1", "W1", 10)
+		assert.ErrorIs(t, err, product.ErrNotFound)
+	})
+
+	t.Run("should return error when product is a bundle", func(t *testing.T) {
+		bundle := newProduct()
+		bundle.Components = []entity.BundleComponent{{Product: warehouseCode, Quantity: 1}}
+		mockWarehouseRepo.EXPECT().Get(warehouseCode).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(productCode).Return(bundle, nil)
+		_, err := warehouseService.AssignStock("P1", "W1", 10)
+		assert.ErrorIs(t, err, ErrCannotBeStocked)
+		assert.Nil(t, bundle.Inventory)
+	})
+
+	t.Run("should return error when not enough stock is unassigned", func(t *testing.T) {
+		existing := newProduct()
+		existing.AssignStock(warehouseCode, 45)
+		mockWarehouseRepo.EXPECT().Get(warehouseCode).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(productCode).Return(existing, nil)
+		_, err := warehouseService.AssignStock("P1", "W1", 10)
+		assert.ErrorIs(t, err, ErrNotEnoughUnassignedStock)
+		assert.Equal(t, 45, existing.Inventory[0].Stock.Value())
+	})
+
+	t.Run("should keep inventory when update fails", func(t *testing.T) {
+		existing := newProduct()
+		mockWarehouseRepo.EXPECT().Get(warehouseCode).Return(stored, nil)
+		mockProductRepo.EXPECT().Get(productCode).Return(existing, nil)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(errors.New("update failed"))
+		_, err := warehouseService.AssignStock("P1", "W1", 10)
+		assert.Error(t, err)
+		assert.Empty(t, existing.Inventory)
+		assert.Equal(t, 50, existing.Unassigned())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		existing := newProduct()
+		mockWarehouseRepo.EXPECT().Get(warehouseCode).Return(stored, nil).Times(2)
+		mockProductRepo.EXPECT().Get(productCode).Return(existing, nil).Times(2)
+		mockProductRepo.EXPECT().Update(existing, 0).Return(nil).Times(2)
+
+		result, err := warehouseService.AssignStock("P1", "W1", 10)
+		assert.NoError(t, err)
+		assert.Same(t, existing, result)
+
+		_, err = warehouseService.AssignStock("P1", "W1", 15)
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.WarehouseStock{{Warehouse: warehouseCode, Stock: existing.Inventory[0].Stock}}, existing.Inventory)
+		assert.Equal(t, 25, existing.Inventory[0].Stock.Value())
+		assert.Equal(t, 50, existing.Stock.Value())
+		assert.Equal(t, 25, existing.Unassigned())
+	})
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrVersionConflict = errors.New("Version conflict")
+)
+
+// VersionConflictError is returned by repositories when an aggregate was
+// changed by someone else after the caller read it.
+type VersionConflictError struct {
+	Aggregate string
+	Key       string
+	Expected  int
+	Actual    int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("Version conflict on %s %s; expected version %d, actual version %d", e.Aggregate, e.Key, e.Expected, e.Actual)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
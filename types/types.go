@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 type Storage[T any] interface {
 	Set(key string, value T)
 	Get(key string) (T, bool)
@@ -7,4 +9,19 @@ type Storage[T any] interface {
 	Len() int
 	Keys() []string
 	Values() []T
+	// AddIndex declares a secondary index over the value extract returns for
+	// each item, kept up to date on Set and Delete.
+	AddIndex(name string, extract func(T) string)
+	// GetBy returns the items whose indexed value equals value.
+	GetBy(name string, value string) []T
+}
+
+type UnitOfWork interface {
+	Do(fn func() error) error
+	OnRollback(fn func())
+}
+
+// Clock tells the current simulated time.
+type Clock interface {
+	Now() time.Time
 }
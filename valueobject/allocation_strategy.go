@@ -0,0 +1,51 @@
+package valueobject
+
+import (
+	"errors"
+)
+
+// Allocation strategies decide which warehouses the units of an order are
+// taken from.
+const (
+	NearestWarehouse      = "nearest"
+	MostStockWarehouse    = "most-stock"
+	SplitAcrossWarehouses = "split"
+)
+
+var (
+	ErrAllocationStrategyCannotBeEmpty = errors.New("Allocation strategy cannot be empty")
+	ErrAllocationStrategyMustBeOneOf   = errors.New("Allocation strategy must be one of 'nearest', 'most-stock', 'split'")
+)
+
+type AllocationStrategy struct {
+	value string
+}
+
+func NewAllocationStrategy(value string) (AllocationStrategy, error) {
+	if value == "" {
+		return AllocationStrategy{}, ErrAllocationStrategyCannotBeEmpty
+	}
+
+	if value != NearestWarehouse && value != MostStockWarehouse && value != SplitAcrossWarehouses {
+		return AllocationStrategy{}, ErrAllocationStrategyMustBeOneOf
+	}
+
+	return AllocationStrategy{value: value}, nil
+}
+
+func (a AllocationStrategy) Value() string {
+	return a.value
+}
+
+func (a AllocationStrategy) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	strategy, ok := value.(AllocationStrategy)
+	if !ok {
+		return false
+	}
+
+	return a.value == strategy.value
+}
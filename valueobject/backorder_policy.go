@@ -0,0 +1,50 @@
+package valueobject
+
+import (
+	"errors"
+)
+
+// Backorder policies decide when the backordered and pre-ordered units of an
+// order count towards the target of a campaign.
+const (
+	CountOnOrder       = "Order"
+	CountOnFulfillment = "Fulfillment"
+)
+
+var (
+	ErrBackorderPolicyCannotBeEmpty = errors.New("Backorder policy cannot be empty")
+	ErrBackorderPolicyMustBeOneOf   = errors.New("Backorder policy must be one of 'Order', 'Fulfillment'")
+)
+
+type BackorderPolicy struct {
+	value string
+}
+
+func NewBackorderPolicy(value string) (BackorderPolicy, error) {
+	if value == "" {
+		return BackorderPolicy{}, ErrBackorderPolicyCannotBeEmpty
+	}
+
+	if value != CountOnOrder && value != CountOnFulfillment {
+		return BackorderPolicy{}, ErrBackorderPolicyMustBeOneOf
+	}
+
+	return BackorderPolicy{value: value}, nil
+}
+
+func (b BackorderPolicy) Value() string {
+	return b.value
+}
+
+func (b BackorderPolicy) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	policy, ok := value.(BackorderPolicy)
+	if !ok {
+		return false
+	}
+
+	return b.value == policy.value
+}
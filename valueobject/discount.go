@@ -0,0 +1,77 @@
+package valueobject
+
+import (
+	"errors"
+	"math"
+)
+
+// Discount types decide whether the amount of a discount is taken off the
+// order total as is or as a percentage of it.
+const (
+	FixedDiscount   = "Fixed"
+	PercentDiscount = "Percent"
+)
+
+var (
+	ErrDiscountTypeCannotBeEmpty    = errors.New("Discount type cannot be empty")
+	ErrDiscountTypeMustBeOneOf      = errors.New("Discount type must be one of 'Fixed', 'Percent'")
+	ErrDiscountAmountMustBePositive = errors.New("Discount amount must be positive")
+	ErrDiscountPercentTooHigh       = errors.New("Discount percent cannot be more than 100")
+)
+
+// Discount is an amount or a percentage taken off an order.
+type Discount struct {
+	kind   string
+	amount float64
+}
+
+func NewDiscount(kind string, amount float64) (Discount, error) {
+	if kind == "" {
+		return Discount{}, ErrDiscountTypeCannotBeEmpty
+	}
+
+	if kind != FixedDiscount && kind != PercentDiscount {
+		return Discount{}, ErrDiscountTypeMustBeOneOf
+	}
+
+	if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Discount{}, ErrDiscountAmountMustBePositive
+	}
+
+	if kind == PercentDiscount && amount > 100 {
+		return Discount{}, ErrDiscountPercentTooHigh
+	}
+
+	return Discount{kind: kind, amount: amount}, nil
+}
+
+func (d Discount) Type() string {
+	return d.kind
+}
+
+func (d Discount) Amount() float64 {
+	return d.amount
+}
+
+// Apply returns the amount the discount takes off total, which is never more
+// than total.
+func (d Discount) Apply(total float64) float64 {
+	if d.kind == PercentDiscount {
+		return total * d.amount / 100
+	}
+
+	return math.Min(d.amount, total)
+}
+
+func (d Discount) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	discount, ok := value.(Discount)
+	if !ok {
+		return false
+	}
+
+	return d.kind == discount.kind && d.amount == discount.amount
+}
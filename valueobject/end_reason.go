@@ -0,0 +1,51 @@
+package valueobject
+
+import (
+	"errors"
+)
+
+// Campaign end reasons. A campaign is cancelled with the same word an order
+// is, so Cancelled serves both.
+const (
+	Expired        = "Expired"
+	TargetReached  = "TargetReached"
+	StockExhausted = "StockExhausted"
+)
+
+var (
+	ErrEndReasonCannotBeEmpty = errors.New("End reason cannot be empty")
+	ErrEndReasonMustBeOneOf   = errors.New("End reason must be one of 'Expired', 'TargetReached', 'Cancelled', 'StockExhausted'")
+)
+
+type EndReason struct {
+	value string
+}
+
+func NewEndReason(value string) (EndReason, error) {
+	if value == "" {
+		return EndReason{}, ErrEndReasonCannotBeEmpty
+	}
+
+	if value != Expired && value != TargetReached && value != Cancelled && value != StockExhausted {
+		return EndReason{}, ErrEndReasonMustBeOneOf
+	}
+
+	return EndReason{value: value}, nil
+}
+
+func (e EndReason) Value() string {
+	return e.value
+}
+
+func (e EndReason) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	reason, ok := value.(EndReason)
+	if !ok {
+		return false
+	}
+
+	return e.value == reason.value
+}
@@ -0,0 +1,50 @@
+package valueobject
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrLocationMustBeFinite = errors.New("Location coordinates must be finite numbers")
+)
+
+// Location is a point on the plane warehouses and customers are placed on.
+type Location struct {
+	x float64
+	y float64
+}
+
+func NewLocation(x float64, y float64) (Location, error) {
+	if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return Location{}, ErrLocationMustBeFinite
+	}
+
+	return Location{x: x, y: y}, nil
+}
+
+func (l Location) X() float64 {
+	return l.x
+}
+
+func (l Location) Y() float64 {
+	return l.y
+}
+
+// Distance returns the straight line distance between l and other.
+func (l Location) Distance(other Location) float64 {
+	return math.Hypot(l.x-other.x, l.y-other.y)
+}
+
+func (l Location) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	location, ok := value.(Location)
+	if !ok {
+		return false
+	}
+
+	return l.x == location.x && l.y == location.y
+}
@@ -0,0 +1,54 @@
+package valueobject
+
+import (
+	"errors"
+)
+
+// Movement types tell why the stock of a product changed.
+const (
+	InitialMovement      = "Initial"
+	SaleMovement         = "Sale"
+	CancellationMovement = "Cancellation"
+	RestockMovement      = "Restock"
+	AdjustmentMovement   = "Adjustment"
+	ReservationMovement  = "Reservation"
+)
+
+var (
+	ErrMovementTypeCannotBeEmpty = errors.New("Movement type cannot be empty")
+	ErrMovementTypeMustBeOneOf   = errors.New("Movement type must be one of 'Initial', 'Sale', 'Cancellation', 'Restock', 'Adjustment', 'Reservation'")
+)
+
+type MovementType struct {
+	value string
+}
+
+func NewMovementType(value string) (MovementType, error) {
+	if value == "" {
+		return MovementType{}, ErrMovementTypeCannotBeEmpty
+	}
+
+	switch value {
+	case InitialMovement, SaleMovement, CancellationMovement, RestockMovement, AdjustmentMovement, ReservationMovement:
+		return MovementType{value: value}, nil
+	}
+
+	return MovementType{}, ErrMovementTypeMustBeOneOf
+}
+
+func (m MovementType) Value() string {
+	return m.value
+}
+
+func (m MovementType) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	movementType, ok := value.(MovementType)
+	if !ok {
+		return false
+	}
+
+	return m.value == movementType.value
+}
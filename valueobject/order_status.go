@@ -0,0 +1,79 @@
+package valueobject
+
+import (
+	"errors"
+)
+
+const (
+	PreOrdered  = "PreOrdered"
+	Backordered = "Backordered"
+	Placed      = "Placed"
+	Paid        = "Paid"
+	Shipped     = "Shipped"
+	Delivered   = "Delivered"
+	Cancelled   = "Cancelled"
+)
+
+var (
+	ErrOrderStatusCannotBeEmpty     = errors.New("Order status cannot be empty")
+	ErrOrderStatusMustBeOneOf       = errors.New("Order status must be one of 'PreOrdered', 'Backordered', 'Placed', 'Paid', 'Shipped', 'Delivered', 'Cancelled'")
+	ErrIllegalOrderStatusTransition = errors.New("Order status cannot change to the given status")
+)
+
+// orderStatusTransitions lists the statuses each status may change to. An
+// order can be cancelled until it is shipped. Pre-orders and backorders are
+// placed once all their units are taken from stock.
+var orderStatusTransitions = map[string][]string{
+	PreOrdered:  {Placed, Cancelled},
+	Backordered: {Placed, Cancelled},
+	Placed:      {Paid, Cancelled},
+	Paid:        {Shipped, Cancelled},
+	Shipped:     {Delivered},
+	Delivered:   {},
+	Cancelled:   {},
+}
+
+type OrderStatus struct {
+	value string
+}
+
+func NewOrderStatus(value string) (OrderStatus, error) {
+	if value == "" {
+		return OrderStatus{}, ErrOrderStatusCannotBeEmpty
+	}
+
+	if _, ok := orderStatusTransitions[value]; !ok {
+		return OrderStatus{}, ErrOrderStatusMustBeOneOf
+	}
+
+	return OrderStatus{value: value}, nil
+}
+
+// Transition returns the status the order moves to, or an error when the
+// change is not allowed from s.
+func (s OrderStatus) Transition(next OrderStatus) (OrderStatus, error) {
+	for _, allowed := range orderStatusTransitions[s.value] {
+		if allowed == next.value {
+			return next, nil
+		}
+	}
+
+	return s, ErrIllegalOrderStatusTransition
+}
+
+func (s OrderStatus) Value() string {
+	return s.value
+}
+
+func (s OrderStatus) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	status, ok := value.(OrderStatus)
+	if !ok {
+		return false
+	}
+
+	return s.value == status.value
+}
@@ -6,12 +6,13 @@ import (
 
 const (
 	Active = "Active"
+	Paused = "Paused"
 	Ended  = "Ended"
 )
 
 var (
 	ErrStatusCannotBeEmpty = errors.New("Status cannot be empty")
-	ErrStatusMustBeOneOf   = errors.New("Status must be one of 'Active', 'Ended'")
+	ErrStatusMustBeOneOf   = errors.New("Status must be one of 'Active', 'Paused', 'Ended'")
 )
 
 type Status struct {
@@ -23,7 +24,7 @@ func NewStatus(value string) (Status, error) {
 		return Status{}, ErrStatusCannotBeEmpty
 	}
 
-	if value != Active && value != Ended {
+	if value != Active && value != Paused && value != Ended {
 		return Status{}, ErrStatusMustBeOneOf
 	}
 
@@ -0,0 +1,51 @@
+package valueobject
+
+import (
+	"errors"
+)
+
+// Stock policies decide what happens to a campaign when the stock of its
+// product can no longer cover the remaining target sales count.
+const (
+	EndOnStockOut   = "End"
+	PauseOnStockOut = "Pause"
+	KeepOnStockOut  = "Keep"
+)
+
+var (
+	ErrStockPolicyCannotBeEmpty = errors.New("Stock policy cannot be empty")
+	ErrStockPolicyMustBeOneOf   = errors.New("Stock policy must be one of 'End', 'Pause', 'Keep'")
+)
+
+type StockPolicy struct {
+	value string
+}
+
+func NewStockPolicy(value string) (StockPolicy, error) {
+	if value == "" {
+		return StockPolicy{}, ErrStockPolicyCannotBeEmpty
+	}
+
+	if value != EndOnStockOut && value != PauseOnStockOut && value != KeepOnStockOut {
+		return StockPolicy{}, ErrStockPolicyMustBeOneOf
+	}
+
+	return StockPolicy{value: value}, nil
+}
+
+func (s StockPolicy) Value() string {
+	return s.value
+}
+
+func (s StockPolicy) Equals(value ValueObject) bool {
+	if value == nil {
+		return false
+	}
+
+	policy, ok := value.(StockPolicy)
+	if !ok {
+		return false
+	}
+
+	return s.value == policy.value
+}